@@ -0,0 +1,93 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"syscall"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
+	"github.com/takashabe/gco-o11y-mcp/internal/server"
+)
+
+// runExport はexport_logsと同じ処理でログをファイルに書き出すCLIモード
+// 中断した場合は同じ引数で再実行すると続きから再開する
+func runExport(args []string) int {
+	fs := flag.NewFlagSet("export", flag.ExitOnError)
+	var (
+		filter        = fs.String("filter", "", "Cloud Logging filter")
+		startTime     = fs.String("start", "", "Start of the window in RFC3339 (default 1h before the end)")
+		endTime       = fs.String("end", "", "End of the window in RFC3339 (default now)")
+		format        = fs.String("format", "ndjson", "Output format: ndjson, csv or parquet")
+		columns       = fs.String("columns", "", "Comma separated entry paths written by csv and parquet")
+		output        = fs.String("output", "", "Output file, which must not exist yet (required)")
+		projectID     = fs.String("project", "", "Google Cloud project ID (detected from the environment if empty)")
+		readsPerMin   = fs.Int("reads-per-minute", quota.DefaultReadsPerMinute, "API read budget per minute")
+		redact        = fs.Bool("redact", false, "Redact personal data and secrets with the built-in detectors")
+		redactionFile = fs.String("redaction-file", "", "YAML or JSON file configuring redaction (implies -redact)")
+	)
+	fs.Parse(args)
+
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "export: -output is required")
+		fs.Usage()
+		return 2
+	}
+	path, err := filepath.Abs(*output)
+	if err != nil {
+		log.Printf("Invalid output path: %v", err)
+		return 1
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM)
+	defer stop()
+
+	if *projectID == "" {
+		*projectID = server.DetectProjectID(ctx)
+	}
+	client, err := logging.NewClient(ctx, *projectID)
+	if err != nil {
+		log.Printf("Failed to create logging client: %v", err)
+		return 1
+	}
+	defer client.Close()
+	client.SetQuotaGovernor(quota.NewGovernor(*readsPerMin))
+
+	if *redact || *redactionFile != "" {
+		if err := logging.EnableRedaction(*redactionFile); err != nil {
+			log.Printf("Failed to enable redaction: %v", err)
+			return 1
+		}
+	}
+
+	// 進捗は標準エラーに出力
+	ctx = logging.WithExportProgress(ctx, func(p logging.ExportProgress) {
+		log.Printf("Exported %d entries through %s", p.Entries, p.Through)
+	})
+
+	tool := logging.NewExportLogsTool(client, filepath.Dir(path))
+	result, err := tool.Execute(ctx, map[string]interface{}{
+		"filter":    *filter,
+		"startTime": *startTime,
+		"endTime":   *endTime,
+		"format":    *format,
+		"columns":   *columns,
+		"output":    path,
+	})
+	if err != nil {
+		log.Printf("Export failed: %v", err)
+		return 1
+	}
+	for _, c := range result.Content {
+		fmt.Println(c.Text)
+	}
+	if result.IsError {
+		return 1
+	}
+	return 0
+}
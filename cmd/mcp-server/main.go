@@ -7,25 +7,51 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 
 	"github.com/takashabe/gco-o11y-mcp/internal/server"
 )
 
 func main() {
+	// export サブコマンドはサーバーを起動せずにログをファイルに書き出す
+	if len(os.Args) > 1 && os.Args[1] == "export" {
+		os.Exit(runExport(os.Args[2:]))
+	}
+
 	var (
 		transportType = flag.String("transport", "stdio", "Transport type: stdio or streamable-http")
 		httpAddr      = flag.String("addr", ":8080", "HTTP address for streamable-http transport")
 		serverName    = flag.String("name", "gcp-o11y-mcp", "Server name")
 		serverVersion = flag.String("version", "1.0.0", "Server version")
+		cacheDir      = flag.String("cache-dir", "", "Directory for the persistent query cache (disabled if empty)")
+		cacheMaxMB    = flag.Int64("cache-max-mb", 256, "Maximum size of the persistent query cache in megabytes")
+		readsPerMin   = flag.Int("reads-per-minute", 60, "API read budget per minute shared by all tools and sessions")
+		presetFile    = flag.String("preset-file", "", "YAML or JSON file with user-defined preset queries")
+		cacheBucket   = flag.Duration("cache-time-bucket", time.Minute, "Granularity that timestamp bounds are rounded to in cache keys")
+		projectID     = flag.String("project", "", "Google Cloud project ID (detected from the environment if empty)")
+		maxTokens     = flag.Int("max-output-tokens", 20000, "Default size limit of a tool result in tokens; larger results are degraded to fit")
+		redact        = flag.Bool("redact", false, "Redact personal data and secrets in tool results with the built-in detectors")
+		redactionFile = flag.String("redaction-file", "", "YAML or JSON file configuring redaction of tool results (implies -redact)")
+		exportDir     = flag.String("export-dir", server.DefaultExportDir(), "Directory export_logs writes to and serves as export:// resources")
 	)
 	flag.Parse()
 
 	// サーバー設定
 	config := server.Config{
-		ServerName:    *serverName,
-		ServerVersion: *serverVersion,
-		TransportType: *transportType,
-		HTTPAddr:      *httpAddr,
+		ServerName:      *serverName,
+		ServerVersion:   *serverVersion,
+		TransportType:   *transportType,
+		HTTPAddr:        *httpAddr,
+		CacheDir:        *cacheDir,
+		CacheMaxBytes:   *cacheMaxMB << 20,
+		CacheTimeBucket: *cacheBucket,
+		ReadsPerMinute:  *readsPerMin,
+		PresetFile:      *presetFile,
+		ProjectID:       *projectID,
+		MaxOutputTokens: *maxTokens,
+		Redact:          *redact,
+		RedactionFile:   *redactionFile,
+		ExportDir:       *exportDir,
 	}
 
 	// サーバーを作成
@@ -0,0 +1,110 @@
+package cloudrun
+
+import (
+	"context"
+	"fmt"
+
+	"google.golang.org/api/option"
+	run "google.golang.org/api/run/v2"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
+)
+
+// Services with long histories keep hundreds of revisions; only the newest matter
+const maxRevisions = 1000
+
+// API is the subset of the Cloud Run Admin API used by the tools
+type API interface {
+	// FindService returns the service with the given id. An empty region
+	// searches every region of the project.
+	FindService(ctx context.Context, projectID, region, service string) (*run.GoogleCloudRunV2Service, error)
+	ListRevisions(ctx context.Context, serviceName string) ([]*run.GoogleCloudRunV2Revision, error)
+}
+
+type restAPI struct {
+	service  *run.Service
+	governor *quota.Governor
+}
+
+// NewAPI creates an API backed by the Cloud Run Admin API v2. Every request
+// draws from the given read budget.
+func NewAPI(ctx context.Context, governor *quota.Governor, opts ...option.ClientOption) (API, error) {
+	opts = append([]option.ClientOption{
+		option.WithScopes(run.CloudPlatformScope),
+	}, opts...)
+	service, err := run.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cloud run client: %w", err)
+	}
+	return &restAPI{
+		service:  service,
+		governor: governor,
+	}, nil
+}
+
+func (a *restAPI) FindService(ctx context.Context, projectID, region, service string) (*run.GoogleCloudRunV2Service, error) {
+	if region != "" {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		name := fmt.Sprintf("projects/%s/locations/%s/services/%s", projectID, region, service)
+		return a.service.Projects.Locations.Services.Get(name).Context(ctx).Do()
+	}
+
+	// "-" lists the services of all regions
+	call := a.service.Projects.Locations.Services.List(fmt.Sprintf("projects/%s/locations/-", projectID)).Context(ctx)
+	var found []*run.GoogleCloudRunV2Service
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		for _, s := range resp.Services {
+			if serviceID(s.Name) == service {
+				found = append(found, s)
+			}
+		}
+		if resp.NextPageToken == "" {
+			break
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+
+	switch len(found) {
+	case 0:
+		return nil, fmt.Errorf("service %q not found in project %s", service, projectID)
+	case 1:
+		return found[0], nil
+	default:
+		var regions []string
+		for _, s := range found {
+			regions = append(regions, serviceRegion(s.Name))
+		}
+		return nil, fmt.Errorf("service %q exists in several regions (%v); specify region", service, regions)
+	}
+}
+
+func (a *restAPI) ListRevisions(ctx context.Context, serviceName string) ([]*run.GoogleCloudRunV2Revision, error) {
+	call := a.service.Projects.Locations.Services.Revisions.List(serviceName).
+		Context(ctx).
+		PageSize(100)
+
+	var revisions []*run.GoogleCloudRunV2Revision
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, resp.Revisions...)
+		if resp.NextPageToken == "" || len(revisions) >= maxRevisions {
+			return revisions, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
@@ -0,0 +1,396 @@
+package cloudrun
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	run "google.golang.org/api/run/v2"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultMaxRevisions = 10
+	maxRevisionLimit    = 50
+	// Errors are compared over this span on either side of each deploy
+	defaultImpactSpan = 30 * time.Minute
+	// Deploys older than this are not correlated with logs
+	maxCorrelationAge = 7 * 24 * time.Hour
+	maxErrorEntries   = 5000
+	maxEnvValueChars  = 200
+)
+
+// ErrorSource reads the error entries of a service. It is satisfied by
+// *logging.Client.
+type ErrorSource interface {
+	RevisionEntries(ctx context.Context, filter string, window logging.TimeWindow, limit int) ([]logging.RevisionEntry, bool, error)
+}
+
+type DescribeServiceTool struct {
+	api         API
+	logs        ErrorSource
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type DescribeServiceArgs struct {
+	Service      string `json:"service"`
+	Region       string `json:"region,omitempty"`
+	MaxRevisions int    `json:"maxRevisions,omitempty"`
+	ImpactSpan   string `json:"impactSpan,omitempty"`
+	SkipLogs     bool   `json:"skipLogs,omitempty"`
+}
+
+type trafficTarget struct {
+	Revision string `json:"revision"`
+	Percent  int64  `json:"percent"`
+	Latest   bool   `json:"latest,omitempty"`
+	Tag      string `json:"tag,omitempty"`
+	URI      string `json:"uri,omitempty"`
+}
+
+type containerImage struct {
+	Container string `json:"container,omitempty"`
+	Image     string `json:"image"`
+	Digest    string `json:"digest,omitempty"`
+}
+
+type envVar struct {
+	Name   string `json:"name"`
+	Value  string `json:"value,omitempty"`
+	Secret string `json:"secret,omitempty"`
+}
+
+type revision struct {
+	Name           string                `json:"name"`
+	CreateTime     string                `json:"createTime"`
+	Ready          bool                  `json:"ready"`
+	TrafficPercent int64                 `json:"trafficPercent,omitempty"`
+	Images         []containerImage      `json:"images"`
+	Env            []envVar              `json:"env,omitempty"`
+	ServiceAccount string                `json:"serviceAccount,omitempty"`
+	MinInstances   int64                 `json:"minInstances,omitempty"`
+	MaxInstances   int64                 `json:"maxInstances,omitempty"`
+	Concurrency    int64                 `json:"concurrency,omitempty"`
+	Failure        string                `json:"failure,omitempty"`
+	Errors         *logging.DeployImpact `json:"errors,omitempty"`
+}
+
+type serviceDescription struct {
+	Service               string          `json:"service"`
+	Region                string          `json:"region"`
+	URI                   string          `json:"uri,omitempty"`
+	CreateTime            string          `json:"createTime"`
+	UpdateTime            string          `json:"updateTime"`
+	Creator               string          `json:"creator,omitempty"`
+	LastModifier          string          `json:"lastModifier,omitempty"`
+	Ingress               string          `json:"ingress,omitempty"`
+	Ready                 bool            `json:"ready"`
+	LatestReadyRevision   string          `json:"latestReadyRevision,omitempty"`
+	LatestCreatedRevision string          `json:"latestCreatedRevision,omitempty"`
+	Traffic               []trafficTarget `json:"traffic"`
+	RevisionCount         int             `json:"revisionCount"`
+	Revisions             []revision      `json:"revisions"`
+	// Newest deploy followed by a significant rise in errors
+	SuspectRevision string `json:"suspectRevision,omitempty"`
+	ImpactSpan      string `json:"impactSpan,omitempty"`
+	ErrorsTruncated bool   `json:"errorsTruncated,omitempty"`
+	LogsError       string `json:"logsError,omitempty"`
+}
+
+func NewDescribeServiceTool(api API, logs ErrorSource, projectID string, cache *logging.LogCache) *DescribeServiceTool {
+	return &DescribeServiceTool{
+		api:         api,
+		logs:        logs,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *DescribeServiceTool) Name() string {
+	return "describe_cloud_run_service"
+}
+
+func (t *DescribeServiceTool) Description() string {
+	return "Describe a Cloud Run service: traffic split, URL, and its newest revisions with creation time, container images and digests, env (secrets shown by reference), scaling and readiness. Each recent revision also gets the service's error count in impactSpan (default 30m) before and after it was created, the first error it logged, and whether errors rose significantly; suspectRevision names the newest such deploy. region is searched when omitted."
+}
+
+func (t *DescribeServiceTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"service": {
+				Type: "string",
+			},
+			"region": {
+				Type: "string",
+			},
+			"maxRevisions": {
+				Type: "integer",
+			},
+			"impactSpan": {
+				Type: "string",
+			},
+			"skipLogs": {
+				Type: "boolean",
+			},
+		},
+		Required:             []string{"service"},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *DescribeServiceTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params DescribeServiceArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.Service == "" {
+		return logging.ErrorResult("Error: service is required"), nil
+	}
+	if params.MaxRevisions <= 0 {
+		params.MaxRevisions = defaultMaxRevisions
+	}
+	if params.MaxRevisions > maxRevisionLimit {
+		params.MaxRevisions = maxRevisionLimit
+	}
+	span := defaultImpactSpan
+	if params.ImpactSpan != "" {
+		var err error
+		span, err = time.ParseDuration(params.ImpactSpan)
+		if err != nil || span < time.Minute {
+			return logging.ErrorResult(fmt.Sprintf("Error: impactSpan %q must be a duration of at least 1m", params.ImpactSpan)), nil
+		}
+	}
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"service":   params.Service,
+		"region":    params.Region,
+	})
+
+	var service *run.GoogleCloudRunV2Service
+	var revisions []*run.GoogleCloudRunV2Revision
+	var cached struct {
+		Service   *run.GoogleCloudRunV2Service
+		Revisions []*run.GoogleCloudRunV2Revision
+	}
+	if t.cache.GetValue(cacheKey, &cached) {
+		service, revisions = cached.Service, cached.Revisions
+	} else {
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			service, err = t.api.FindService(ctx, t.projectID, params.Region, params.Service)
+			if err != nil {
+				return err
+			}
+			revisions, err = t.api.ListRevisions(ctx, service.Name)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to describe Cloud Run service: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error describing service: %v", err)), nil
+		}
+		cached.Service, cached.Revisions = service, revisions
+		t.cache.SetValue(cacheKey, cached, time.Minute)
+	}
+
+	result := describeService(service, revisions, params.MaxRevisions)
+	if !params.SkipLogs {
+		t.correlateErrors(ctx, &result, span)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal service: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// correlateErrors lays the service's error entries over the creation times
+// of its recent revisions. Failures are reported without failing the tool.
+func (t *DescribeServiceTool) correlateErrors(ctx context.Context, result *serviceDescription, span time.Duration) {
+	now := time.Now().UTC()
+	var oldest time.Time
+	var recent []int
+	for i, rev := range result.Revisions {
+		created, err := time.Parse(time.RFC3339Nano, rev.CreateTime)
+		if err != nil || now.Sub(created) > maxCorrelationAge {
+			continue
+		}
+		recent = append(recent, i)
+		if oldest.IsZero() || created.Before(oldest) {
+			oldest = created
+		}
+	}
+	if len(recent) == 0 {
+		return
+	}
+
+	window := logging.TimeWindow{Start: oldest.Add(-span), End: now}
+	filter := fmt.Sprintf(`resource.type="cloud_run_revision" AND resource.labels.service_name="%s" AND resource.labels.location="%s" AND severity>=ERROR`, logging.EscapeFilterValue(result.Service), logging.EscapeFilterValue(result.Region))
+	var errors []logging.RevisionEntry
+	var truncated bool
+	err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+		var err error
+		errors, truncated, err = t.logs.RevisionEntries(ctx, filter, window, maxErrorEntries)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to read errors for %s: %v", result.Service, err)
+		result.LogsError = err.Error()
+		return
+	}
+
+	result.ImpactSpan = span.String()
+	result.ErrorsTruncated = truncated
+	for _, i := range recent {
+		rev := &result.Revisions[i]
+		created, _ := time.Parse(time.RFC3339Nano, rev.CreateTime)
+		impact := logging.MeasureDeployImpact(errors, created, span, rev.Name, now)
+		rev.Errors = &impact
+		// Revisions are newest first, so the first hit is the newest suspect
+		if impact.Significant && result.SuspectRevision == "" {
+			result.SuspectRevision = rev.Name
+		}
+	}
+}
+
+func describeService(service *run.GoogleCloudRunV2Service, revisions []*run.GoogleCloudRunV2Revision, maxRevisions int) serviceDescription {
+	result := serviceDescription{
+		Service:               serviceID(service.Name),
+		Region:                serviceRegion(service.Name),
+		URI:                   service.Uri,
+		CreateTime:            service.CreateTime,
+		UpdateTime:            service.UpdateTime,
+		Creator:               service.Creator,
+		LastModifier:          service.LastModifier,
+		Ingress:               service.Ingress,
+		Ready:                 conditionReady(service.TerminalCondition),
+		LatestReadyRevision:   shortName(service.LatestReadyRevision),
+		LatestCreatedRevision: shortName(service.LatestCreatedRevision),
+		Traffic:               []trafficTarget{},
+		RevisionCount:         len(revisions),
+		Revisions:             []revision{},
+	}
+
+	// Statuses resolve "latest" targets to the revision actually serving
+	traffic := make(map[string]int64)
+	for _, status := range service.TrafficStatuses {
+		target := trafficTarget{
+			Revision: status.Revision,
+			Percent:  status.Percent,
+			Latest:   status.Type == "TRAFFIC_TARGET_ALLOCATION_TYPE_LATEST",
+			Tag:      status.Tag,
+			URI:      status.Uri,
+		}
+		if target.Latest && target.Revision == "" {
+			target.Revision = result.LatestReadyRevision
+		}
+		traffic[target.Revision] += target.Percent
+		result.Traffic = append(result.Traffic, target)
+	}
+
+	sort.SliceStable(revisions, func(i, j int) bool {
+		return revisions[i].CreateTime > revisions[j].CreateTime
+	})
+	for i, rev := range revisions {
+		name := path.Base(rev.Name)
+		// Serving revisions are always shown, however old
+		if i >= maxRevisions && traffic[name] == 0 {
+			continue
+		}
+		result.Revisions = append(result.Revisions, describeRevision(rev, traffic[name]))
+	}
+	return result
+}
+
+func describeRevision(rev *run.GoogleCloudRunV2Revision, percent int64) revision {
+	r := revision{
+		Name:           path.Base(rev.Name),
+		CreateTime:     rev.CreateTime,
+		TrafficPercent: percent,
+		Images:         []containerImage{},
+		ServiceAccount: rev.ServiceAccount,
+		Concurrency:    rev.MaxInstanceRequestConcurrency,
+	}
+	if rev.Scaling != nil {
+		r.MinInstances = rev.Scaling.MinInstanceCount
+		r.MaxInstances = rev.Scaling.MaxInstanceCount
+	}
+	for _, c := range rev.Conditions {
+		if c.Type == "Ready" {
+			r.Ready = conditionReady(c)
+			if !r.Ready && c.Message != "" {
+				r.Failure = c.Message
+			}
+		}
+	}
+
+	for _, c := range rev.Containers {
+		image := containerImage{Container: c.Name, Image: c.Image}
+		if i := strings.Index(c.Image, "@"); i >= 0 {
+			image.Digest = c.Image[i+1:]
+		}
+		r.Images = append(r.Images, image)
+
+		// Env is only worth the space for revisions that serve traffic
+		if percent == 0 {
+			continue
+		}
+		for _, env := range c.Env {
+			v := envVar{Name: env.Name}
+			if ref := env.ValueSource; ref != nil && ref.SecretKeyRef != nil {
+				v.Secret = fmt.Sprintf("%s:%s", path.Base(ref.SecretKeyRef.Secret), ref.SecretKeyRef.Version)
+			} else {
+				v.Value = logging.Truncate(env.Value, maxEnvValueChars)
+			}
+			r.Env = append(r.Env, v)
+		}
+	}
+	return r
+}
+
+func conditionReady(c *run.GoogleCloudRunV2Condition) bool {
+	return c != nil && c.State == "CONDITION_SUCCEEDED"
+}
+
+// shortName is the last segment of a resource name
+func shortName(name string) string {
+	if name == "" {
+		return ""
+	}
+	return path.Base(name)
+}
+
+// serviceID and serviceRegion split projects/p/locations/r/services/s
+func serviceID(name string) string {
+	return shortName(name)
+}
+
+func serviceRegion(name string) string {
+	parts := strings.Split(name, "/")
+	if len(parts) >= 4 && parts[2] == "locations" {
+		return parts[3]
+	}
+	return ""
+}
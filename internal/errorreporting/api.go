@@ -0,0 +1,111 @@
+package errorreporting
+
+import (
+	"context"
+	"fmt"
+
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+	"google.golang.org/api/option"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
+)
+
+// GroupStatsQuery selects error groups; fields map directly to the
+// projects.groupStats.list parameters
+type GroupStatsQuery struct {
+	TimeRange string
+	Service   string
+	Version   string
+	GroupIDs  []string
+	Order     string
+	PageSize  int
+}
+
+// EventsQuery selects the sample events of a group
+type EventsQuery struct {
+	GroupID   string
+	TimeRange string
+	Service   string
+	PageSize  int
+}
+
+// API is the subset of the Error Reporting API used by the tools
+type API interface {
+	ListGroupStats(ctx context.Context, projectID string, query GroupStatsQuery) ([]*clouderrorreporting.ErrorGroupStats, error)
+	ListEvents(ctx context.Context, projectID string, query EventsQuery) ([]*clouderrorreporting.ErrorEvent, error)
+}
+
+type restAPI struct {
+	service  *clouderrorreporting.Service
+	governor *quota.Governor
+}
+
+// NewAPI creates an API backed by the Error Reporting REST API. Every
+// request draws from the given read budget.
+func NewAPI(ctx context.Context, governor *quota.Governor, opts ...option.ClientOption) (API, error) {
+	opts = append([]option.ClientOption{
+		option.WithScopes(clouderrorreporting.CloudPlatformScope),
+	}, opts...)
+	service, err := clouderrorreporting.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create error reporting client: %w", err)
+	}
+	return &restAPI{
+		service:  service,
+		governor: governor,
+	}, nil
+}
+
+func (a *restAPI) ListGroupStats(ctx context.Context, projectID string, query GroupStatsQuery) ([]*clouderrorreporting.ErrorGroupStats, error) {
+	if err := a.governor.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	call := a.service.Projects.GroupStats.List("projects/" + projectID).
+		Context(ctx).
+		TimeRangePeriod(query.TimeRange)
+	if query.Service != "" {
+		call = call.ServiceFilterService(query.Service)
+	}
+	if query.Version != "" {
+		call = call.ServiceFilterVersion(query.Version)
+	}
+	if len(query.GroupIDs) > 0 {
+		call = call.GroupId(query.GroupIDs...)
+	}
+	if query.Order != "" {
+		call = call.Order(query.Order)
+	}
+	if query.PageSize > 0 {
+		call = call.PageSize(int64(query.PageSize))
+	}
+
+	resp, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+	return resp.ErrorGroupStats, nil
+}
+
+func (a *restAPI) ListEvents(ctx context.Context, projectID string, query EventsQuery) ([]*clouderrorreporting.ErrorEvent, error) {
+	if err := a.governor.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	call := a.service.Projects.Events.List("projects/" + projectID).
+		Context(ctx).
+		GroupId(query.GroupID).
+		TimeRangePeriod(query.TimeRange)
+	if query.Service != "" {
+		call = call.ServiceFilterService(query.Service)
+	}
+	if query.PageSize > 0 {
+		call = call.PageSize(int64(query.PageSize))
+	}
+
+	resp, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+	return resp.ErrorEvents, nil
+}
@@ -0,0 +1,146 @@
+package errorreporting
+
+import (
+	"context"
+	"time"
+
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+)
+
+// fakeAPI serves fixed groups and events, applying the service, group and
+// page size filters the real API would, and counts the calls it receives.
+// Time ranges are not applied.
+type fakeAPI struct {
+	Groups []*clouderrorreporting.ErrorGroupStats
+	Events map[string][]*clouderrorreporting.ErrorEvent
+	Err    error
+
+	groupQueries []GroupStatsQuery
+	eventQueries []EventsQuery
+}
+
+func (f *fakeAPI) ListGroupStats(ctx context.Context, projectID string, query GroupStatsQuery) ([]*clouderrorreporting.ErrorGroupStats, error) {
+	f.groupQueries = append(f.groupQueries, query)
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	var groups []*clouderrorreporting.ErrorGroupStats
+	for _, stats := range f.Groups {
+		if len(query.GroupIDs) > 0 && (stats.Group == nil || !contains(query.GroupIDs, stats.Group.GroupId)) {
+			continue
+		}
+		if query.Service != "" && !affects(stats.AffectedServices, query.Service, query.Version) {
+			continue
+		}
+		groups = append(groups, stats)
+		if query.PageSize > 0 && len(groups) == query.PageSize {
+			break
+		}
+	}
+	return groups, nil
+}
+
+func (f *fakeAPI) ListEvents(ctx context.Context, projectID string, query EventsQuery) ([]*clouderrorreporting.ErrorEvent, error) {
+	f.eventQueries = append(f.eventQueries, query)
+	if f.Err != nil {
+		return nil, f.Err
+	}
+
+	var events []*clouderrorreporting.ErrorEvent
+	for _, event := range f.Events[query.GroupID] {
+		if query.Service != "" && (event.ServiceContext == nil || event.ServiceContext.Service != query.Service) {
+			continue
+		}
+		events = append(events, event)
+		if query.PageSize > 0 && len(events) == query.PageSize {
+			break
+		}
+	}
+	return events, nil
+}
+
+func affects(services []*clouderrorreporting.ServiceContext, service, version string) bool {
+	for _, s := range services {
+		if s.Service == service && (version == "" || s.Version == version) {
+			return true
+		}
+	}
+	return false
+}
+
+func contains(values []string, value string) bool {
+	for _, v := range values {
+		if v == value {
+			return true
+		}
+	}
+	return false
+}
+
+// sampleGroups returns two groups seen in the hour before now, the first
+// with a few sample events
+func sampleGroups(now time.Time) ([]*clouderrorreporting.ErrorGroupStats, map[string][]*clouderrorreporting.ErrorEvent) {
+	at := func(ago time.Duration) string {
+		return now.Add(-ago).UTC().Format(time.RFC3339)
+	}
+	declined := "payment declined for order 1234: card expired\n\tat checkout.Charge(charge.go:42)"
+
+	groups := []*clouderrorreporting.ErrorGroupStats{
+		{
+			Group: &clouderrorreporting.ErrorGroup{
+				GroupId:          "CJ2x0cW8jf7wNg",
+				ResolutionStatus: "OPEN",
+				TrackingIssues:   []*clouderrorreporting.TrackingIssue{{Url: "https://issues.example.com/42"}},
+			},
+			Count:              17,
+			AffectedUsersCount: 5,
+			FirstSeenTime:      at(50 * time.Minute),
+			LastSeenTime:       at(2 * time.Minute),
+			AffectedServices: []*clouderrorreporting.ServiceContext{
+				{Service: "checkout", Version: "checkout-00042-abc"},
+				{Service: "checkout", Version: "checkout-00041-xyz"},
+			},
+			Representative: &clouderrorreporting.ErrorEvent{Message: declined},
+		},
+		{
+			Group:         &clouderrorreporting.ErrorGroup{GroupId: "COjT1YWsqfG8Vw"},
+			Count:         3,
+			FirstSeenTime: at(30 * time.Minute),
+			LastSeenTime:  at(10 * time.Minute),
+			AffectedServices: []*clouderrorreporting.ServiceContext{
+				{Service: "inventory", Version: "v7"},
+			},
+			Representative: &clouderrorreporting.ErrorEvent{Message: `lookup "sku" failed: connection refused`},
+		},
+	}
+
+	events := map[string][]*clouderrorreporting.ErrorEvent{
+		"CJ2x0cW8jf7wNg": {
+			{
+				EventTime:      at(2 * time.Minute),
+				Message:        declined,
+				ServiceContext: &clouderrorreporting.ServiceContext{Service: "checkout", Version: "checkout-00042-abc"},
+				Context: &clouderrorreporting.ErrorContext{
+					User: "user-17",
+					HttpRequest: &clouderrorreporting.HttpRequestContext{
+						Method:             "POST",
+						Url:                "https://shop.example.com/checkout",
+						ResponseStatusCode: 502,
+					},
+					ReportLocation: &clouderrorreporting.SourceLocation{
+						FilePath:     "checkout/charge.go",
+						LineNumber:   42,
+						FunctionName: "checkout.Charge",
+					},
+				},
+			},
+			{
+				EventTime:      at(20 * time.Minute),
+				Message:        declined,
+				ServiceContext: &clouderrorreporting.ServiceContext{Service: "checkout", Version: "checkout-00041-xyz"},
+			},
+		},
+	}
+	return groups, events
+}
@@ -0,0 +1,223 @@
+package errorreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultEventLimit    = 10
+	maxEventLimit        = 100
+	maxEventMessageChars = 4000
+)
+
+type GetErrorEventsTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type GetErrorEventsArgs struct {
+	GroupID   string `json:"groupId"`
+	Service   string `json:"service,omitempty"`
+	TimeRange string `json:"timeRange,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+type errorEvent struct {
+	EventTime      string           `json:"eventTime"`
+	Service        *serviceVersion  `json:"service,omitempty"`
+	Message        string           `json:"message"`
+	User           string           `json:"user,omitempty"`
+	HTTPRequest    *eventHTTPReq    `json:"httpRequest,omitempty"`
+	ReportLocation *eventSourceLine `json:"reportLocation,omitempty"`
+}
+
+type eventHTTPReq struct {
+	Method string `json:"method,omitempty"`
+	URL    string `json:"url,omitempty"`
+	Status int64  `json:"status,omitempty"`
+}
+
+type eventSourceLine struct {
+	File     string `json:"file,omitempty"`
+	Line     int64  `json:"line,omitempty"`
+	Function string `json:"function,omitempty"`
+}
+
+type errorEventsResult struct {
+	GroupID   string             `json:"groupId"`
+	TimeRange string             `json:"timeRange"`
+	Group     *errorGroupSummary `json:"group,omitempty"`
+	Count     int                `json:"count"`
+	Events    []errorEvent       `json:"events"`
+}
+
+func NewGetErrorEventsTool(api API, projectID string, cache *logging.LogCache) *GetErrorEventsTool {
+	return &GetErrorEventsTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *GetErrorEventsTool) Name() string {
+	return "get_error_events"
+}
+
+func (t *GetErrorEventsTool) Description() string {
+	return "Fetch sample events of an Error Reporting group, newest first, with full messages and stack traces, the affected service and version, and HTTP request context. Also returns the group's stats and a logFilter for list_log_entries."
+}
+
+func (t *GetErrorEventsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"groupId": {
+				Type: "string",
+			},
+			"service": {
+				Type: "string",
+			},
+			"timeRange": {
+				Type: "string",
+				Enum: timeRangeNames,
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		Required:             []string{"groupId"},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *GetErrorEventsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params GetErrorEventsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.GroupID == "" {
+		return logging.ErrorResult("Error: groupId is required"), nil
+	}
+	if params.TimeRange == "" {
+		params.TimeRange = defaultTimeRange
+	}
+	period, ok := timeRangePeriods[params.TimeRange]
+	if !ok {
+		return logging.ErrorResult(fmt.Sprintf("Error: timeRange must be one of %s", strings.Join(timeRangeNames, ", "))), nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultEventLimit
+	}
+	if params.Limit > maxEventLimit {
+		params.Limit = maxEventLimit
+	}
+
+	query := EventsQuery{
+		GroupID:   params.GroupID,
+		TimeRange: period,
+		Service:   params.Service,
+		PageSize:  params.Limit,
+	}
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"query":     query,
+		"bucket":    time.Now().Truncate(t.cache.TimeBucket()),
+	})
+
+	var result errorEventsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for error events: %s", params.GroupID)
+	} else {
+		var events []*clouderrorreporting.ErrorEvent
+		var stats []*clouderrorreporting.ErrorGroupStats
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			events, err = t.api.ListEvents(ctx, t.projectID, query)
+			if err != nil {
+				return err
+			}
+			stats, err = t.api.ListGroupStats(ctx, t.projectID, GroupStatsQuery{
+				TimeRange: period,
+				GroupIDs:  []string{params.GroupID},
+				PageSize:  1,
+			})
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to get error events: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error getting error events: %v", err)), nil
+		}
+
+		result = errorEventsResult{
+			GroupID:   params.GroupID,
+			TimeRange: params.TimeRange,
+			Events:    []errorEvent{},
+		}
+		if len(stats) > 0 {
+			summary := summarizeGroup(stats[0])
+			result.Group = &summary
+		}
+		for _, event := range events {
+			result.Events = append(result.Events, convertEvent(event))
+		}
+		result.Count = len(result.Events)
+		t.cache.SetValue(cacheKey, result, time.Minute)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal error events: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func convertEvent(event *clouderrorreporting.ErrorEvent) errorEvent {
+	converted := errorEvent{
+		EventTime: event.EventTime,
+		Message:   logging.Truncate(event.Message, maxEventMessageChars),
+	}
+	if sc := event.ServiceContext; sc != nil && sc.Service != "" {
+		converted.Service = &serviceVersion{Service: sc.Service, Version: sc.Version}
+	}
+	if ctx := event.Context; ctx != nil {
+		converted.User = ctx.User
+		if req := ctx.HttpRequest; req != nil {
+			converted.HTTPRequest = &eventHTTPReq{
+				Method: req.Method,
+				URL:    req.Url,
+				Status: req.ResponseStatusCode,
+			}
+		}
+		if loc := ctx.ReportLocation; loc != nil {
+			converted.ReportLocation = &eventSourceLine{
+				File:     loc.FilePath,
+				Line:     loc.LineNumber,
+				Function: loc.FunctionName,
+			}
+		}
+	}
+	return converted
+}
@@ -0,0 +1,113 @@
+package errorreporting
+
+import (
+	"context"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+func TestGetErrorEvents(t *testing.T) {
+	groups, events := sampleGroups(time.Now())
+	api := &fakeAPI{Groups: groups, Events: events}
+	tool := NewGetErrorEventsTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"groupId": "CJ2x0cW8jf7wNg", "timeRange": "6h"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+
+	wantEvents := EventsQuery{GroupID: "CJ2x0cW8jf7wNg", TimeRange: "PERIOD_6_HOURS", PageSize: defaultEventLimit}
+	if len(api.eventQueries) != 1 || api.eventQueries[0] != wantEvents {
+		t.Errorf("event queries = %+v, want %+v", api.eventQueries, wantEvents)
+	}
+	wantGroup := GroupStatsQuery{TimeRange: "PERIOD_6_HOURS", GroupIDs: []string{"CJ2x0cW8jf7wNg"}, PageSize: 1}
+	if len(api.groupQueries) != 1 || !equalGroupQuery(api.groupQueries[0], wantGroup) {
+		t.Errorf("group queries = %+v, want %+v", api.groupQueries, wantGroup)
+	}
+
+	var got errorEventsResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.GroupID != "CJ2x0cW8jf7wNg" || got.TimeRange != "6h" || got.Count != 2 {
+		t.Fatalf("got group %q, timeRange %q and %d events", got.GroupID, got.TimeRange, got.Count)
+	}
+
+	// The group's stats come with the log filter that finds its entries
+	if got.Group == nil || got.Group.Count != 17 {
+		t.Fatalf("group = %+v, want the stats of the group", got.Group)
+	}
+	if want := summarizeGroup(groups[0]).LogFilter; got.Group.LogFilter != want {
+		t.Errorf("logFilter = %s, want %s", got.Group.LogFilter, want)
+	}
+	if !strings.Contains(got.Group.LogFilter, `resource.labels.service_name="checkout"`) {
+		t.Errorf("logFilter does not select the affected service: %s", got.Group.LogFilter)
+	}
+
+	event := got.Events[0]
+	if event.Service == nil || *event.Service != (serviceVersion{Service: "checkout", Version: "checkout-00042-abc"}) {
+		t.Errorf("service = %+v", event.Service)
+	}
+	if event.User != "user-17" {
+		t.Errorf("user = %q", event.User)
+	}
+	if event.HTTPRequest == nil || *event.HTTPRequest != (eventHTTPReq{Method: "POST", URL: "https://shop.example.com/checkout", Status: 502}) {
+		t.Errorf("httpRequest = %+v", event.HTTPRequest)
+	}
+	if event.ReportLocation == nil || *event.ReportLocation != (eventSourceLine{File: "checkout/charge.go", Line: 42, Function: "checkout.Charge"}) {
+		t.Errorf("reportLocation = %+v", event.ReportLocation)
+	}
+	if !strings.Contains(event.Message, "checkout.Charge(charge.go:42)") {
+		t.Errorf("message lost its stack trace: %q", event.Message)
+	}
+	if got.Events[1].HTTPRequest != nil || got.Events[1].ReportLocation != nil {
+		t.Errorf("event without context = %+v", got.Events[1])
+	}
+}
+
+func TestGetErrorEventsService(t *testing.T) {
+	groups, events := sampleGroups(time.Now())
+	api := &fakeAPI{Groups: groups, Events: events}
+	tool := NewGetErrorEventsTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"groupId": "CJ2x0cW8jf7wNg", "service": "inventory"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got errorEventsResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != 0 || got.Events == nil {
+		t.Errorf("got %+v, want an empty event list", got.Events)
+	}
+}
+
+func TestGetErrorEventsInvalidArguments(t *testing.T) {
+	tests := []map[string]interface{}{
+		{},
+		{"groupId": "CJ2x0cW8jf7wNg", "timeRange": "2d"},
+	}
+
+	for _, args := range tests {
+		api := &fakeAPI{}
+		tool := NewGetErrorEventsTool(api, "example-project", logging.NewLogCache())
+		result, err := tool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.IsError {
+			t.Errorf("%v was accepted", args)
+		}
+		if len(api.eventQueries) != 0 {
+			t.Errorf("%v reached the API", args)
+		}
+	}
+}
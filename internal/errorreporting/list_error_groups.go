@@ -0,0 +1,235 @@
+package errorreporting
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	clouderrorreporting "google.golang.org/api/clouderrorreporting/v1beta1"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultTimeRange  = "1d"
+	defaultGroupLimit = 20
+	maxGroupLimit     = 100
+	maxMessageChars   = 500
+)
+
+var groupOrders = map[string]string{
+	"count":         "COUNT_DESC",
+	"lastSeen":      "LAST_SEEN_DESC",
+	"created":       "CREATED_DESC",
+	"affectedUsers": "AFFECTED_USERS_DESC",
+}
+
+type ListErrorGroupsTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListErrorGroupsArgs struct {
+	Service   string `json:"service,omitempty"`
+	Version   string `json:"version,omitempty"`
+	TimeRange string `json:"timeRange,omitempty"`
+	Order     string `json:"order,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+type serviceVersion struct {
+	Service string `json:"service"`
+	Version string `json:"version,omitempty"`
+}
+
+type errorGroupSummary struct {
+	GroupID          string           `json:"groupId"`
+	Message          string           `json:"message"`
+	Count            int64            `json:"count"`
+	AffectedUsers    int64            `json:"affectedUsers,omitempty"`
+	FirstSeen        string           `json:"firstSeen"`
+	LastSeen         string           `json:"lastSeen"`
+	Services         []serviceVersion `json:"services,omitempty"`
+	ResolutionStatus string           `json:"resolutionStatus,omitempty"`
+	TrackingIssues   []string         `json:"trackingIssues,omitempty"`
+	LogFilter        string           `json:"logFilter"`
+}
+
+type listErrorGroupsResult struct {
+	TimeRange string              `json:"timeRange"`
+	Count     int                 `json:"count"`
+	Groups    []errorGroupSummary `json:"groups"`
+}
+
+func NewListErrorGroupsTool(api API, projectID string, cache *logging.LogCache) *ListErrorGroupsTool {
+	return &ListErrorGroupsTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListErrorGroupsTool) Name() string {
+	return "list_error_groups"
+}
+
+func (t *ListErrorGroupsTool) Description() string {
+	return "List Error Reporting error groups with counts, first and last seen, affected services and versions. Each group includes a logFilter for list_log_entries that finds its log entries. timeRange is one of 1h, 6h, 1d (default), 7d, 30d; order is count (default), lastSeen, created or affectedUsers."
+}
+
+func (t *ListErrorGroupsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"service": {
+				Type: "string",
+			},
+			"version": {
+				Type: "string",
+			},
+			"timeRange": {
+				Type: "string",
+				Enum: timeRangeNames,
+			},
+			"order": {
+				Type: "string",
+				Enum: []string{"count", "lastSeen", "created", "affectedUsers"},
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListErrorGroupsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListErrorGroupsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.TimeRange == "" {
+		params.TimeRange = defaultTimeRange
+	}
+	period, ok := timeRangePeriods[params.TimeRange]
+	if !ok {
+		return logging.ErrorResult(fmt.Sprintf("Error: timeRange must be one of %s", strings.Join(timeRangeNames, ", "))), nil
+	}
+	if params.Order == "" {
+		params.Order = "count"
+	}
+	order, ok := groupOrders[params.Order]
+	if !ok {
+		return logging.ErrorResult("Error: order must be one of count, lastSeen, created, affectedUsers"), nil
+	}
+	if params.Version != "" && params.Service == "" {
+		return logging.ErrorResult("Error: version requires service"), nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultGroupLimit
+	}
+	if params.Limit > maxGroupLimit {
+		params.Limit = maxGroupLimit
+	}
+
+	query := GroupStatsQuery{
+		TimeRange: period,
+		Service:   params.Service,
+		Version:   params.Version,
+		Order:     order,
+		PageSize:  params.Limit,
+	}
+	// Stats cover a period ending now, so the key includes the current time bucket
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"query":     query,
+		"bucket":    time.Now().Truncate(t.cache.TimeBucket()),
+	})
+
+	var result listErrorGroupsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for error groups: %s", params.TimeRange)
+	} else {
+		var stats []*clouderrorreporting.ErrorGroupStats
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			stats, err = t.api.ListGroupStats(ctx, t.projectID, query)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to list error groups: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error listing error groups: %v", err)), nil
+		}
+
+		result = listErrorGroupsResult{
+			TimeRange: params.TimeRange,
+			Groups:    []errorGroupSummary{},
+		}
+		for _, s := range stats {
+			result.Groups = append(result.Groups, summarizeGroup(s))
+		}
+		result.Count = len(result.Groups)
+		t.cache.SetValue(cacheKey, result, time.Minute)
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal error groups: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func summarizeGroup(stats *clouderrorreporting.ErrorGroupStats) errorGroupSummary {
+	summary := errorGroupSummary{
+		Count:         stats.Count,
+		AffectedUsers: stats.AffectedUsersCount,
+		FirstSeen:     stats.FirstSeenTime,
+		LastSeen:      stats.LastSeenTime,
+	}
+	if stats.Group != nil {
+		summary.GroupID = stats.Group.GroupId
+		summary.ResolutionStatus = stats.Group.ResolutionStatus
+		for _, issue := range stats.Group.TrackingIssues {
+			summary.TrackingIssues = append(summary.TrackingIssues, issue.Url)
+		}
+	}
+	if stats.Representative != nil {
+		summary.Message = logging.Truncate(stats.Representative.Message, maxMessageChars)
+	}
+
+	var services []string
+	seen := make(map[string]bool)
+	for _, s := range stats.AffectedServices {
+		summary.Services = append(summary.Services, serviceVersion{Service: s.Service, Version: s.Version})
+		if s.Service != "" && !seen[s.Service] {
+			seen[s.Service] = true
+			services = append(services, s.Service)
+		}
+	}
+
+	firstSeen, _ := time.Parse(time.RFC3339Nano, stats.FirstSeenTime)
+	lastSeen, _ := time.Parse(time.RFC3339Nano, stats.LastSeenTime)
+	var message string
+	if stats.Representative != nil {
+		message = stats.Representative.Message
+	}
+	summary.LogFilter = LogFilter(message, services, firstSeen, lastSeen)
+	return summary
+}
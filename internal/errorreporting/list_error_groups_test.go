@@ -0,0 +1,136 @@
+package errorreporting
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+func TestListErrorGroups(t *testing.T) {
+	now := time.Now()
+	groups, _ := sampleGroups(now)
+	api := &fakeAPI{Groups: groups}
+	tool := NewListErrorGroupsTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"order": "lastSeen", "limit": 5})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+
+	wantQuery := GroupStatsQuery{TimeRange: "PERIOD_1_DAY", Order: "LAST_SEEN_DESC", PageSize: 5}
+	if len(api.groupQueries) != 1 || !equalGroupQuery(api.groupQueries[0], wantQuery) {
+		t.Fatalf("queries = %+v, want %+v", api.groupQueries, wantQuery)
+	}
+
+	var got listErrorGroupsResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.TimeRange != "1d" || got.Count != 2 {
+		t.Fatalf("got timeRange %q and %d groups, want 1d and 2", got.TimeRange, got.Count)
+	}
+
+	first := got.Groups[0]
+	if first.GroupID != "CJ2x0cW8jf7wNg" || first.Count != 17 || first.AffectedUsers != 5 || first.ResolutionStatus != "OPEN" {
+		t.Errorf("unexpected first group %+v", first)
+	}
+	if len(first.Services) != 2 || first.Services[0] != (serviceVersion{Service: "checkout", Version: "checkout-00042-abc"}) {
+		t.Errorf("services = %+v", first.Services)
+	}
+	if len(first.TrackingIssues) != 1 || first.TrackingIssues[0] != "https://issues.example.com/42" {
+		t.Errorf("tracking issues = %v", first.TrackingIssues)
+	}
+
+	// The log filter joins the group back to its entries, naming each service once
+	firstSeen, _ := time.Parse(time.RFC3339, groups[0].FirstSeenTime)
+	lastSeen, _ := time.Parse(time.RFC3339, groups[0].LastSeenTime)
+	wantFilter := `severity >= ERROR` +
+		` AND timestamp >= "` + firstSeen.Add(-time.Minute).Format(time.RFC3339) + `"` +
+		` AND timestamp <= "` + lastSeen.Add(time.Minute).Format(time.RFC3339) + `"` +
+		` AND (resource.labels.service_name="checkout" OR resource.labels.module_id="checkout" OR resource.labels.function_name="checkout")` +
+		` AND ("payment declined for order")`
+	if first.LogFilter != wantFilter {
+		t.Errorf("logFilter =\n%s\nwant\n%s", first.LogFilter, wantFilter)
+	}
+	if !strings.Contains(got.Groups[1].LogFilter, `("lookup \"sku\" failed: connection refused")`) {
+		t.Errorf("logFilter of the second group does not search its message: %s", got.Groups[1].LogFilter)
+	}
+
+	// Repeated calls within the time bucket are served from the cache
+	if _, err := tool.Execute(context.Background(), map[string]interface{}{"order": "lastSeen", "limit": 5}); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.groupQueries) != 1 {
+		t.Errorf("repeated call queried the API again")
+	}
+}
+
+func TestListErrorGroupsService(t *testing.T) {
+	groups, _ := sampleGroups(time.Now())
+	api := &fakeAPI{Groups: groups}
+	tool := NewListErrorGroupsTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"service": "inventory", "timeRange": "7d"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got listErrorGroupsResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != 1 || got.Groups[0].GroupID != "COjT1YWsqfG8Vw" {
+		t.Errorf("got %+v, want only the inventory group", got.Groups)
+	}
+	if q := api.groupQueries[0]; q.Service != "inventory" || q.TimeRange != "PERIOD_1_WEEK" {
+		t.Errorf("query = %+v", q)
+	}
+}
+
+func TestListErrorGroupsInvalidArguments(t *testing.T) {
+	tests := []map[string]interface{}{
+		{"timeRange": "2h"},
+		{"order": "name"},
+		{"version": "v7"},
+	}
+
+	for _, args := range tests {
+		api := &fakeAPI{}
+		tool := NewListErrorGroupsTool(api, "example-project", logging.NewLogCache())
+		result, err := tool.Execute(context.Background(), args)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !result.IsError {
+			t.Errorf("%v was accepted", args)
+		}
+		if len(api.groupQueries) != 0 {
+			t.Errorf("%v reached the API", args)
+		}
+	}
+}
+
+func TestListErrorGroupsAPIError(t *testing.T) {
+	api := &fakeAPI{Err: errors.New("permission denied")}
+	tool := NewListErrorGroupsTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError || !strings.Contains(result.Content[0].Text, "permission denied") {
+		t.Errorf("got %+v, want an error result", result)
+	}
+}
+
+func equalGroupQuery(a, b GroupStatsQuery) bool {
+	return a.TimeRange == b.TimeRange && a.Service == b.Service && a.Version == b.Version &&
+		a.Order == b.Order && a.PageSize == b.PageSize && strings.Join(a.GroupIDs, ",") == strings.Join(b.GroupIDs, ",")
+}
@@ -0,0 +1,83 @@
+package errorreporting
+
+import (
+	"fmt"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+const (
+	maxSearchTextChars = 100
+	// Shorter excerpts match too many unrelated entries
+	minSearchTextChars = 8
+)
+
+// Time ranges accepted by the tools and the Error Reporting periods they map to
+var timeRangePeriods = map[string]string{
+	"1h":  "PERIOD_1_HOUR",
+	"6h":  "PERIOD_6_HOURS",
+	"1d":  "PERIOD_1_DAY",
+	"7d":  "PERIOD_1_WEEK",
+	"30d": "PERIOD_30_DAYS",
+}
+
+var timeRangeNames = []string{"1h", "6h", "1d", "7d", "30d"}
+
+// Resource labels that carry the service name Error Reporting reports for
+// Cloud Run, App Engine and Cloud Functions
+var serviceLabels = []string{"service_name", "module_id", "function_name"}
+
+// LogFilter builds a list_log_entries filter that finds the log entries an
+// error group was created from: error entries of the affected services that
+// contain the start of the representative message, within the group's lifetime
+func LogFilter(message string, services []string, firstSeen, lastSeen time.Time) string {
+	fb := logging.NewFilterBuilder().AddSeverity("ERROR")
+
+	if !firstSeen.IsZero() {
+		start := firstSeen
+		// Cloud Logging retains 30 days by default, so older bounds only cost scan time
+		if limit := time.Now().Add(-30 * 24 * time.Hour); start.Before(limit) {
+			start = limit
+		}
+		end := ""
+		if !lastSeen.IsZero() {
+			end = lastSeen.Add(time.Minute).UTC().Format(time.RFC3339)
+		}
+		fb.AddTimeRange(start.Add(-time.Minute).UTC().Format(time.RFC3339), end)
+	}
+
+	var serviceClauses []string
+	for _, service := range services {
+		for _, label := range serviceLabels {
+			serviceClauses = append(serviceClauses, fmt.Sprintf(`resource.labels.%s="%s"`, label, logging.EscapeFilterValue(service)))
+		}
+	}
+	if len(serviceClauses) > 0 {
+		fb.AddFilter(strings.Join(serviceClauses, " OR "))
+	}
+
+	if text := searchText(message); text != "" {
+		fb.AddFilter(fmt.Sprintf(`"%s"`, logging.EscapeFilterValue(text)))
+	}
+	return fb.Build()
+}
+
+// searchText takes the constant start of the first line of a message, up to
+// the first digit, so it matches every occurrence of the error
+func searchText(message string) string {
+	line := strings.TrimSpace(strings.SplitN(message, "\n", 2)[0])
+	if i := strings.IndexFunc(line, unicode.IsDigit); i >= minSearchTextChars {
+		line = line[:i]
+	}
+	if len(line) > maxSearchTextChars {
+		line = strings.ToValidUTF8(line[:maxSearchTextChars], "")
+	}
+	line = strings.TrimSpace(line)
+	if len(line) < minSearchTextChars {
+		return ""
+	}
+	return line
+}
@@ -0,0 +1,87 @@
+package errorreporting
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLogFilter(t *testing.T) {
+	firstSeen := time.Now().Add(-2 * time.Hour).UTC().Truncate(time.Second)
+	lastSeen := firstSeen.Add(90 * time.Minute)
+	start := firstSeen.Add(-time.Minute).Format(time.RFC3339)
+	end := lastSeen.Add(time.Minute).Format(time.RFC3339)
+
+	tests := []struct {
+		name     string
+		message  string
+		services []string
+		want     string
+	}{
+		{
+			name:     "service and message",
+			message:  "payment declined for order 1234: card expired\n\tat checkout.Charge(charge.go:42)",
+			services: []string{"checkout"},
+			want: `severity >= ERROR AND timestamp >= "` + start + `" AND timestamp <= "` + end + `"` +
+				` AND (resource.labels.service_name="checkout" OR resource.labels.module_id="checkout" OR resource.labels.function_name="checkout")` +
+				` AND ("payment declined for order")`,
+		},
+		{
+			name:     "escapes quotes",
+			message:  `lookup "sku" failed: connection refused`,
+			services: []string{`a"b`},
+			want: `severity >= ERROR AND timestamp >= "` + start + `" AND timestamp <= "` + end + `"` +
+				` AND (resource.labels.service_name="a\"b" OR resource.labels.module_id="a\"b" OR resource.labels.function_name="a\"b")` +
+				` AND ("lookup \"sku\" failed: connection refused")`,
+		},
+		{
+			name:    "message too short to search",
+			message: "err 42",
+			want:    `severity >= ERROR AND timestamp >= "` + start + `" AND timestamp <= "` + end + `"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := LogFilter(tt.message, tt.services, firstSeen, lastSeen); got != tt.want {
+				t.Errorf("LogFilter() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestLogFilterClampsToRetention(t *testing.T) {
+	firstSeen := time.Now().Add(-90 * 24 * time.Hour)
+	filter := LogFilter("", nil, firstSeen, time.Time{})
+
+	const prefix = `severity >= ERROR AND timestamp >= "`
+	if !strings.HasPrefix(filter, prefix) || strings.Contains(filter, "timestamp <=") {
+		t.Fatalf("unexpected filter %s", filter)
+	}
+	start, err := time.Parse(time.RFC3339, strings.TrimSuffix(strings.TrimPrefix(filter, prefix), `"`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if limit := time.Now().Add(-30*24*time.Hour - 2*time.Minute); start.Before(limit) {
+		t.Errorf("start %s is before the 30 day retention", start)
+	}
+}
+
+func TestSearchText(t *testing.T) {
+	tests := []struct {
+		message string
+		want    string
+	}{
+		{"payment declined for order 1234: card expired", "payment declined for order"},
+		{"  connection reset by peer\nstack trace", "connection reset by peer"},
+		{"timeout", ""},
+		{"E1234 failed", "E1234 failed"},
+		{strings.Repeat("x", 150), strings.Repeat("x", maxSearchTextChars)},
+	}
+
+	for _, tt := range tests {
+		if got := searchText(tt.message); got != tt.want {
+			t.Errorf("searchText(%q) = %q, want %q", tt.message, got, tt.want)
+		}
+	}
+}
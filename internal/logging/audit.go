@@ -0,0 +1,155 @@
+package logging
+
+import (
+	"fmt"
+	"strings"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	iamlogging "google.golang.org/genproto/googleapis/iam/v1/logging"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/protobuf/types/known/structpb"
+)
+
+// Cloud Audit Logs streams, as they appear in logName
+const (
+	auditLogPrefix   = "cloudaudit.googleapis.com"
+	auditActivityLog = auditLogPrefix + "%2Factivity"
+)
+
+var auditLogTypes = []string{"activity", "data_access", "system_event", "policy"}
+
+// AuditEntry is a Cloud Audit Logs protoPayload flattened for reading:
+// who called which method on what, from where, and whether it was allowed
+type AuditEntry struct {
+	ServiceName  string `json:"serviceName"`
+	MethodName   string `json:"methodName"`
+	ResourceName string `json:"resourceName,omitempty"`
+	Principal    string `json:"principal,omitempty"`
+	// Service accounts the principal acted through, outermost first
+	DelegationChain []string `json:"delegationChain,omitempty"`
+	CallerIP        string   `json:"callerIp,omitempty"`
+	UserAgent       string   `json:"userAgent,omitempty"`
+	// OK or the canonical code name such as PERMISSION_DENIED
+	Status        string `json:"status"`
+	StatusMessage string `json:"statusMessage,omitempty"`
+	// "granted" or "denied", then the permission and resource checked
+	Authorization []string               `json:"authorization,omitempty"`
+	Locations     []string               `json:"locations,omitempty"`
+	PolicyDelta   []BindingChange        `json:"policyDelta,omitempty"`
+	Request       map[string]interface{} `json:"request,omitempty"`
+	Response      map[string]interface{} `json:"response,omitempty"`
+	Metadata      map[string]interface{} `json:"metadata,omitempty"`
+}
+
+// BindingChange is one IAM role binding added or removed by a policy change
+type BindingChange struct {
+	Action    string `json:"action"`
+	Role      string `json:"role"`
+	Member    string `json:"member"`
+	Condition string `json:"condition,omitempty"`
+}
+
+// auditLog returns the payload of a Cloud Audit Logs entry. Importing the
+// audit package registers AuditLog so that logadmin can decode protoPayload.
+func auditLog(entry *logging.Entry) (*audit.AuditLog, bool) {
+	payload, ok := entry.Payload.(*audit.AuditLog)
+	return payload, ok && payload != nil
+}
+
+// auditLogType returns the stream of an audit log name, e.g. data_access
+func auditLogType(logName string) string {
+	if i := strings.Index(logName, auditLogPrefix+"%2F"); i >= 0 {
+		return logName[i+len(auditLogPrefix)+3:]
+	}
+	return ""
+}
+
+func newAuditEntry(payload *audit.AuditLog) *AuditEntry {
+	entry := &AuditEntry{
+		ServiceName:  payload.ServiceName,
+		MethodName:   payload.MethodName,
+		ResourceName: payload.ResourceName,
+		Status:       code.Code_OK.String(),
+		Request:      structMap(payload.Request),
+		Response:     structMap(payload.Response),
+		Metadata:     structMap(payload.Metadata),
+	}
+
+	if info := payload.AuthenticationInfo; info != nil {
+		entry.Principal = info.PrincipalEmail
+		if entry.Principal == "" {
+			entry.Principal = info.PrincipalSubject
+		}
+		for _, delegation := range info.ServiceAccountDelegationInfo {
+			if p := delegation.GetFirstPartyPrincipal(); p != nil {
+				entry.DelegationChain = append(entry.DelegationChain, p.PrincipalEmail)
+			}
+		}
+	}
+	if metadata := payload.RequestMetadata; metadata != nil {
+		entry.CallerIP = metadata.CallerIp
+		entry.UserAgent = metadata.CallerSuppliedUserAgent
+	}
+	if status := payload.Status; status != nil && status.Code != 0 {
+		entry.Status = code.Code(status.Code).String()
+		entry.StatusMessage = status.Message
+	}
+	for _, authz := range payload.AuthorizationInfo {
+		decision := "denied"
+		if authz.Granted {
+			decision = "granted"
+		}
+		entry.Authorization = append(entry.Authorization, fmt.Sprintf("%s %s on %s", decision, authz.Permission, authz.Resource))
+	}
+	if location := payload.ResourceLocation; location != nil {
+		entry.Locations = location.CurrentLocations
+	}
+	entry.PolicyDelta = policyDelta(payload)
+	return entry
+}
+
+// policyDelta reads IAM binding changes from serviceData, where SetIamPolicy
+// reports them, or from the policyDelta some services put in metadata
+func policyDelta(payload *audit.AuditLog) []BindingChange {
+	var changes []BindingChange
+	if payload.ServiceData != nil {
+		if msg, err := payload.ServiceData.UnmarshalNew(); err == nil {
+			if data, ok := msg.(*iamlogging.AuditData); ok {
+				for _, delta := range data.GetPolicyDelta().GetBindingDeltas() {
+					change := BindingChange{
+						Action: delta.Action.String(),
+						Role:   delta.Role,
+						Member: delta.Member,
+					}
+					if delta.Condition != nil {
+						change.Condition = delta.Condition.Expression
+					}
+					changes = append(changes, change)
+				}
+			}
+		}
+	}
+	if len(changes) > 0 {
+		return changes
+	}
+
+	deltas := payload.Metadata.GetFields()["policyDelta"].GetStructValue().GetFields()["bindingDeltas"].GetListValue().GetValues()
+	for _, value := range deltas {
+		fields := value.GetStructValue().GetFields()
+		changes = append(changes, BindingChange{
+			Action:    fields["action"].GetStringValue(),
+			Role:      fields["role"].GetStringValue(),
+			Member:    fields["member"].GetStringValue(),
+			Condition: fields["condition"].GetStructValue().GetFields()["expression"].GetStringValue(),
+		})
+	}
+	return changes
+}
+
+func structMap(s *structpb.Struct) map[string]interface{} {
+	if s == nil || len(s.Fields) == 0 {
+		return nil
+	}
+	return s.AsMap()
+}
@@ -1,26 +1,35 @@
 package logging
 
 import (
-	"crypto/md5"
+	"crypto/sha256"
+	"encoding/json"
 	"fmt"
+	"log"
+	"strings"
 	"sync"
 	"time"
 )
 
+const historicalCacheTTL = 10 * time.Minute
+
 type CacheEntry struct {
 	Data      []LogEntry
+	Value     json.RawMessage // Results other than log entries, e.g. aggregations
 	Timestamp time.Time
 	TTL       time.Duration
 }
 
 type LogCache struct {
-	mu    sync.RWMutex
-	cache map[string]*CacheEntry
+	mu         sync.RWMutex
+	cache      map[string]*CacheEntry
+	disk       *DiskCache
+	timeBucket time.Duration
 }
 
 func NewLogCache() *LogCache {
 	cache := &LogCache{
-		cache: make(map[string]*CacheEntry),
+		cache:      make(map[string]*CacheEntry),
+		timeBucket: DefaultCacheTimeBucket,
 	}
 
 	// Start cleanup routine
@@ -29,23 +38,42 @@ func NewLogCache() *LogCache {
 	return cache
 }
 
+// SetDiskTier enables a persistent tier used for closed historical windows
+func (c *LogCache) SetDiskTier(disk *DiskCache) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.disk = disk
+}
+
 func (c *LogCache) Get(key string) ([]LogEntry, bool) {
 	c.mu.RLock()
-	defer c.mu.RUnlock()
-
 	entry, exists := c.cache[key]
-	if !exists {
-		return nil, false
+	c.mu.RUnlock()
+
+	if exists && entry.Data != nil && time.Since(entry.Timestamp) <= entry.TTL {
+		return entry.Data, true
 	}
 
-	if time.Since(entry.Timestamp) > entry.TTL {
+	raw, found := c.getDisk(key)
+	if !found {
+		return nil, false
+	}
+	var data []LogEntry
+	if err := json.Unmarshal(raw, &data); err != nil {
 		return nil, false
 	}
 
-	return entry.Data, true
+	// Promote to memory to avoid re-reading the file on subsequent hits
+	c.Set(key, data, historicalCacheTTL)
+	return data, true
 }
 
 func (c *LogCache) Set(key string, data []LogEntry, ttl time.Duration) {
+	if data == nil {
+		// Distinguish an empty result from a value entry
+		data = []LogEntry{}
+	}
+
 	c.mu.Lock()
 	defer c.mu.Unlock()
 
@@ -56,11 +84,122 @@ func (c *LogCache) Set(key string, data []LogEntry, ttl time.Duration) {
 	}
 }
 
+// SetHistorical caches results for a window that can no longer change.
+// They are also written to the disk tier when one is configured.
+func (c *LogCache) SetHistorical(key string, data []LogEntry) {
+	c.Set(key, data, historicalCacheTTL)
+	c.setDisk(key, data)
+}
+
+// GetValue decodes a cached result that is not a list of log entries into v
+func (c *LogCache) GetValue(key string, v interface{}) bool {
+	c.mu.RLock()
+	entry, exists := c.cache[key]
+	c.mu.RUnlock()
+
+	if exists && entry.Value != nil && time.Since(entry.Timestamp) <= entry.TTL {
+		return json.Unmarshal(entry.Value, v) == nil
+	}
+
+	raw, found := c.getDisk(key)
+	if !found || json.Unmarshal(raw, v) != nil {
+		return false
+	}
+
+	c.setRaw(key, raw, historicalCacheTTL)
+	return true
+}
+
+func (c *LogCache) SetValue(key string, v interface{}, ttl time.Duration) {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to cache value: %v", err)
+		return
+	}
+	c.setRaw(key, raw, ttl)
+}
+
+// SetHistoricalValue is SetHistorical for results other than log entries
+func (c *LogCache) SetHistoricalValue(key string, v interface{}) {
+	c.SetValue(key, v, historicalCacheTTL)
+	c.setDisk(key, v)
+}
+
+func (c *LogCache) setRaw(key string, raw json.RawMessage, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.cache[key] = &CacheEntry{
+		Value:     raw,
+		Timestamp: time.Now(),
+		TTL:       ttl,
+	}
+}
+
+func (c *LogCache) getDisk(key string) (json.RawMessage, bool) {
+	c.mu.RLock()
+	disk := c.disk
+	c.mu.RUnlock()
+
+	if disk == nil {
+		return nil, false
+	}
+	return disk.Get(key)
+}
+
+func (c *LogCache) setDisk(key string, v interface{}) {
+	c.mu.RLock()
+	disk := c.disk
+	c.mu.RUnlock()
+
+	if disk == nil {
+		return
+	}
+	raw, err := json.Marshal(v)
+	if err != nil {
+		log.Printf("Failed to marshal disk cache value: %v", err)
+		return
+	}
+	if err := disk.Set(key, raw); err != nil {
+		log.Printf("Failed to write disk cache: %v", err)
+	}
+}
+
+// SetTimeBucket sets the granularity timestamp bounds are rounded to in cache keys
+func (c *LogCache) SetTimeBucket(bucket time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.timeBucket = bucket
+}
+
+// TimeBucket returns the granularity timestamp bounds are rounded to in cache keys
+func (c *LogCache) TimeBucket() time.Duration {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.timeBucket
+}
+
 func (c *LogCache) GenerateKey(params interface{}) string {
-	hash := md5.Sum([]byte(fmt.Sprintf("%+v", params)))
+	// JSON encoding has a fixed field order and sorted map keys
+	data, err := json.Marshal(params)
+	if err != nil {
+		data = []byte(fmt.Sprintf("%+v", params))
+	}
+	hash := sha256.Sum256(data)
 	return fmt.Sprintf("%x", hash)
 }
 
+// GenerateQueryKey builds a key from the normalized filter, so logically
+// identical queries share an entry regardless of which tool issued them
+func (c *LogCache) GenerateQueryKey(filter, query string, pageSize int, orderBy string) string {
+	return c.GenerateKey(queryCacheKey{
+		Filter:   NormalizeFilter(filter, c.TimeBucket()),
+		Query:    strings.Join(strings.Fields(strings.ToLower(query)), " "),
+		PageSize: pageSize,
+		OrderBy:  strings.ToLower(strings.Join(strings.Fields(orderBy), " ")),
+	})
+}
+
 func (c *LogCache) cleanup() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
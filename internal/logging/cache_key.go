@@ -0,0 +1,207 @@
+package logging
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultCacheTimeBucket is the granularity that timestamp bounds of open
+// windows are rounded to before hashing, so relative windows computed from
+// time.Now() share entries.
+const DefaultCacheTimeBucket = time.Minute
+
+var (
+	comparisonOperators = []string{">=", "<=", "!=", "=~", "!~", "=", "<", ">", ":"}
+	timestampClause     = regexp.MustCompile(`^timestamp(>=|<=|>|<|=)"([^"]+)"$`)
+)
+
+// queryCacheKey identifies a query independently of which tool issued it.
+// Logically identical queries produce identical keys.
+type queryCacheKey struct {
+	Filter   string `json:"filter"`
+	Query    string `json:"query,omitempty"`
+	PageSize int    `json:"pageSize"`
+	OrderBy  string `json:"orderBy,omitempty"`
+}
+
+// NormalizeFilter rewrites a Cloud Logging filter into a canonical form.
+// Top-level AND clauses are whitespace-normalized, de-duplicated and sorted.
+// Since OR binds tighter than AND in the logging query language, reordering
+// top-level AND clauses never changes the meaning of the filter.
+//
+// Timestamp bounds of open windows are rounded down to the given bucket;
+// their results are only cached briefly, so calls a few seconds apart may
+// share them. Closed windows are cached permanently and keep their exact
+// bounds, so that different windows never share an entry.
+func NormalizeFilter(filter string, bucket time.Duration) string {
+	if IsClosedWindow(FilterEndTime(filter)) {
+		bucket = 0
+	}
+	clauses := splitTopLevelAnd(filter)
+
+	seen := make(map[string]bool, len(clauses))
+	normalized := make([]string, 0, len(clauses))
+	for _, clause := range clauses {
+		clause = normalizeClause(clause)
+		if clause == "" {
+			continue
+		}
+		clause = roundTimestampClause(clause, bucket)
+		if seen[clause] {
+			continue
+		}
+		seen[clause] = true
+		normalized = append(normalized, clause)
+	}
+
+	sort.Strings(normalized)
+	return strings.Join(normalized, " AND ")
+}
+
+// splitTopLevelAnd splits a filter on AND operators that are outside quotes
+// and parentheses.
+func splitTopLevelAnd(filter string) []string {
+	var clauses []string
+	var current strings.Builder
+	depth := 0
+	inQuote := false
+
+	runes := []rune(filter)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		switch {
+		case inQuote:
+			if r == '\\' && i+1 < len(runes) {
+				current.WriteRune(r)
+				i++
+				current.WriteRune(runes[i])
+				continue
+			}
+			if r == '"' {
+				inQuote = false
+			}
+		case r == '"':
+			inQuote = true
+		case r == '(':
+			depth++
+		case r == ')':
+			if depth > 0 {
+				depth--
+			}
+		case depth == 0 && isSpace(r):
+			if end := andKeywordEnd(runes, i+1); end > 0 {
+				clauses = append(clauses, current.String())
+				current.Reset()
+				// Skip the keyword itself; the following whitespace is trimmed later
+				i = end - 1
+				continue
+			}
+		}
+
+		current.WriteRune(r)
+	}
+	clauses = append(clauses, current.String())
+	return clauses
+}
+
+// andKeywordEnd returns the index just past an AND keyword that follows
+// optional whitespace at start, or -1 if there is none.
+func andKeywordEnd(runes []rune, start int) int {
+	for start < len(runes) && isSpace(runes[start]) {
+		start++
+	}
+	end := start + len("AND")
+	if end > len(runes) || string(runes[start:end]) != "AND" {
+		return -1
+	}
+	if end < len(runes) && !isSpace(runes[end]) && runes[end] != '(' {
+		return -1
+	}
+	return end
+}
+
+// normalizeClause collapses whitespace outside quotes and removes spaces
+// around comparison operators.
+func normalizeClause(clause string) string {
+	var b strings.Builder
+	inQuote := false
+	pendingSpace := false
+
+	runes := []rune(strings.TrimSpace(clause))
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+
+		if inQuote {
+			b.WriteRune(r)
+			if r == '\\' && i+1 < len(runes) {
+				i++
+				b.WriteRune(runes[i])
+			} else if r == '"' {
+				inQuote = false
+			}
+			continue
+		}
+
+		if isSpace(r) {
+			pendingSpace = true
+			continue
+		}
+
+		if op := operatorAt(runes, i); op != "" {
+			b.WriteString(op)
+			i += len(op) - 1
+			pendingSpace = false
+			// Drop whitespace following the operator
+			for i+1 < len(runes) && isSpace(runes[i+1]) {
+				i++
+			}
+			continue
+		}
+
+		if pendingSpace && b.Len() > 0 {
+			b.WriteRune(' ')
+		}
+		pendingSpace = false
+
+		if r == '"' {
+			inQuote = true
+		}
+		b.WriteRune(r)
+	}
+
+	return b.String()
+}
+
+func operatorAt(runes []rune, i int) string {
+	for _, op := range comparisonOperators {
+		end := i + len(op)
+		if end <= len(runes) && string(runes[i:end]) == op {
+			return op
+		}
+	}
+	return ""
+}
+
+// roundTimestampClause rewrites a timestamp bound in UTC, rounded down to
+// bucket unless it is zero
+func roundTimestampClause(clause string, bucket time.Duration) string {
+	matches := timestampClause.FindStringSubmatch(clause)
+	if matches == nil {
+		return clause
+	}
+	ts, err := time.Parse(time.RFC3339Nano, matches[2])
+	if err != nil {
+		return clause
+	}
+	if bucket > 0 {
+		ts = ts.Truncate(bucket)
+	}
+	return `timestamp` + matches[1] + `"` + ts.UTC().Format(time.RFC3339Nano) + `"`
+}
+
+func isSpace(r rune) bool {
+	return r == ' ' || r == '\t' || r == '\n' || r == '\r'
+}
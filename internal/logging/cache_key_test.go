@@ -0,0 +1,167 @@
+package logging
+
+import (
+	"fmt"
+	"testing"
+	"time"
+)
+
+func TestNormalizeFilter(t *testing.T) {
+	tests := []struct {
+		name   string
+		filter string
+		want   string
+	}{
+		{
+			name:   "sorts top-level clauses",
+			filter: `severity>=ERROR AND resource.type="cloud_run_revision"`,
+			want:   `resource.type="cloud_run_revision" AND severity>=ERROR`,
+		},
+		{
+			name:   "collapses whitespace outside quotes",
+			filter: "  severity >=  ERROR\n\tAND  jsonPayload.message:\"a  b\"",
+			want:   `jsonPayload.message:"a  b" AND severity>=ERROR`,
+		},
+		{
+			name:   "drops duplicate clauses",
+			filter: `severity>=ERROR AND severity >= ERROR`,
+			want:   `severity>=ERROR`,
+		},
+		{
+			name:   "keeps OR groups together",
+			filter: `(b="2" OR a="1") AND c="3"`,
+			want:   `(b="2" OR a="1") AND c="3"`,
+		},
+		{
+			name:   "ignores AND inside quotes",
+			filter: `textPayload:"x AND y" AND a="1"`,
+			want:   `a="1" AND textPayload:"x AND y"`,
+		},
+		{
+			name:   "keeps exact bounds of closed windows",
+			filter: `timestamp>="2024-01-01T10:00:05+00:00" AND timestamp<="2024-01-01T11:00:55Z"`,
+			want:   `timestamp<="2024-01-01T11:00:55Z" AND timestamp>="2024-01-01T10:00:05Z"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NormalizeFilter(tt.filter, time.Minute); got != tt.want {
+				t.Errorf("NormalizeFilter(%q) = %q, want %q", tt.filter, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalizeFilterRoundsOpenWindows(t *testing.T) {
+	start := time.Now().UTC().Add(-time.Hour).Truncate(time.Minute)
+	filter := func(offset time.Duration) string {
+		return fmt.Sprintf(`timestamp>="%s"`, start.Add(offset).Format(time.RFC3339))
+	}
+
+	if a, b := NormalizeFilter(filter(5*time.Second), time.Minute), NormalizeFilter(filter(55*time.Second), time.Minute); a != b {
+		t.Errorf("open windows in the same bucket differ: %q and %q", a, b)
+	}
+	if a, b := NormalizeFilter(filter(5*time.Second), time.Minute), NormalizeFilter(filter(65*time.Second), time.Minute); a == b {
+		t.Errorf("open windows in different buckets share %q", a)
+	}
+}
+
+func TestGenerateQueryKey(t *testing.T) {
+	cache := NewLogCache()
+	key := func(filter string) string {
+		return cache.GenerateQueryKey(filter, "", 100, "timestamp desc")
+	}
+
+	equivalent := [][2]string{
+		{
+			`severity>=ERROR AND resource.type="cloud_run_revision"`,
+			`resource.type = "cloud_run_revision"   AND severity>=ERROR`,
+		},
+		{
+			`timestamp>="2024-01-01T10:00:00Z" AND timestamp<="2024-01-01T11:00:00Z" AND severity>=ERROR`,
+			`severity>=ERROR AND timestamp<="2024-01-01T11:00:00Z" AND timestamp>="2024-01-01T10:00:00Z"`,
+		},
+	}
+	for _, pair := range equivalent {
+		if key(pair[0]) != key(pair[1]) {
+			t.Errorf("equivalent filters have different keys:\n%s\n%s", pair[0], pair[1])
+		}
+	}
+
+	different := [][2]string{
+		{
+			`timestamp>="2024-01-01T10:00:05Z" AND timestamp<="2024-01-01T11:00:00Z"`,
+			`timestamp>="2024-01-01T10:00:55Z" AND timestamp<="2024-01-01T11:00:00Z"`,
+		},
+		{
+			`timestamp>="2024-01-01T10:00:00Z" AND timestamp<="2024-01-01T11:00:05Z"`,
+			`timestamp>="2024-01-01T10:00:00Z" AND timestamp<="2024-01-01T11:00:55Z"`,
+		},
+		{
+			`severity>=ERROR`,
+			`severity>=WARNING`,
+		},
+	}
+	for _, pair := range different {
+		if key(pair[0]) == key(pair[1]) {
+			t.Errorf("different filters share a key:\n%s\n%s", pair[0], pair[1])
+		}
+	}
+
+	if cache.GenerateQueryKey("a=1", "", 100, "timestamp desc") == cache.GenerateQueryKey("a=1", "", 50, "timestamp desc") {
+		t.Error("different page sizes share a key")
+	}
+}
+
+func TestLogCacheEquivalentFilters(t *testing.T) {
+	disk, err := NewDiskCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cache := NewLogCache()
+	cache.SetDiskTier(disk)
+
+	stored := `severity>=ERROR AND timestamp>="2024-01-01T10:00:00Z" AND timestamp<="2024-01-01T11:00:00Z"`
+	equivalent := `timestamp <= "2024-01-01T11:00:00Z"  AND timestamp>="2024-01-01T10:00:00+00:00" AND severity >= ERROR`
+	other := `severity>=ERROR AND timestamp>="2024-01-01T10:00:30Z" AND timestamp<="2024-01-01T11:00:00Z"`
+	entries := []LogEntry{{Timestamp: "2024-01-01T10:30:00Z", Severity: "ERROR", InsertID: "a1"}}
+
+	cache.SetHistorical(cache.GenerateQueryKey(stored, "", 100, "timestamp desc"), entries)
+
+	got, ok := cache.Get(cache.GenerateQueryKey(equivalent, "", 100, "timestamp desc"))
+	if !ok || len(got) != 1 || got[0].InsertID != "a1" {
+		t.Fatalf("equivalent filter missed the cache: %v, %v", got, ok)
+	}
+	if _, ok := cache.Get(cache.GenerateQueryKey(other, "", 100, "timestamp desc")); ok {
+		t.Error("a different window was served from the cache")
+	}
+
+	// A new process finds the closed window on disk under the equivalent filter
+	restarted := NewLogCache()
+	restarted.SetDiskTier(disk)
+	if got, ok := restarted.Get(restarted.GenerateQueryKey(equivalent, "", 100, "timestamp desc")); !ok || len(got) != 1 {
+		t.Errorf("equivalent filter missed the disk tier: %v, %v", got, ok)
+	}
+}
+
+func TestFilterEndTime(t *testing.T) {
+	tests := []struct {
+		filter string
+		want   string
+	}{
+		{`severity>=ERROR AND timestamp <= "2024-01-01T10:00:00Z"`, "2024-01-01T10:00:00Z"},
+		{`(timestamp>="2024-01-01T09:00:00Z" AND timestamp<"2024-01-01T10:00:00Z") AND a="1"`, "2024-01-01T10:00:00Z"},
+		{`timestamp<="2024-01-02T00:00:00Z" AND timestamp<="2024-01-01T10:00:00Z"`, "2024-01-01T10:00:00Z"},
+		{`a="1" OR timestamp<="2024-01-01T10:00:00Z"`, ""},
+		{`(a="1") OR (timestamp<="2024-01-01T10:00:00Z")`, ""},
+		{`NOT timestamp<="2024-01-01T10:00:00Z"`, ""},
+		{`timestamp>="2024-01-01T10:00:00Z"`, ""},
+	}
+
+	for _, tt := range tests {
+		if got := FilterEndTime(tt.filter); got != tt.want {
+			t.Errorf("FilterEndTime(%q) = %q, want %q", tt.filter, got, tt.want)
+		}
+	}
+}
@@ -3,12 +3,17 @@ package logging
 import (
 	"context"
 
+	"cloud.google.com/go/logging"
 	"cloud.google.com/go/logging/logadmin"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
 )
 
 type Client struct {
 	client    *logadmin.Client
 	projectID string
+	cache     *LogCache
+	governor  *quota.Governor
 }
 
 func NewClient(ctx context.Context, projectID string) (*Client, error) {
@@ -20,6 +25,8 @@ func NewClient(ctx context.Context, projectID string) (*Client, error) {
 	return &Client{
 		client:    client,
 		projectID: projectID,
+		cache:     NewLogCache(),
+		governor:  quota.NewGovernor(quota.DefaultReadsPerMinute),
 	}, nil
 }
 
@@ -34,3 +41,49 @@ func (c *Client) ProjectID() string {
 func (c *Client) LogAdminClient() *logadmin.Client {
 	return c.client
 }
+
+// Cache returns the query cache shared by all tools using this client
+func (c *Client) Cache() *LogCache {
+	return c.cache
+}
+
+// QuotaGovernor returns the read budget shared by all tools using this client
+func (c *Client) QuotaGovernor() *quota.Governor {
+	return c.governor
+}
+
+// SetQuotaGovernor replaces the read budget, e.g. with one shared across API clients
+func (c *Client) SetQuotaGovernor(governor *quota.Governor) {
+	c.governor = governor
+}
+
+// Entries lists log entries, acquiring a quota token before every page fetch
+func (c *Client) Entries(ctx context.Context, opts ...logadmin.EntriesOption) *EntryIterator {
+	return &EntryIterator{
+		ctx:      ctx,
+		it:       c.client.Entries(ctx, opts...),
+		governor: c.governor,
+	}
+}
+
+type EntryIterator struct {
+	ctx      context.Context
+	it       *logadmin.EntryIterator
+	governor *quota.Governor
+	fetched  bool
+}
+
+func (it *EntryIterator) Next() (*logging.Entry, error) {
+	pageInfo := it.it.PageInfo()
+	if pageInfo.Remaining() == 0 {
+		// The buffer is empty, so Next will fetch a page unless the last one was final
+		if it.fetched && pageInfo.Token == "" {
+			return it.it.Next()
+		}
+		if err := it.governor.Wait(it.ctx); err != nil {
+			return nil, err
+		}
+		it.fetched = true
+	}
+	return it.it.Next()
+}
@@ -0,0 +1,459 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	compareModeWindows   = "windows"
+	compareModeRevisions = "revisions"
+	defaultCompareItems  = 10
+	// Revisions rarely overlap, so by default look back far enough to see both
+	defaultRevisionWindow = 24 * time.Hour
+)
+
+type CompareLogsTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type CompareLogsArgs struct {
+	Filter            string `json:"filter,omitempty"`
+	StartTime         string `json:"startTime,omitempty"`
+	EndTime           string `json:"endTime,omitempty"`
+	BaselineStartTime string `json:"baselineStartTime,omitempty"`
+	BaselineEndTime   string `json:"baselineEndTime,omitempty"`
+	Revision          string `json:"revision,omitempty"`
+	BaselineRevision  string `json:"baselineRevision,omitempty"`
+	MaxItems          int    `json:"maxItems,omitempty"`
+	MaxEntries        int    `json:"maxEntries,omitempty"`
+}
+
+// compareSide accumulates what one side of the comparison saw
+type compareSide struct {
+	Label      string    `json:"label"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Entries    int       `json:"entries"`
+	Errors     int       `json:"errors"`
+	FirstSeen  time.Time `json:"firstSeen"`
+	LastSeen   time.Time `json:"lastSeen"`
+	severities map[string]int
+	patterns   map[int]int
+	errors     *ErrorGrouper
+}
+
+type volumeDiff struct {
+	Current      int     `json:"current"`
+	Baseline     int     `json:"baseline"`
+	CurrentRate  float64 `json:"currentPerMinute"`
+	BaselineRate float64 `json:"baselinePerMinute"`
+	Change       float64 `json:"change,omitempty"`
+	ZScore       float64 `json:"zScore"`
+	Significant  bool    `json:"significant"`
+}
+
+type severityDiff struct {
+	Severity      string  `json:"severity"`
+	Current       int     `json:"current"`
+	Baseline      int     `json:"baseline"`
+	CurrentShare  float64 `json:"currentPercent"`
+	BaselineShare float64 `json:"baselinePercent"`
+	ZScore        float64 `json:"zScore"`
+	Significant   bool    `json:"significant"`
+}
+
+type errorGroupDiff struct {
+	Fingerprint string   `json:"fingerprint"`
+	Message     string   `json:"message"`
+	Current     int      `json:"current"`
+	Baseline    int      `json:"baseline"`
+	Status      string   `json:"status,omitempty"`
+	ZScore      float64  `json:"zScore"`
+	Significant bool     `json:"significant"`
+	Services    []string `json:"services,omitempty"`
+}
+
+type compareLogsResult struct {
+	Mode        string           `json:"mode"`
+	Filter      string           `json:"filter"`
+	Current     compareSide      `json:"current"`
+	Baseline    compareSide      `json:"baseline"`
+	Truncated   bool             `json:"truncated"`
+	Volume      volumeDiff       `json:"volume"`
+	Severities  []severityDiff   `json:"severities"`
+	ErrorGroups []errorGroupDiff `json:"errorGroups"`
+	NewPatterns []LogPattern     `json:"newPatterns"`
+}
+
+func NewCompareLogsTool(client *Client) *CompareLogsTool {
+	return &CompareLogsTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *CompareLogsTool) Name() string {
+	return "compare_logs"
+}
+
+func (t *CompareLogsTool) Description() string {
+	return "Compare logs before and after a change: either two time windows (baselineStartTime/baselineEndTime, defaulting to the preceding window of equal length) or two Cloud Run revisions (revision and baselineRevision, over the last 24h by default). Reports volume, severity distribution, error groups and new log patterns, flagging differences with |zScore| >= 3 as significant."
+}
+
+func (t *CompareLogsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"baselineStartTime": {
+				Type: "string",
+			},
+			"baselineEndTime": {
+				Type: "string",
+			},
+			"revision": {
+				Type: "string",
+			},
+			"baselineRevision": {
+				Type: "string",
+			},
+			"maxItems": {
+				Type: "integer",
+			},
+			"maxEntries": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *CompareLogsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params CompareLogsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	mode := compareModeWindows
+	defaultWindow := time.Hour
+	if params.Revision != "" || params.BaselineRevision != "" {
+		if params.Revision == "" || params.BaselineRevision == "" {
+			return ErrorResult("Error: revision and baselineRevision must be given together"), nil
+		}
+		if params.BaselineStartTime != "" || params.BaselineEndTime != "" {
+			return ErrorResult("Error: baseline times cannot be combined with a revision comparison"), nil
+		}
+		mode = compareModeRevisions
+		defaultWindow = defaultRevisionWindow
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, defaultWindow)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	baseline := TimeWindow{Start: window.Start.Add(-window.Duration()), End: window.Start}
+	if params.BaselineStartTime != "" || params.BaselineEndTime != "" {
+		baseline, err = ParseTimeWindow(params.BaselineStartTime, params.BaselineEndTime, window.Duration())
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Error: baseline: %v", err)), nil
+		}
+	}
+
+	if params.MaxItems <= 0 {
+		params.MaxItems = defaultCompareItems
+	}
+	params.MaxEntries = scanLimit(params.MaxEntries)
+
+	keyParams := map[string]interface{}{
+		"tool":       t.Name(),
+		"mode":       mode,
+		"filter":     NormalizeFilter(window.Filter(params.Filter), t.cache.TimeBucket()),
+		"maxEntries": params.MaxEntries,
+	}
+	if mode == compareModeRevisions {
+		keyParams["revisions"] = []string{params.Revision, params.BaselineRevision}
+	} else {
+		keyParams["baseline"] = NormalizeFilter(baseline.Filter(params.Filter), t.cache.TimeBucket())
+	}
+	cacheKey := t.cache.GenerateKey(keyParams)
+
+	var result compareLogsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for log comparison: %s", result.Filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			if mode == compareModeRevisions {
+				result, err = t.compareRevisions(ctx, params, window)
+			} else {
+				result, err = t.compareWindows(ctx, params, window, baseline)
+			}
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to compare logs: %v", err)
+			return ErrorResult(fmt.Sprintf("Error comparing logs: %v", err)), nil
+		}
+
+		// An explicit baseline can still be open when the window is closed
+		if window.IsClosed() && baseline.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	if len(result.ErrorGroups) > params.MaxItems {
+		result.ErrorGroups = result.ErrorGroups[:params.MaxItems]
+	}
+	if len(result.NewPatterns) > params.MaxItems {
+		result.NewPatterns = result.NewPatterns[:params.MaxItems]
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log comparison: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *CompareLogsTool) compareWindows(ctx context.Context, params CompareLogsArgs, window, baseline TimeWindow) (compareLogsResult, error) {
+	miner := NewPatternMiner(0)
+	current := newCompareSide("current", window)
+	previous := newCompareSide("baseline", baseline)
+
+	scanned, baselineTruncated, err := scanEntries(ctx, t.client, baseline.Filter(params.Filter), params.MaxEntries, func(entry *logging.Entry) {
+		previous.add(entry, miner)
+	})
+	if err != nil {
+		return compareLogsResult{}, err
+	}
+	previous.Entries = scanned
+
+	filter := window.Filter(params.Filter)
+	scanned, truncated, err := scanEntries(ctx, t.client, filter, params.MaxEntries, func(entry *logging.Entry) {
+		current.add(entry, miner)
+	})
+	if err != nil {
+		return compareLogsResult{}, err
+	}
+	current.Entries = scanned
+
+	result := buildComparison(current, previous, miner)
+	result.Mode = compareModeWindows
+	result.Filter = filter
+	result.Truncated = truncated || baselineTruncated
+	result.Volume = compareVolume(current, previous, window.Duration(), baseline.Duration())
+	return result, nil
+}
+
+// compareRevisions scans the window once and splits entries by the
+// revision_name they were logged from
+func (t *CompareLogsTool) compareRevisions(ctx context.Context, params CompareLogsArgs, window TimeWindow) (compareLogsResult, error) {
+	miner := NewPatternMiner(0)
+	current := newCompareSide(params.Revision, window)
+	previous := newCompareSide(params.BaselineRevision, window)
+
+	filter := window.Filter(NewFilterBuilder().
+		AddFilter(params.Filter).
+		AddFilter(fmt.Sprintf(`resource.labels.revision_name=("%s" OR "%s")`,
+			EscapeFilterValue(params.Revision), EscapeFilterValue(params.BaselineRevision))).
+		Build())
+
+	_, truncated, err := scanEntries(ctx, t.client, filter, params.MaxEntries, func(entry *logging.Entry) {
+		info := ExtractServiceInfoFromLogEntry(newLogEntry(entry))
+		switch info.RevisionName {
+		case params.Revision:
+			current.Entries++
+			current.add(entry, miner)
+		case params.BaselineRevision:
+			previous.Entries++
+			previous.add(entry, miner)
+		}
+	})
+	if err != nil {
+		return compareLogsResult{}, err
+	}
+
+	result := buildComparison(current, previous, miner)
+	result.Mode = compareModeRevisions
+	result.Filter = filter
+	result.Truncated = truncated
+	// Revisions serve traffic at different times, so compare rates over
+	// the span each one was actually seen logging
+	result.Volume = compareVolume(current, previous, current.activeSpan(), previous.activeSpan())
+	return result, nil
+}
+
+func newCompareSide(label string, window TimeWindow) *compareSide {
+	return &compareSide{
+		Label:      label,
+		Start:      window.Start,
+		End:        window.End,
+		severities: make(map[string]int),
+		patterns:   make(map[int]int),
+		errors:     NewErrorGrouper(),
+	}
+}
+
+func (s *compareSide) add(entry *logging.Entry, miner *PatternMiner) {
+	logEntry := newLogEntry(entry)
+	info := ExtractServiceInfoFromLogEntry(logEntry)
+
+	if s.FirstSeen.IsZero() || entry.Timestamp.Before(s.FirstSeen) {
+		s.FirstSeen = entry.Timestamp
+	}
+	if entry.Timestamp.After(s.LastSeen) {
+		s.LastSeen = entry.Timestamp
+	}
+
+	s.severities[strings.ToUpper(entry.Severity.String())]++
+	s.patterns[miner.Add(info.Message)]++
+	if entry.Severity >= logging.Error {
+		s.Errors++
+		s.errors.Add(logEntry)
+	}
+}
+
+// activeSpan is the time between the first and last entry, at least a minute
+func (s *compareSide) activeSpan() time.Duration {
+	span := s.LastSeen.Sub(s.FirstSeen)
+	if span < time.Minute {
+		return time.Minute
+	}
+	return span
+}
+
+func compareVolume(current, baseline *compareSide, currentSpan, baselineSpan time.Duration) volumeDiff {
+	diff := volumeDiff{
+		Current:      current.Entries,
+		Baseline:     baseline.Entries,
+		CurrentRate:  round2(float64(current.Entries) / currentSpan.Minutes()),
+		BaselineRate: round2(float64(baseline.Entries) / baselineSpan.Minutes()),
+		ZScore:       rateZScore(current.Entries, currentSpan.Minutes(), baseline.Entries, baselineSpan.Minutes()),
+	}
+	if diff.BaselineRate > 0 {
+		diff.Change = round2(diff.CurrentRate / diff.BaselineRate)
+	}
+	diff.Significant = isSignificant(diff.ZScore)
+	return diff
+}
+
+// buildComparison compares severity shares, error groups and patterns.
+// Counts are compared as shares of each side's entries, which works whether
+// or not the sides cover the same amount of time.
+func buildComparison(current, baseline *compareSide, miner *PatternMiner) compareLogsResult {
+	result := compareLogsResult{
+		Current:     *current,
+		Baseline:    *baseline,
+		Severities:  []severityDiff{},
+		ErrorGroups: []errorGroupDiff{},
+		NewPatterns: []LogPattern{},
+	}
+
+	for _, severity := range logSeverities {
+		c, b := current.severities[severity], baseline.severities[severity]
+		if c == 0 && b == 0 {
+			continue
+		}
+		z := proportionZScore(c, current.Entries, b, baseline.Entries)
+		result.Severities = append(result.Severities, severityDiff{
+			Severity:      severity,
+			Current:       c,
+			Baseline:      b,
+			CurrentShare:  share(c, current.Entries),
+			BaselineShare: share(b, baseline.Entries),
+			ZScore:        z,
+			Significant:   isSignificant(z),
+		})
+	}
+
+	groups := make(map[string]*errorGroupDiff)
+	for _, group := range current.errors.Groups() {
+		groups[group.Fingerprint] = &errorGroupDiff{
+			Fingerprint: group.Fingerprint,
+			Message:     group.Message,
+			Current:     group.Count,
+			Services:    group.Services,
+		}
+	}
+	for _, group := range baseline.errors.Groups() {
+		diff, exists := groups[group.Fingerprint]
+		if !exists {
+			diff = &errorGroupDiff{
+				Fingerprint: group.Fingerprint,
+				Message:     group.Message,
+				Services:    group.Services,
+			}
+			groups[group.Fingerprint] = diff
+		}
+		diff.Baseline = group.Count
+	}
+	for _, diff := range groups {
+		switch {
+		case diff.Baseline == 0:
+			diff.Status = patternNew
+		case diff.Current == 0:
+			diff.Status = patternGone
+		}
+		diff.ZScore = proportionZScore(diff.Current, current.Entries, diff.Baseline, baseline.Entries)
+		diff.Significant = isSignificant(diff.ZScore)
+		result.ErrorGroups = append(result.ErrorGroups, *diff)
+	}
+	// Most significant increases first
+	sort.Slice(result.ErrorGroups, func(i, j int) bool {
+		a, b := result.ErrorGroups[i], result.ErrorGroups[j]
+		if a.ZScore != b.ZScore {
+			return a.ZScore > b.ZScore
+		}
+		return a.Fingerprint < b.Fingerprint
+	})
+
+	for _, pattern := range miner.Patterns() {
+		if baseline.patterns[pattern.ID] > 0 || current.patterns[pattern.ID] == 0 {
+			continue
+		}
+		pattern.Count = current.patterns[pattern.ID]
+		result.NewPatterns = append(result.NewPatterns, pattern)
+	}
+	sort.SliceStable(result.NewPatterns, func(i, j int) bool {
+		return result.NewPatterns[i].Count > result.NewPatterns[j].Count
+	})
+
+	return result
+}
+
+func share(count, total int) float64 {
+	if total == 0 {
+		return 0
+	}
+	return round2(float64(count) / float64(total) * 100)
+}
@@ -0,0 +1,411 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	deployKindCloudRun   = "cloud_run"
+	deployKindGKE        = "gke"
+	deployKindCloudBuild = "cloud_build"
+
+	defaultDeployImpactSpan = 30 * time.Minute
+	defaultDeployLimit      = 50
+	maxDeployLimit          = 200
+	// Audit entries read per call; deploys are rare next to other admin activity
+	maxDeployEvents = 1000
+	// Services whose errors are read per call, most recently deployed first
+	maxCorrelatedServices = 10
+	maxDeployErrorEntries = 5000
+)
+
+// podNameSuffixes matches what each workload controller appends to its name
+// when naming pods, so that a workload is not confused with another one whose
+// name it prefixes: Deployments add the ReplicaSet's template hash and a
+// random suffix, StatefulSets an ordinal and DaemonSets a random suffix
+var podNameSuffixes = map[string]string{
+	"deployments":  `-[a-z0-9]+-[a-z0-9]{5}$`,
+	"statefulsets": `-[0-9]+$`,
+	"daemonsets":   `-[a-z0-9]{5}$`,
+}
+
+// deployMethodFilter selects the audit entries of Cloud Run service updates,
+// Kubernetes workload rollouts and Cloud Build builds. Dots are matched with
+// [.] to avoid escaping backslashes in the query string.
+const deployMethodFilter = `protoPayload.methodName=~"(Services[.](ReplaceService|CreateService|UpdateService)|CreateRevision|CloudBuild[.](CreateBuild|RunBuildTrigger)|^io[.]k8s[.]apps[.]v1[.](deployments|statefulsets|daemonsets)[.](create|update|patch)$)"`
+
+type CorrelateDeploysTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type CorrelateDeploysArgs struct {
+	Service    string `json:"service,omitempty"`
+	StartTime  string `json:"startTime,omitempty"`
+	EndTime    string `json:"endTime,omitempty"`
+	ImpactSpan string `json:"impactSpan,omitempty"`
+	Limit      int    `json:"limit,omitempty"`
+}
+
+// deployEvent is one deploy read from the audit logs. Service and errorFilter
+// are empty when the affected workload cannot be told, e.g. for a build
+// that does not deploy to Cloud Run.
+type deployEvent struct {
+	Time      string        `json:"time"`
+	Kind      string        `json:"kind"`
+	Method    string        `json:"method"`
+	Service   string        `json:"service,omitempty"`
+	Location  string        `json:"location,omitempty"`
+	Resource  string        `json:"resource"`
+	Principal string        `json:"principal,omitempty"`
+	Failed    string        `json:"failed,omitempty"`
+	Errors    *DeployImpact `json:"errors,omitempty"`
+	LogsError string        `json:"logsError,omitempty"`
+
+	at          time.Time
+	errorFilter string
+}
+
+type correlateDeploysResult struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	ImpactSpan string    `json:"impactSpan"`
+	Count      int       `json:"count"`
+	Truncated  bool      `json:"truncated,omitempty"`
+	// Services beyond maxCorrelatedServices are listed without error counts
+	ServicesSkipped int `json:"servicesSkipped,omitempty"`
+	// Verdict answers whether the most recent measured deploy raised errors
+	Verdict  string        `json:"verdict"`
+	Suspects []deployEvent `json:"suspects"`
+	Deploys  []deployEvent `json:"deploys"`
+}
+
+func NewCorrelateDeploysTool(client *Client) *CorrelateDeploysTool {
+	return &CorrelateDeploysTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *CorrelateDeploysTool) Name() string {
+	return "correlate_deploys"
+}
+
+func (t *CorrelateDeploysTool) Description() string {
+	return "Find deploys in a window (default the last 24h) from the Admin Activity audit logs: Cloud Run service updates (ReplaceService, CreateService, UpdateService), GKE Deployment/StatefulSet/DaemonSet rollouts and Cloud Build builds. Each deploy is laid over the ERROR count of the affected service in impactSpan (default 30m) before and after it; suspects lists deploys followed by a significant error increase and verdict says whether the last deploy broke anything. service matches affected service names case-insensitively."
+}
+
+func (t *CorrelateDeploysTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"service": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"impactSpan": {
+				Type: "string",
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *CorrelateDeploysTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params CorrelateDeploysArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, 24*time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	span := defaultDeployImpactSpan
+	if params.ImpactSpan != "" {
+		span, err = time.ParseDuration(params.ImpactSpan)
+		if err != nil || span < time.Minute {
+			return ErrorResult(fmt.Sprintf("Error: impactSpan %q must be a duration of at least 1m", params.ImpactSpan)), nil
+		}
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultDeployLimit
+	}
+	if params.Limit > maxDeployLimit {
+		params.Limit = maxDeployLimit
+	}
+
+	filter := window.Filter(NewFilterBuilder().
+		AddFilter(fmt.Sprintf(`logName:"%s"`, auditActivityLog)).
+		AddFilter(deployMethodFilter).
+		Build())
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":       t.Name(),
+		"filter":     NormalizeFilter(filter, t.cache.TimeBucket()),
+		"service":    strings.ToLower(params.Service),
+		"impactSpan": span.String(),
+		"limit":      params.Limit,
+	})
+
+	var result correlateDeploysResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for deploy correlation")
+	} else {
+		var events []deployEvent
+		var truncated bool
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			events, truncated, err = t.readDeploys(ctx, filter, params.Service)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to read deploys: %v", err)
+			return ErrorResult(fmt.Sprintf("Error reading deploys: %v", err)), nil
+		}
+		if len(events) > params.Limit {
+			events = events[:params.Limit]
+			truncated = true
+		}
+
+		now := time.Now().UTC()
+		result = correlateDeploysResult{
+			Start:      window.Start,
+			End:        window.End,
+			ImpactSpan: span.String(),
+			Truncated:  truncated,
+			Suspects:   []deployEvent{},
+			Deploys:    events,
+		}
+		result.ServicesSkipped = t.measureImpact(ctx, events, span, now)
+		summarizeDeploys(&result)
+
+		// Errors after the window still count, so it is only final once the span has passed too
+		if window.End.Add(span).Before(now) {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal deploy correlation: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// readDeploys returns the deploys matching the filter, newest first
+func (t *CorrelateDeploysTool) readDeploys(ctx context.Context, filter, service string) ([]deployEvent, bool, error) {
+	service = strings.ToLower(service)
+	var events []deployEvent
+	_, truncated, err := scanEntries(ctx, t.client, filter, maxDeployEvents, func(entry *logging.Entry) {
+		// Long-running operations log their start and end; the start is the deploy
+		if entry.Operation != nil && entry.Operation.Last && !entry.Operation.First {
+			return
+		}
+		payload, ok := auditLog(entry)
+		if !ok {
+			return
+		}
+		event, ok := newDeployEvent(entry, payload)
+		if !ok || (service != "" && !strings.Contains(strings.ToLower(event.Service), service)) {
+			return
+		}
+		events = append(events, event)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].at.After(events[j].at)
+	})
+	return events, truncated, nil
+}
+
+// measureImpact reads the errors of each affected service once, covering all
+// of its deploys, and fills in their impact. It returns how many services
+// were skipped for exceeding maxCorrelatedServices.
+func (t *CorrelateDeploysTool) measureImpact(ctx context.Context, events []deployEvent, span time.Duration, now time.Time) int {
+	// Events are newest first, so services are ordered by their latest deploy
+	var filters []string
+	byFilter := make(map[string][]int)
+	for i, event := range events {
+		if event.errorFilter == "" || event.Failed != "" {
+			continue
+		}
+		if _, ok := byFilter[event.errorFilter]; !ok {
+			filters = append(filters, event.errorFilter)
+		}
+		byFilter[event.errorFilter] = append(byFilter[event.errorFilter], i)
+	}
+
+	skipped := 0
+	for n, filter := range filters {
+		if n >= maxCorrelatedServices {
+			skipped++
+			continue
+		}
+		indexes := byFilter[filter]
+		oldest := events[indexes[len(indexes)-1]].at
+		newest := events[indexes[0]].at
+		window := TimeWindow{Start: oldest.Add(-span), End: newest.Add(span)}
+		if window.End.After(now) {
+			window.End = now
+		}
+
+		var errors []RevisionEntry
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			errors, _, err = t.client.RevisionEntries(ctx, filter, window, maxDeployErrorEntries)
+			return err
+		})
+		for _, i := range indexes {
+			if err != nil {
+				events[i].LogsError = err.Error()
+				continue
+			}
+			impact := MeasureDeployImpact(errors, events[i].at, span, "", now)
+			events[i].Errors = &impact
+		}
+		if err != nil {
+			log.Printf("Failed to read errors for %s: %v", events[indexes[0]].Service, err)
+		}
+	}
+	return skipped
+}
+
+func summarizeDeploys(result *correlateDeploysResult) {
+	result.Count = len(result.Deploys)
+	var last *deployEvent
+	for i := range result.Deploys {
+		event := result.Deploys[i]
+		if event.Errors == nil {
+			continue
+		}
+		if last == nil {
+			last = &result.Deploys[i]
+		}
+		if event.Errors.Significant {
+			result.Suspects = append(result.Suspects, event)
+		}
+	}
+
+	switch {
+	case result.Count == 0:
+		result.Verdict = "no deploys found in the window"
+	case last == nil:
+		result.Verdict = "no deploy could be laid over the errors of an affected service"
+	case last.Errors.Significant:
+		result.Verdict = fmt.Sprintf("errors rose significantly after the last deploy: %s of %s at %s (%d errors in the span before, %d after, zScore %.2f)",
+			last.Method, last.Service, last.Time, last.Errors.ErrorsBefore, last.Errors.ErrorsAfter, last.Errors.ZScore)
+	default:
+		result.Verdict = fmt.Sprintf("no significant error increase after the last deploy: %s of %s at %s", last.Method, last.Service, last.Time)
+	}
+}
+
+// newDeployEvent classifies an audit entry and works out which service it
+// rolled out and how to select that service's error entries
+func newDeployEvent(entry *logging.Entry, payload *audit.AuditLog) (deployEvent, bool) {
+	event := deployEvent{
+		Time:     entry.Timestamp.UTC().Format(time.RFC3339),
+		Method:   payload.MethodName,
+		Resource: payload.ResourceName,
+		at:       entry.Timestamp,
+	}
+	if info := payload.AuthenticationInfo; info != nil {
+		event.Principal = info.PrincipalEmail
+	}
+	if status := payload.Status; status != nil && status.Code != 0 {
+		event.Failed = status.Message
+		if event.Failed == "" {
+			event.Failed = fmt.Sprintf("code %d", status.Code)
+		}
+	}
+	var labels map[string]string
+	if entry.Resource != nil {
+		labels = entry.Resource.Labels
+	}
+
+	switch {
+	case strings.HasPrefix(payload.MethodName, "io.k8s."):
+		// apps/v1/namespaces/<namespace>/deployments/<name>
+		parts := strings.Split(payload.ResourceName, "/")
+		if len(parts) < 6 || parts[2] != "namespaces" {
+			return deployEvent{}, false
+		}
+		namespace, name := parts[3], parts[5]
+		suffix, ok := podNameSuffixes[parts[4]]
+		if !ok {
+			return deployEvent{}, false
+		}
+		event.Kind = deployKindGKE
+		event.Service = namespace + "/" + name
+		event.Location = labels["location"]
+		podName := "^" + regexp.QuoteMeta(name) + suffix
+		event.errorFilter = fmt.Sprintf(`resource.type="k8s_container" AND resource.labels.cluster_name="%s" AND resource.labels.namespace_name="%s" AND resource.labels.pod_name=~"%s" AND severity>=ERROR`,
+			EscapeFilterValue(labels["cluster_name"]), EscapeFilterValue(namespace), EscapeFilterValue(podName))
+	case strings.Contains(payload.MethodName, "CloudBuild"):
+		event.Kind = deployKindCloudBuild
+		// Cloud Run continuous deployment triggers name the service they deploy
+		substitutions := payload.Request.GetFields()["build"].GetStructValue().GetFields()["substitutions"].GetStructValue().GetFields()
+		event.Service = substitutions["_SERVICE_NAME"].GetStringValue()
+		event.Location = substitutions["_DEPLOY_REGION"].GetStringValue()
+		if event.Service != "" {
+			event.errorFilter = cloudRunErrorFilter(event.Service, event.Location)
+		}
+	default:
+		event.Kind = deployKindCloudRun
+		event.Service = labels["service_name"]
+		if event.Service == "" {
+			// namespaces/<project>/services/<name> or projects/<project>/locations/<region>/services/<name>
+			if i := strings.LastIndex(payload.ResourceName, "/services/"); i >= 0 {
+				event.Service = strings.SplitN(payload.ResourceName[i+len("/services/"):], "/", 2)[0]
+			}
+		}
+		event.Location = labels["location"]
+		if event.Service == "" {
+			return deployEvent{}, false
+		}
+		event.errorFilter = cloudRunErrorFilter(event.Service, event.Location)
+	}
+	return event, true
+}
+
+func cloudRunErrorFilter(service, location string) string {
+	filter := fmt.Sprintf(`resource.type="cloud_run_revision" AND resource.labels.service_name="%s"`, EscapeFilterValue(service))
+	if location != "" {
+		filter += fmt.Sprintf(` AND resource.labels.location="%s"`, EscapeFilterValue(location))
+	}
+	return filter + " AND severity>=ERROR"
+}
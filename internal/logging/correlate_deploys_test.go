@@ -0,0 +1,83 @@
+package logging
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/logging"
+	"google.golang.org/genproto/googleapis/api/monitoredres"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+)
+
+func TestDeployEventPodNames(t *testing.T) {
+	tests := []struct {
+		resource string
+		match    []string
+		noMatch  []string
+	}{
+		{
+			resource: "apps/v1/namespaces/shop/deployments/api",
+			match:    []string{"api-7d9f8b6c5-x2k9p", "api-5c8d7-abcde"},
+			noMatch:  []string{"api-gateway-7d9f8b6c5-x2k9p", "api-0", "api-x2k9p", "xapi-7d9f8b6c5-x2k9p"},
+		},
+		{
+			resource: "apps/v1/namespaces/shop/deployments/api.v2",
+			match:    []string{"api.v2-7d9f8b6c5-x2k9p"},
+			noMatch:  []string{"apixv2-7d9f8b6c5-x2k9p"},
+		},
+		{
+			resource: "apps/v1/namespaces/shop/statefulsets/db",
+			match:    []string{"db-0", "db-12"},
+			noMatch:  []string{"db-replica-0", "db-7d9f8b6c5-x2k9p"},
+		},
+		{
+			resource: "apps/v1/namespaces/shop/daemonsets/agent",
+			match:    []string{"agent-x2k9p"},
+			noMatch:  []string{"agent-proxy-x2k9p", "agent-0"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.resource, func(t *testing.T) {
+			entry := &logging.Entry{
+				Timestamp: time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC),
+				Resource:  &monitoredres.MonitoredResource{Labels: map[string]string{"cluster_name": "prod", "location": "asia-northeast1"}},
+			}
+			event, ok := newDeployEvent(entry, &audit.AuditLog{MethodName: "io.k8s.apps.v1.deployments.patch", ResourceName: tt.resource})
+			if !ok {
+				t.Fatal("deploy was not recognized")
+			}
+			if event.Kind != deployKindGKE || !strings.HasPrefix(event.errorFilter, `resource.type="k8s_container" AND resource.labels.cluster_name="prod" AND resource.labels.namespace_name="shop"`) {
+				t.Fatalf("unexpected event %+v", event)
+			}
+
+			pattern := podNamePattern(t, event.errorFilter)
+			for _, pod := range tt.match {
+				if !pattern.MatchString(pod) {
+					t.Errorf("%s does not match pod %s", pattern, pod)
+				}
+			}
+			for _, pod := range tt.noMatch {
+				if pattern.MatchString(pod) {
+					t.Errorf("%s matches pod %s", pattern, pod)
+				}
+			}
+		})
+	}
+}
+
+// podNamePattern extracts the pod name regular expression of an error filter
+// the way Cloud Logging reads the quoted string
+func podNamePattern(t *testing.T, filter string) *regexp.Regexp {
+	t.Helper()
+	const prefix = `resource.labels.pod_name=~"`
+	i := strings.Index(filter, prefix)
+	if i < 0 {
+		t.Fatalf("filter has no pod name pattern: %s", filter)
+	}
+	quoted := filter[i+len(prefix):]
+	quoted = quoted[:strings.Index(quoted, `" AND`)]
+	return regexp.MustCompile(strings.NewReplacer(`\\`, `\`, `\"`, `"`).Replace(quoted))
+}
@@ -0,0 +1,80 @@
+package logging
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+// RevisionEntry is the time and Cloud Run revision of a log entry
+type RevisionEntry struct {
+	Timestamp time.Time
+	Revision  string
+}
+
+// DeployImpact compares the rate of error entries in equal spans before and
+// after a deploy. The after span is cut short when it reaches the present.
+type DeployImpact struct {
+	ErrorsBefore int     `json:"errorsBefore"`
+	ErrorsAfter  int     `json:"errorsAfter"`
+	ZScore       float64 `json:"zScore"`
+	// Set when the after rate is significantly higher
+	Significant bool `json:"significant"`
+	// First error of the deployed revision and how long after the deploy it came
+	FirstError string `json:"firstError,omitempty"`
+	OnsetDelay string `json:"onsetDelay,omitempty"`
+}
+
+// RevisionEntries returns the time and revision of every entry matching the
+// filter within the window, oldest first, for laying logs over deploys
+func (c *Client) RevisionEntries(ctx context.Context, filter string, window TimeWindow, limit int) ([]RevisionEntry, bool, error) {
+	var entries []RevisionEntry
+	_, truncated, err := scanEntries(ctx, c, window.Filter(filter), scanLimit(limit), func(entry *logging.Entry) {
+		e := RevisionEntry{Timestamp: entry.Timestamp}
+		if entry.Resource != nil {
+			e.Revision = entry.Resource.Labels["revision_name"]
+		}
+		entries = append(entries, e)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	return entries, truncated, nil
+}
+
+// MeasureDeployImpact counts errors within span on either side of deployedAt.
+// errors must be sorted oldest first; revision, if set, selects the entries
+// whose first occurrence marks the onset.
+func MeasureDeployImpact(errors []RevisionEntry, deployedAt time.Time, span time.Duration, revision string, now time.Time) DeployImpact {
+	var impact DeployImpact
+	end := deployedAt.Add(span)
+	if end.After(now) {
+		end = now
+	}
+
+	for _, e := range errors {
+		switch {
+		case e.Timestamp.Before(deployedAt.Add(-span)):
+			continue
+		case e.Timestamp.Before(deployedAt):
+			impact.ErrorsBefore++
+		case e.Timestamp.Before(end):
+			impact.ErrorsAfter++
+		}
+		if impact.FirstError == "" && !e.Timestamp.Before(deployedAt) && (revision == "" || e.Revision == revision) {
+			impact.FirstError = e.Timestamp.Format(time.RFC3339)
+			impact.OnsetDelay = e.Timestamp.Sub(deployedAt).Truncate(time.Second).String()
+		}
+	}
+
+	after := end.Sub(deployedAt).Seconds()
+	impact.ZScore = rateZScore(impact.ErrorsAfter, after, impact.ErrorsBefore, span.Seconds())
+	impact.Significant = impact.ZScore >= significanceZScore
+	return impact
+}
@@ -0,0 +1,236 @@
+package logging
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io/fs"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Entries for a window that ended less than this long ago may still receive
+// late-arriving logs, so they are not treated as closed yet.
+const closedWindowGracePeriod = 5 * time.Minute
+
+// DefaultDiskCacheMaxBytes is used when no explicit size limit is configured.
+const DefaultDiskCacheMaxBytes int64 = 256 << 20
+
+type diskCacheRecord struct {
+	Key       string          `json:"key"`
+	CreatedAt time.Time       `json:"createdAt"`
+	Data      json.RawMessage `json:"data"`
+}
+
+// DiskCache persists results of queries over closed historical windows.
+// Files are addressed by the SHA-256 of the cache key and stored under
+// <dir>/<first two hex chars>/<hash>.json.
+type DiskCache struct {
+	mu       sync.Mutex
+	dir      string
+	maxBytes int64
+	size     int64
+}
+
+func NewDiskCache(dir string, maxBytes int64) (*DiskCache, error) {
+	if dir == "" {
+		return nil, fmt.Errorf("disk cache directory is required")
+	}
+	if maxBytes <= 0 {
+		maxBytes = DefaultDiskCacheMaxBytes
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("failed to create disk cache directory: %w", err)
+	}
+
+	d := &DiskCache{
+		dir:      dir,
+		maxBytes: maxBytes,
+	}
+	if err := d.Compact(); err != nil {
+		return nil, err
+	}
+	return d, nil
+}
+
+func (d *DiskCache) Get(key string) (json.RawMessage, bool) {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.path(key)
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, false
+	}
+
+	var record diskCacheRecord
+	if err := json.Unmarshal(data, &record); err != nil || record.Key != key {
+		// Corrupted or colliding file; drop it so compaction does not count it
+		d.remove(path, int64(len(data)))
+		return nil, false
+	}
+
+	// Touch the file so compaction evicts least recently used entries first
+	now := time.Now()
+	_ = os.Chtimes(path, now, now)
+
+	return record.Data, true
+}
+
+func (d *DiskCache) Set(key string, value json.RawMessage) error {
+	data, err := json.Marshal(diskCacheRecord{
+		Key:       key,
+		CreatedAt: time.Now(),
+		Data:      value,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to marshal disk cache record: %w", err)
+	}
+	if int64(len(data)) > d.maxBytes {
+		return fmt.Errorf("disk cache record of %d bytes exceeds limit of %d bytes", len(data), d.maxBytes)
+	}
+
+	d.mu.Lock()
+	defer d.mu.Unlock()
+
+	path := d.path(key)
+	if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+		return fmt.Errorf("failed to create disk cache shard: %w", err)
+	}
+
+	var previous int64
+	if info, err := os.Stat(path); err == nil {
+		previous = info.Size()
+	}
+
+	// Write to a temporary file first so readers never observe partial records
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".tmp-*")
+	if err != nil {
+		return fmt.Errorf("failed to create disk cache file: %w", err)
+	}
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to write disk cache file: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to close disk cache file: %w", err)
+	}
+	if err := os.Rename(tmp.Name(), path); err != nil {
+		os.Remove(tmp.Name())
+		return fmt.Errorf("failed to store disk cache file: %w", err)
+	}
+
+	d.size += int64(len(data)) - previous
+	if d.size > d.maxBytes {
+		return d.compactLocked()
+	}
+	return nil
+}
+
+// Compact removes temporary and unreadable files and evicts the least
+// recently used records until the cache fits within its size limit.
+func (d *DiskCache) Compact() error {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return d.compactLocked()
+}
+
+func (d *DiskCache) compactLocked() error {
+	type cacheFile struct {
+		path    string
+		size    int64
+		modTime time.Time
+	}
+
+	var files []cacheFile
+	var total int64
+
+	err := filepath.WalkDir(d.dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if entry.IsDir() {
+			return nil
+		}
+		info, err := entry.Info()
+		if err != nil {
+			return nil
+		}
+
+		// Leftovers from interrupted writes
+		if strings.HasPrefix(entry.Name(), ".tmp-") {
+			os.Remove(path)
+			return nil
+		}
+		if filepath.Ext(path) != ".json" {
+			return nil
+		}
+
+		files = append(files, cacheFile{
+			path:    path,
+			size:    info.Size(),
+			modTime: info.ModTime(),
+		})
+		total += info.Size()
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("failed to scan disk cache: %w", err)
+	}
+
+	// Evict down to 90% of the limit so that compaction does not run on every write
+	target := d.maxBytes - d.maxBytes/10
+	if total > d.maxBytes {
+		sort.Slice(files, func(i, j int) bool {
+			return files[i].modTime.Before(files[j].modTime)
+		})
+		evicted := 0
+		for _, f := range files {
+			if total <= target {
+				break
+			}
+			if err := os.Remove(f.path); err != nil && !os.IsNotExist(err) {
+				continue
+			}
+			total -= f.size
+			evicted++
+		}
+		log.Printf("Disk cache compacted: evicted %d entries, %d bytes remaining", evicted, total)
+	}
+
+	d.size = total
+	return nil
+}
+
+func (d *DiskCache) path(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	name := hex.EncodeToString(sum[:])
+	return filepath.Join(d.dir, name[:2], name+".json")
+}
+
+func (d *DiskCache) remove(path string, size int64) {
+	if err := os.Remove(path); err == nil {
+		d.size -= size
+	}
+}
+
+// IsClosedWindow reports whether an RFC3339 end time is far enough in the past
+// that no more entries can arrive for the window it closes.
+func IsClosedWindow(endTime string) bool {
+	if endTime == "" {
+		return false
+	}
+	end, err := time.Parse(time.RFC3339, endTime)
+	if err != nil {
+		return false
+	}
+	return end.Before(time.Now().Add(-closedWindowGracePeriod))
+}
@@ -0,0 +1,235 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const (
+	// Key that replaces the members of an object cut off by maxPayloadBytes
+	omittedKey = "_omitted"
+	// Values are only cut, rather than dropped, when at least this much budget is left
+	minTruncateBytes = 32
+)
+
+// Top-level fields that maxPayloadBytes applies to: entry payloads, and the
+// request and response bodies of flattened audit records
+var payloadFields = map[string]bool{
+	"textPayload":  true,
+	"jsonPayload":  true,
+	"protoPayload": true,
+	"request":      true,
+	"response":     true,
+	"metadata":     true,
+}
+
+// EntryView controls how much of each returned entry is shown. Fields
+// projects entries onto dotted paths such as jsonPayload.message, and
+// MaxPayloadBytes cuts payloads down to a budget. Compact drops indentation.
+type EntryView struct {
+	Fields          []string
+	MaxPayloadBytes int
+	Compact         bool
+}
+
+// NewEntryView parses the fields, maxPayloadBytes and compact tool arguments.
+// fields is a comma separated list of paths.
+func NewEntryView(fields string, maxPayloadBytes int, compact bool) EntryView {
+	view := EntryView{MaxPayloadBytes: maxPayloadBytes, Compact: compact}
+	for _, field := range strings.Split(fields, ",") {
+		if field = strings.TrimSpace(field); field != "" {
+			view.Fields = append(view.Fields, field)
+		}
+	}
+	return view
+}
+
+// Apply returns the entry reduced by the view. Entries are returned as is
+// when neither a projection nor a payload budget is set.
+func (v EntryView) Apply(entry interface{}) interface{} {
+	if len(v.Fields) == 0 && v.MaxPayloadBytes <= 0 {
+		return entry
+	}
+
+	var object map[string]interface{}
+	if data, err := json.Marshal(entry); err != nil || json.Unmarshal(data, &object) != nil {
+		return entry
+	}
+	if len(v.Fields) > 0 {
+		object = project(object, v.Fields)
+	}
+	if v.MaxPayloadBytes > 0 {
+		for key, value := range object {
+			if payloadFields[key] {
+				object[key] = truncateValue(value, v.MaxPayloadBytes)
+			}
+		}
+	}
+	return object
+}
+
+// ApplyAll applies the view to each entry of a slice
+func ApplyAll[T any](v EntryView, entries []T) []interface{} {
+	viewed := make([]interface{}, len(entries))
+	for i, entry := range entries {
+		viewed[i] = v.Apply(entry)
+	}
+	return viewed
+}
+
+// Marshal encodes a tool result, indented unless the view is compact
+func (v EntryView) Marshal(result interface{}) ([]byte, error) {
+	if v.Compact {
+		return json.Marshal(result)
+	}
+	return json.MarshalIndent(result, "", "  ")
+}
+
+// project keeps only the given dotted paths, preserving their nesting. The
+// timestamp is always kept so projected entries can still be ordered.
+func project(object map[string]interface{}, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{})
+	if ts, ok := object["timestamp"]; ok {
+		projected["timestamp"] = ts
+	}
+	for _, field := range fields {
+		path := strings.Split(field, ".")
+		var value interface{} = object
+		found := true
+		for _, key := range path {
+			m, ok := value.(map[string]interface{})
+			if !ok {
+				found = false
+				break
+			}
+			if value, ok = m[key]; !ok {
+				found = false
+				break
+			}
+		}
+		if !found {
+			continue
+		}
+
+		target := projected
+		for _, key := range path[:len(path)-1] {
+			next, ok := target[key].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				target[key] = next
+			}
+			target = next
+		}
+		target[path[len(path)-1]] = value
+	}
+	return projected
+}
+
+// truncateValue cuts a decoded JSON value down to about budget bytes of
+// encoded JSON. Object members are kept smallest first, ties by name, so the
+// result only depends on the value; the member that no longer fits is cut
+// recursively and the rest are listed under omittedKey. Arrays keep their
+// leading items and strings their prefix, each followed by a marker.
+func truncateValue(value interface{}, budget int) interface{} {
+	size := jsonSize(value)
+	if size <= budget {
+		return value
+	}
+
+	switch v := value.(type) {
+	case string:
+		// Leave room for the quotes
+		keep := min(budget-2, len(v))
+		if keep < 0 {
+			keep = 0
+		}
+		if keep == len(v) {
+			return v
+		}
+		for keep > 0 && !utf8.RuneStart(v[keep]) {
+			keep--
+		}
+		return fmt.Sprintf("%s...[%d more bytes]", v[:keep], len(v)-keep)
+
+	case map[string]interface{}:
+		keys := make([]string, 0, len(v))
+		sizes := make(map[string]int, len(v))
+		for key, member := range v {
+			keys = append(keys, key)
+			// Account for the quoted key, colon and comma
+			sizes[key] = jsonSize(member) + len(key) + 4
+		}
+		sort.Slice(keys, func(i, j int) bool {
+			if sizes[keys[i]] != sizes[keys[j]] {
+				return sizes[keys[i]] < sizes[keys[j]]
+			}
+			return keys[i] < keys[j]
+		})
+
+		result := make(map[string]interface{})
+		remaining := budget
+		var omitted []string
+		var omittedBytes int
+		for _, key := range keys {
+			switch {
+			case sizes[key] <= remaining:
+				result[key] = v[key]
+				remaining -= sizes[key]
+			case remaining-len(key)-4 >= minTruncateBytes && isTruncatable(v[key]):
+				result[key] = truncateValue(v[key], remaining-len(key)-4)
+				remaining = 0
+			default:
+				omitted = append(omitted, key)
+				omittedBytes += sizes[key]
+			}
+		}
+		if len(omitted) > 0 {
+			sort.Strings(omitted)
+			result[omittedKey] = map[string]interface{}{
+				"keys":  omitted,
+				"bytes": omittedBytes,
+			}
+		}
+		return result
+
+	case []interface{}:
+		var result []interface{}
+		remaining := budget
+		for i, item := range v {
+			itemSize := jsonSize(item) + 1
+			if itemSize > remaining {
+				if remaining-1 >= minTruncateBytes && isTruncatable(item) {
+					result = append(result, truncateValue(item, remaining-1))
+					i++
+				}
+				if rest := len(v) - i; rest > 0 {
+					result = append(result, fmt.Sprintf("...[%d more items]", rest))
+				}
+				return result
+			}
+			result = append(result, item)
+			remaining -= itemSize
+		}
+		return result
+	}
+	return value
+}
+
+func isTruncatable(value interface{}) bool {
+	switch value.(type) {
+	case string, map[string]interface{}, []interface{}:
+		return true
+	}
+	return false
+}
+
+func jsonSize(value interface{}) int {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return 0
+	}
+	return len(data)
+}
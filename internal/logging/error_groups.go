@@ -0,0 +1,194 @@
+package logging
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// Stack frames that contribute to a fingerprint; deeper frames are mostly framework noise
+	fingerprintFrames  = 5
+	maxExampleLength   = 500
+	maxNormalizedChars = 300
+)
+
+// Replacements applied in order; more specific patterns must come first
+var messageNormalizers = []struct {
+	pattern     *regexp.Regexp
+	replacement string
+}{
+	{regexp.MustCompile(`[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}`), "<uuid>"},
+	{regexp.MustCompile(`\d{4}-\d{2}-\d{2}[T ]\d{2}:\d{2}:\d{2}(\.\d+)?(Z|[+-]\d{2}:?\d{2})?`), "<ts>"},
+	{regexp.MustCompile(`[\w.+-]+@[\w-]+\.[\w.-]+`), "<email>"},
+	{regexp.MustCompile(`\b\d{1,3}\.\d{1,3}\.\d{1,3}\.\d{1,3}(:\d+)?\b`), "<ip>"},
+	{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), "<hex>"},
+	{regexp.MustCompile(`\b[0-9a-fA-F]{16,}\b`), "<hex>"},
+	{regexp.MustCompile(`\b[A-Za-z_-]*\d[A-Za-z0-9_-]{5,}\b|\b[A-Za-z_-]{5,}\d[A-Za-z0-9_-]*\b`), "<id>"},
+	{regexp.MustCompile(`\b\d+(\.\d+)?\b`), "<num>"},
+	{regexp.MustCompile(`\s+`), " "},
+}
+
+var stackFramePatterns = []*regexp.Regexp{
+	// Java / Kotlin: "\tat com.example.Foo.bar(Foo.java:42)"
+	regexp.MustCompile(`^\s*at ([\w$.<>]+)\(`),
+	// Node.js: "    at handler (/app/index.js:10:5)" or "    at /app/index.js:10:5"
+	regexp.MustCompile(`^\s*at (?:async )?([^\s(]+)(?: \(|:\d)`),
+	// Python: '  File "/app/main.py", line 10, in handler'
+	regexp.MustCompile(`^\s*File "([^"]+)", line \d+, in (\S+)`),
+	// Go: "main.handler(0xc000010000)" followed by "\t/app/main.go:42 +0x1d"
+	regexp.MustCompile(`^([\w./*()-]+\.[\w*()-]+)\(.*\)$`),
+}
+
+// NormalizeMessage removes variable parts such as IDs, numbers, UUIDs,
+// timestamps and addresses so that messages of the same kind compare equal
+func NormalizeMessage(message string) string {
+	normalized := message
+	for _, n := range messageNormalizers {
+		normalized = n.pattern.ReplaceAllString(normalized, n.replacement)
+	}
+	normalized = strings.TrimSpace(normalized)
+	if len(normalized) > maxNormalizedChars {
+		normalized = strings.ToValidUTF8(normalized[:maxNormalizedChars], "")
+	}
+	return normalized
+}
+
+// ErrorFingerprint identifies the kind of error a message represents. Messages
+// with a stack trace are identified by their first line and top frames, so
+// line numbers and variable values do not split a group.
+func ErrorFingerprint(message string) (string, string) {
+	lines := strings.Split(strings.TrimSpace(message), "\n")
+	headline := NormalizeMessage(lines[0])
+
+	frames := stackFrames(lines[1:])
+	key := headline
+	if len(frames) > 0 {
+		key = headline + "\n" + strings.Join(frames, "\n")
+	}
+
+	sum := sha1.Sum([]byte(key))
+	return hex.EncodeToString(sum[:])[:12], headline
+}
+
+func stackFrames(lines []string) []string {
+	var frames []string
+	for _, line := range lines {
+		for _, pattern := range stackFramePatterns {
+			matches := pattern.FindStringSubmatch(line)
+			if matches == nil {
+				continue
+			}
+			frames = append(frames, strings.Join(matches[1:], ":"))
+			break
+		}
+		if len(frames) == fingerprintFrames {
+			break
+		}
+	}
+	return frames
+}
+
+// errorMessage returns the message of an entry including any stack trace
+// reported in a separate payload field
+func errorMessage(info *ServiceInfo) string {
+	message := info.Message
+	if info.JSONPayload == nil {
+		return message
+	}
+	for _, field := range []string{"stack_trace", "stackTrace", "exception", "stack"} {
+		if stack, ok := info.JSONPayload[field].(string); ok && stack != "" && !strings.Contains(message, stack) {
+			return message + "\n" + stack
+		}
+	}
+	return message
+}
+
+type ErrorGroup struct {
+	Fingerprint     string    `json:"fingerprint"`
+	Message         string    `json:"message"`
+	Example         string    `json:"example"`
+	Count           int       `json:"count"`
+	FirstSeen       time.Time `json:"firstSeen"`
+	LastSeen        time.Time `json:"lastSeen"`
+	ExampleInsertID string    `json:"exampleInsertId,omitempty"`
+	Services        []string  `json:"services,omitempty"`
+	Severities      []string  `json:"severities,omitempty"`
+}
+
+// ErrorGrouper accumulates log entries into groups keyed by fingerprint
+type ErrorGrouper struct {
+	groups     map[string]*ErrorGroup
+	services   map[string]map[string]bool
+	severities map[string]map[string]bool
+}
+
+func NewErrorGrouper() *ErrorGrouper {
+	return &ErrorGrouper{
+		groups:     make(map[string]*ErrorGroup),
+		services:   make(map[string]map[string]bool),
+		severities: make(map[string]map[string]bool),
+	}
+}
+
+func (g *ErrorGrouper) Add(entry LogEntry) {
+	info := ExtractServiceInfoFromLogEntry(entry)
+	message := errorMessage(info)
+	fingerprint, headline := ErrorFingerprint(message)
+
+	group, exists := g.groups[fingerprint]
+	if !exists {
+		group = &ErrorGroup{
+			Fingerprint:     fingerprint,
+			Message:         headline,
+			Example:         Truncate(message, maxExampleLength),
+			FirstSeen:       info.Timestamp,
+			LastSeen:        info.Timestamp,
+			ExampleInsertID: entry.InsertID,
+		}
+		g.groups[fingerprint] = group
+		g.services[fingerprint] = make(map[string]bool)
+		g.severities[fingerprint] = make(map[string]bool)
+	}
+
+	group.Count++
+	if info.Timestamp.Before(group.FirstSeen) {
+		group.FirstSeen = info.Timestamp
+	}
+	if info.Timestamp.After(group.LastSeen) {
+		group.LastSeen = info.Timestamp
+	}
+	if info.ServiceName != "" {
+		g.services[fingerprint][info.ServiceName] = true
+	}
+	g.severities[fingerprint][info.Severity] = true
+}
+
+// Groups returns the groups ordered by count, most frequent first
+func (g *ErrorGrouper) Groups() []ErrorGroup {
+	groups := make([]ErrorGroup, 0, len(g.groups))
+	for fingerprint, group := range g.groups {
+		group.Services = sortedKeys(g.services[fingerprint])
+		group.Severities = sortedKeys(g.severities[fingerprint])
+		groups = append(groups, *group)
+	}
+	sort.Slice(groups, func(i, j int) bool {
+		if groups[i].Count != groups[j].Count {
+			return groups[i].Count > groups[j].Count
+		}
+		return groups[i].FirstSeen.Before(groups[j].FirstSeen)
+	})
+	return groups
+}
+
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
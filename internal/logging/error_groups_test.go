@@ -0,0 +1,29 @@
+package logging
+
+import (
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestErrorGroupsKeepValidUTF8(t *testing.T) {
+	// Three-byte characters do not line up with the cut-off points
+	message := "x" + strings.Repeat("決済に失敗しました", 40)
+
+	if normalized := NormalizeMessage(message); !utf8.ValidString(normalized) || len(normalized) > maxNormalizedChars {
+		t.Errorf("NormalizeMessage cut a character: %q", normalized)
+	}
+
+	grouper := NewErrorGrouper()
+	grouper.Add(LogEntry{Timestamp: "2024-05-01T10:00:00Z", Severity: "ERROR", TextPayload: message, InsertID: "a1"})
+	groups := grouper.Groups()
+	if len(groups) != 1 {
+		t.Fatalf("got %d groups, want 1", len(groups))
+	}
+	if example := groups[0].Example; !utf8.ValidString(example) || !strings.HasSuffix(example, "...") {
+		t.Errorf("example cut a character: %q", example)
+	}
+	if !utf8.ValidString(groups[0].Message) || !utf8.ValidString(groups[0].Fingerprint) {
+		t.Errorf("group has invalid text: %+v", groups[0])
+	}
+}
@@ -0,0 +1,438 @@
+package logging
+
+import (
+	"bufio"
+	"context"
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+
+	"cloud.google.com/go/logging/logadmin"
+	"github.com/parquet-go/parquet-go"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// Entries written between checkpoints; an interrupted export repeats at most this many
+	exportCheckpointEvery = scanPageSize
+	// Suffixes of the files an export keeps next to its output until it completes
+	exportSpoolSuffix      = ".partial"
+	exportCheckpointSuffix = ".checkpoint"
+)
+
+// Formats export_logs can write
+var exportFormats = []string{"ndjson", "csv", "parquet"}
+
+// Columns written to CSV and Parquet files when none are chosen
+var defaultExportColumns = []string{"timestamp", "severity", "logName", "resource.type", "insertId", "traceId", "message"}
+
+// ExportOptions describes an export. Filter is the complete Logging query,
+// including the time range; Key identifies the request so that rerunning it
+// resumes an interrupted export instead of starting over.
+type ExportOptions struct {
+	Filter  string
+	Key     string
+	Format  string
+	Columns []string
+	Output  string
+}
+
+// ExportProgress is reported after every checkpoint
+type ExportProgress struct {
+	Entries int
+	// Timestamp of the last entry written
+	Through string
+}
+
+type ExportResult struct {
+	Output     string         `json:"output"`
+	URI        string         `json:"uri,omitempty"`
+	Format     string         `json:"format"`
+	Filter     string         `json:"filter"`
+	Columns    []string       `json:"columns,omitempty"`
+	Entries    int            `json:"entries"`
+	Bytes      int64          `json:"bytes"`
+	Resumed    bool           `json:"resumed,omitempty"`
+	Redactions map[string]int `json:"redactions,omitempty"`
+}
+
+// exportCheckpoint records how far the spool file is complete
+type exportCheckpoint struct {
+	Key        string         `json:"key"`
+	Filter     string         `json:"filter"`
+	Entries    int            `json:"entries"`
+	Bytes      int64          `json:"bytes"`
+	Cursor     string         `json:"cursor,omitempty"`
+	Redactions map[string]int `json:"redactions,omitempty"`
+}
+
+// ParseExportOptions validates the format and the comma separated columns
+func ParseExportOptions(format, columns string) (string, []string, error) {
+	if format == "" {
+		format = "ndjson"
+	}
+	format = strings.ToLower(format)
+	if !slices.Contains(exportFormats, format) {
+		return "", nil, fmt.Errorf("format must be one of %s", strings.Join(exportFormats, ", "))
+	}
+	if format == "ndjson" {
+		return format, nil, nil
+	}
+
+	var cols []string
+	for _, column := range strings.Split(columns, ",") {
+		if column = strings.TrimSpace(column); column != "" {
+			cols = append(cols, column)
+		}
+	}
+	if len(cols) == 0 {
+		cols = defaultExportColumns
+	}
+	// Parquet column names cannot tell a.b from a_b
+	seen := make(map[string]bool, len(cols))
+	for _, column := range cols {
+		name := strings.ReplaceAll(column, ".", "_")
+		if seen[name] {
+			return "", nil, fmt.Errorf("duplicate column %q", column)
+		}
+		seen[name] = true
+	}
+	return format, cols, nil
+}
+
+// ExportEntries writes every entry matching the filter, oldest first, to
+// opts.Output. Entries are first appended to an NDJSON spool file with a
+// checkpoint after every exportCheckpointEvery entries; if the export is
+// interrupted, running it again with the same key continues from the last
+// checkpoint. Once all pages are read the spool is converted to the
+// requested format.
+func ExportEntries(ctx context.Context, client *Client, opts ExportOptions, progress func(ExportProgress)) (*ExportResult, error) {
+	spoolPath := opts.Output + exportSpoolSuffix
+	checkpointPath := opts.Output + exportCheckpointSuffix
+
+	checkpoint := exportCheckpoint{Key: opts.Key, Filter: opts.Filter, Redactions: make(map[string]int)}
+	resumed := false
+	if previous, err := loadExportCheckpoint(checkpointPath); err == nil && previous.Key == opts.Key {
+		checkpoint = previous
+		if checkpoint.Redactions == nil {
+			checkpoint.Redactions = make(map[string]int)
+		}
+		resumed = true
+	}
+
+	spool, err := os.OpenFile(spoolPath, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open spool file: %w", err)
+	}
+	defer spool.Close()
+	spoolInfo, err := spool.Stat()
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat spool file: %w", err)
+	}
+	// A spool that was removed or cut short no longer holds what the
+	// checkpoint counts, so the export starts over
+	if spoolInfo.Size() < checkpoint.Bytes {
+		checkpoint = exportCheckpoint{Key: opts.Key, Filter: opts.Filter, Redactions: make(map[string]int)}
+		resumed = false
+	}
+	// Drop whatever was written after the last checkpoint
+	if err := spool.Truncate(checkpoint.Bytes); err != nil {
+		return nil, fmt.Errorf("failed to truncate spool file: %w", err)
+	}
+	if _, err := spool.Seek(checkpoint.Bytes, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to seek spool file: %w", err)
+	}
+
+	cursor, err := parsePageToken(checkpoint.Cursor)
+	if err != nil {
+		return nil, err
+	}
+	filter := checkpoint.Filter
+	if cursor != nil {
+		filter = NewFilterBuilder().AddFilter(filter).AddFilter(cursor.Filter()).Build()
+	}
+
+	w := bufio.NewWriter(spool)
+	var last LogEntry
+	save := func() error {
+		if err := w.Flush(); err != nil {
+			return fmt.Errorf("failed to write spool file: %w", err)
+		}
+		if err := spool.Sync(); err != nil {
+			return fmt.Errorf("failed to sync spool file: %w", err)
+		}
+		if last.Timestamp != "" {
+			checkpoint.Cursor = pageCursor{Timestamp: last.Timestamp, InsertID: last.InsertID, OldestFirst: true}.Token()
+		}
+		if err := saveExportCheckpoint(checkpointPath, checkpoint); err != nil {
+			return err
+		}
+		if progress != nil {
+			progress(ExportProgress{Entries: checkpoint.Entries, Through: last.Timestamp})
+		}
+		return nil
+	}
+
+	iter := client.Entries(ctx,
+		logadmin.Filter(filter),
+		logadmin.PageSize(scanPageSize),
+	)
+	for {
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			// Keep what was read so far for the next attempt
+			if saveErr := save(); saveErr != nil {
+				return nil, errors.Join(err, saveErr)
+			}
+			return nil, fmt.Errorf("export interrupted after %d entries, run it again to resume: %w", checkpoint.Entries, err)
+		}
+		if cursor.Skip(entry) {
+			continue
+		}
+
+		last = newLogEntry(entry)
+		line, err := json.Marshal(last)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal log entry: %w", err)
+		}
+		redacted, counts := Redact(string(line))
+		for name, count := range counts {
+			checkpoint.Redactions[name] += count
+		}
+		n, err := w.WriteString(redacted + "\n")
+		if err != nil {
+			return nil, fmt.Errorf("failed to write spool file: %w", err)
+		}
+		checkpoint.Entries++
+		checkpoint.Bytes += int64(n)
+
+		if checkpoint.Entries%exportCheckpointEvery == 0 {
+			if err := save(); err != nil {
+				return nil, err
+			}
+		}
+	}
+	if err := save(); err != nil {
+		return nil, err
+	}
+
+	if err := convertSpool(spool, opts); err != nil {
+		return nil, err
+	}
+	info, err := os.Stat(opts.Output)
+	if err != nil {
+		return nil, fmt.Errorf("failed to stat export: %w", err)
+	}
+	os.Remove(spoolPath)
+	os.Remove(checkpointPath)
+
+	result := &ExportResult{
+		Output:  opts.Output,
+		Format:  opts.Format,
+		Filter:  checkpoint.Filter,
+		Columns: opts.Columns,
+		Entries: checkpoint.Entries,
+		Bytes:   info.Size(),
+		Resumed: resumed,
+	}
+	if len(checkpoint.Redactions) > 0 {
+		result.Redactions = checkpoint.Redactions
+	}
+	return result, nil
+}
+
+func loadExportCheckpoint(path string) (exportCheckpoint, error) {
+	var checkpoint exportCheckpoint
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return checkpoint, err
+	}
+	err = json.Unmarshal(data, &checkpoint)
+	return checkpoint, err
+}
+
+// saveExportCheckpoint replaces the checkpoint atomically, so a crash leaves
+// either the previous or the new one
+func saveExportCheckpoint(path string, checkpoint exportCheckpoint) error {
+	data, err := json.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("failed to marshal checkpoint: %w", err)
+	}
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write checkpoint: %w", err)
+	}
+	return nil
+}
+
+// convertSpool writes the completed spool to the output in its final format
+func convertSpool(spool *os.File, opts ExportOptions) error {
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("failed to read spool file: %w", err)
+	}
+
+	tmp := opts.Output + ".tmp"
+	out, err := os.Create(tmp)
+	if err != nil {
+		return fmt.Errorf("failed to create export file: %w", err)
+	}
+	defer os.Remove(tmp)
+
+	switch opts.Format {
+	case "csv":
+		err = writeCSV(out, spool, opts.Columns)
+	case "parquet":
+		err = writeParquet(out, spool, opts.Columns)
+	default:
+		_, err = io.Copy(out, spool)
+	}
+	if closeErr := out.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write export file: %w", err)
+	}
+	return os.Rename(tmp, opts.Output)
+}
+
+// readSpool calls fn with the values of the columns of every spooled entry
+func readSpool(spool io.Reader, columns []string, fn func(values []*string) error) error {
+	scanner := bufio.NewScanner(spool)
+	// Entries can be up to 256KB, and more once escaped
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for scanner.Scan() {
+		var entry LogEntry
+		var object map[string]interface{}
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return err
+		}
+		if err := json.Unmarshal(scanner.Bytes(), &object); err != nil {
+			return err
+		}
+		values := make([]*string, len(columns))
+		for i, column := range columns {
+			values[i] = columnValue(entry, object, column)
+		}
+		if err := fn(values); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+// columnValue looks up a dotted path in an entry. Strings are written as is
+// and other values as JSON. message is the entry's message unless the entry
+// has a field of that name.
+func columnValue(entry LogEntry, object map[string]interface{}, column string) *string {
+	var value interface{} = object
+	for _, key := range strings.Split(column, ".") {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			value = nil
+			break
+		}
+		value = m[key]
+	}
+
+	switch v := value.(type) {
+	case nil:
+		if column == "message" {
+			message := entryMessage(entry)
+			return &message
+		}
+		return nil
+	case string:
+		return &v
+	default:
+		data, err := json.Marshal(v)
+		if err != nil {
+			return nil
+		}
+		s := string(data)
+		return &s
+	}
+}
+
+func writeCSV(out io.Writer, spool io.Reader, columns []string) error {
+	w := csv.NewWriter(out)
+	if err := w.Write(columns); err != nil {
+		return err
+	}
+	record := make([]string, len(columns))
+	err := readSpool(spool, columns, func(values []*string) error {
+		for i, value := range values {
+			record[i] = ""
+			if value != nil {
+				record[i] = *value
+			}
+		}
+		return w.Write(record)
+	})
+	if err != nil {
+		return err
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// writeParquet writes one optional string column per chosen column. Dots
+// are replaced in column names, which many readers treat as nesting.
+func writeParquet(out io.Writer, spool io.Reader, columns []string) error {
+	group := make(parquet.Group, len(columns))
+	names := make([]string, len(columns))
+	for i, column := range columns {
+		names[i] = strings.ReplaceAll(column, ".", "_")
+		group[names[i]] = parquet.Optional(parquet.String())
+	}
+	schema := parquet.NewSchema("log_entry", group)
+
+	// The schema orders columns by name
+	index := make(map[string]int, len(columns))
+	for i, path := range schema.Columns() {
+		index[path[0]] = i
+	}
+
+	w := parquet.NewWriter(out, schema)
+	rows := make([]parquet.Row, 0, exportCheckpointEvery)
+	flush := func() error {
+		if _, err := w.WriteRows(rows); err != nil {
+			return err
+		}
+		rows = rows[:0]
+		return nil
+	}
+	err := readSpool(spool, columns, func(values []*string) error {
+		row := make(parquet.Row, len(values))
+		for i, value := range values {
+			column := index[names[i]]
+			if value == nil {
+				row[column] = parquet.NullValue().Level(0, 0, column)
+			} else {
+				row[column] = parquet.ByteArrayValue([]byte(*value)).Level(0, 1, column)
+			}
+		}
+		rows = append(rows, row)
+		if len(rows) == cap(rows) {
+			return flush()
+		}
+		return nil
+	})
+	if err == nil {
+		err = flush()
+	}
+	if err != nil {
+		return err
+	}
+	return w.Close()
+}
@@ -0,0 +1,217 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/url"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+// ExportURIScheme is the scheme of the resource URIs export files are served under
+const ExportURIScheme = "export"
+
+type ExportLogsTool struct {
+	client      *Client
+	rateLimiter *RateLimiter
+	dir         string
+}
+
+type ExportLogsArgs struct {
+	Filter    string `json:"filter,omitempty"`
+	StartTime string `json:"startTime,omitempty"`
+	EndTime   string `json:"endTime,omitempty"`
+	Format    string `json:"format,omitempty"`
+	Columns   string `json:"columns,omitempty"`
+	Output    string `json:"output,omitempty"`
+}
+
+type exportProgressKey struct{}
+
+// WithExportProgress returns a context that export_logs reports its progress to
+func WithExportProgress(ctx context.Context, progress func(ExportProgress)) context.Context {
+	return context.WithValue(ctx, exportProgressKey{}, progress)
+}
+
+// NewExportLogsTool creates the tool. Files are written to dir, which output
+// paths cannot leave.
+func NewExportLogsTool(client *Client, dir string) *ExportLogsTool {
+	return &ExportLogsTool{
+		client:      client,
+		rateLimiter: NewRateLimiter(),
+		dir:         dir,
+	}
+}
+
+func (t *ExportLogsTool) Name() string {
+	return "export_logs"
+}
+
+func (t *ExportLogsTool) Description() string {
+	return "Export every entry matching filter over startTime..endTime (default the last 1h), oldest first and without a page size cap, to a file for post-mortems. format is ndjson (default), csv or parquet; columns picks comma separated paths for csv and parquet (default timestamp,severity,logName,resource.type,insertId,traceId,message). output is a file name or relative path in the export directory (default logs-<hash>.<format>) and must not exist yet; the file is returned with an export:// resource URI. Progress is reported as the export runs; if it is interrupted, calling it again with the same arguments resumes from the last checkpoint."
+}
+
+func (t *ExportLogsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"format": {
+				Type: "string",
+				Enum: exportFormats,
+			},
+			"columns": {
+				Type: "string",
+			},
+			"output": {
+				Type: "string",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ExportLogsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ExportLogsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	format, columns, err := ParseExportOptions(params.Format, params.Columns)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// The arguments as given, so that an open window resumes with the end it started with
+	key := t.client.Cache().GenerateKey(map[string]string{
+		"filter":    params.Filter,
+		"startTime": params.StartTime,
+		"endTime":   params.EndTime,
+	})
+	output, err := t.outputPath(params.Output, key, format)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	opts := ExportOptions{
+		Filter:  window.Filter(params.Filter),
+		Key:     key,
+		Format:  format,
+		Columns: columns,
+		Output:  output,
+	}
+	progress, _ := ctx.Value(exportProgressKey{}).(func(ExportProgress))
+
+	var result *ExportResult
+	// A retry resumes from the last checkpoint rather than starting over
+	err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+		result, err = ExportEntries(ctx, t.client, opts, progress)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to export logs: %v", err)
+		return ErrorResult(fmt.Sprintf("Error exporting logs: %v", err)), nil
+	}
+	result.URI = ExportURI(t.dir, output)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal export result: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// outputPath resolves the output argument to a file in the export directory.
+// Exports never replace files they did not write: an existing file is only
+// overwritten when it has the default name, which is derived from the key.
+func (t *ExportLogsTool) outputPath(output, key, format string) (string, error) {
+	own := output == ""
+	if own {
+		output = fmt.Sprintf("logs-%s.%s", key[:12], format)
+	}
+	if !filepath.IsAbs(output) {
+		output = filepath.Join(t.dir, output)
+	}
+	if rel, err := filepath.Rel(t.dir, output); err != nil || !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("output %q must be within the export directory %s", output, t.dir)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(output), 0o755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+	// Symbolic links could still lead out of the directory
+	if _, err := resolveWithin(t.dir, filepath.Dir(output)); err != nil {
+		return "", fmt.Errorf("output %q must be within the export directory %s: %w", output, t.dir, err)
+	}
+
+	if _, err := os.Lstat(output); err == nil && !own {
+		return "", fmt.Errorf("output %q already exists; remove it or choose another name", output)
+	}
+	return output, nil
+}
+
+// ExportURI returns the resource URI of a file in the export directory, or an
+// empty string for files elsewhere
+func ExportURI(dir, path string) string {
+	rel, err := filepath.Rel(dir, path)
+	if err != nil || !filepath.IsLocal(rel) {
+		return ""
+	}
+	return ExportURIScheme + "://" + filepath.ToSlash(rel)
+}
+
+// ExportPath resolves a resource URI to a file in the export directory
+func ExportPath(dir, uri string) (string, error) {
+	u, err := url.Parse(uri)
+	if err != nil || u.Scheme != ExportURIScheme {
+		return "", fmt.Errorf("not an export URI: %s", uri)
+	}
+	rel := filepath.FromSlash(u.Host + u.Path)
+	if !filepath.IsLocal(rel) {
+		return "", fmt.Errorf("not an export URI: %s", uri)
+	}
+	// A symbolic link in the directory must not expose files outside it
+	return resolveWithin(dir, filepath.Join(dir, rel))
+}
+
+// resolveWithin resolves the symbolic links in path and fails unless the
+// result is dir itself or inside it
+func resolveWithin(dir, path string) (string, error) {
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		return "", err
+	}
+	resolved, err := filepath.EvalSymlinks(path)
+	if err != nil {
+		return "", err
+	}
+	if rel, err := filepath.Rel(resolvedDir, resolved); err != nil || (rel != "." && !filepath.IsLocal(rel)) {
+		return "", fmt.Errorf("%s resolves to %s outside %s", path, resolved, dir)
+	}
+	return resolved, nil
+}
@@ -0,0 +1,119 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestExportOutputPath(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+	tool := &ExportLogsTool{dir: dir}
+	key := strings.Repeat("ab", 32)
+
+	if err := os.WriteFile(filepath.Join(dir, "existing.csv"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "logs-abababababab.csv"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		output  string
+		want    string
+		wantErr string
+	}{
+		{name: "default name", output: "", want: filepath.Join(dir, "logs-abababababab.ndjson")},
+		{name: "relative", output: "incident/errors.ndjson", want: filepath.Join(dir, "incident", "errors.ndjson")},
+		{name: "absolute inside", output: filepath.Join(dir, "errors.ndjson"), want: filepath.Join(dir, "errors.ndjson")},
+		{name: "absolute outside", output: filepath.Join(outside, "errors.ndjson"), wantErr: "within the export directory"},
+		{name: "parent", output: "../errors.ndjson", wantErr: "within the export directory"},
+		{name: "through a symbolic link", output: "link/errors.ndjson", wantErr: "within the export directory"},
+		{name: "existing file", output: "existing.csv", wantErr: "already exists"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := tool.outputPath(tt.output, key, "ndjson")
+			if tt.wantErr != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+					t.Fatalf("outputPath(%q) = %q, %v, want error %q", tt.output, got, err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("outputPath(%q): %v", tt.output, err)
+			}
+			if got != tt.want {
+				t.Errorf("outputPath(%q) = %q, want %q", tt.output, got, tt.want)
+			}
+		})
+	}
+
+	// A previous export with the default name is replaced
+	if got, err := tool.outputPath("", key, "csv"); err != nil || got != filepath.Join(dir, "logs-abababababab.csv") {
+		t.Errorf("default name over a previous export = %q, %v", got, err)
+	}
+	if _, err := os.Stat(filepath.Join(outside, "errors.ndjson")); !os.IsNotExist(err) {
+		t.Errorf("a file was created outside the export directory")
+	}
+}
+
+func TestExportURI(t *testing.T) {
+	dir := filepath.Join(string(filepath.Separator), "exports")
+
+	if got := ExportURI(dir, filepath.Join(dir, "incident", "errors.csv")); got != "export://incident/errors.csv" {
+		t.Errorf("ExportURI = %q", got)
+	}
+	if got := ExportURI(dir, filepath.Join(string(filepath.Separator), "tmp", "errors.csv")); got != "" {
+		t.Errorf("ExportURI outside the directory = %q", got)
+	}
+}
+
+func TestExportPath(t *testing.T) {
+	dir := t.TempDir()
+	outside := t.TempDir()
+
+	if err := os.MkdirAll(filepath.Join(dir, "incident"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(dir, "incident", "errors.csv"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(filepath.Join(outside, "secret.csv"), nil, 0o644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(filepath.Join(outside, "secret.csv"), filepath.Join(dir, "secret.csv")); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Symlink(outside, filepath.Join(dir, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	resolvedDir, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	path, err := ExportPath(dir, "export://incident/errors.csv")
+	if err != nil || path != filepath.Join(resolvedDir, "incident", "errors.csv") {
+		t.Errorf("ExportPath = %q, %v", path, err)
+	}
+	for _, uri := range []string{
+		"export://../etc/passwd",
+		"file:///etc/passwd",
+		"export:///etc/passwd",
+		"export://secret.csv",
+		"export://link/secret.csv",
+		"export://incident/missing.csv",
+	} {
+		if path, err := ExportPath(dir, uri); err == nil {
+			t.Errorf("ExportPath(%q) = %q was accepted", uri, path)
+		}
+	}
+}
@@ -62,6 +62,14 @@ func (fb *FilterBuilder) AddKeywords(keywords string) *FilterBuilder {
 	return fb
 }
 
+// AddFilter adds a raw Cloud Logging filter expression
+func (fb *FilterBuilder) AddFilter(filter string) *FilterBuilder {
+	if strings.TrimSpace(filter) != "" {
+		fb.filters = append(fb.filters, fmt.Sprintf("(%s)", strings.TrimSpace(filter)))
+	}
+	return fb
+}
+
 func (fb *FilterBuilder) AddDefaultTimeConstraint() *FilterBuilder {
 	// Add default 24-hour constraint if no time filters exist
 	hasTimeFilter := false
@@ -90,3 +98,70 @@ func (fb *FilterBuilder) Reset() *FilterBuilder {
 	fb.filters = make([]string, 0)
 	return fb
 }
+
+// EscapeFilterValue escapes a value for use inside a quoted filter string
+func EscapeFilterValue(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	return strings.ReplaceAll(value, `"`, `\"`)
+}
+
+// FilterEndTime returns the earliest upper timestamp bound of a Cloud Logging
+// filter, or an empty string if the filter has none. Only bounds that are
+// top-level AND clauses, possibly in parentheses, restrict the whole filter;
+// a bound inside OR or NOT leaves the window open.
+func FilterEndTime(filter string) string {
+	var endTime string
+	var earliest time.Time
+	for _, clause := range splitTopLevelAnd(filter) {
+		clause = normalizeClause(clause)
+		bound := ""
+		if inner, ok := unwrapParens(clause); ok {
+			bound = FilterEndTime(inner)
+		} else if matches := timestampClause.FindStringSubmatch(clause); matches != nil && strings.HasPrefix(matches[1], "<") {
+			bound = matches[2]
+		}
+		if bound == "" {
+			continue
+		}
+		end, err := time.Parse(time.RFC3339Nano, bound)
+		if err != nil {
+			continue
+		}
+		if endTime == "" || end.Before(earliest) {
+			endTime, earliest = bound, end
+		}
+	}
+	return endTime
+}
+
+// unwrapParens returns the inside of a clause that is a single parenthesized
+// group
+func unwrapParens(clause string) (string, bool) {
+	if !strings.HasPrefix(clause, "(") || !strings.HasSuffix(clause, ")") {
+		return "", false
+	}
+	depth := 0
+	inQuote := false
+	runes := []rune(clause)
+	for i := 0; i < len(runes); i++ {
+		switch r := runes[i]; {
+		case inQuote:
+			if r == '\\' {
+				i++
+			} else if r == '"' {
+				inQuote = false
+			}
+		case r == '"':
+			inQuote = true
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+			// The group closes before the end, as in (a) OR (b)
+			if depth == 0 && i < len(runes)-1 {
+				return "", false
+			}
+		}
+	}
+	return string(runes[1 : len(runes)-1]), true
+}
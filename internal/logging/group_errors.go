@@ -0,0 +1,177 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const defaultErrorGroups = 20
+
+type GroupErrorsTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type GroupErrorsArgs struct {
+	Filter      string `json:"filter,omitempty"`
+	StartTime   string `json:"startTime,omitempty"`
+	EndTime     string `json:"endTime,omitempty"`
+	MinSeverity string `json:"minSeverity,omitempty"`
+	MaxGroups   int    `json:"maxGroups,omitempty"`
+	MaxEntries  int    `json:"maxEntries,omitempty"`
+}
+
+type groupErrorsResult struct {
+	Filter     string       `json:"filter"`
+	Scanned    int          `json:"scanned"`
+	Truncated  bool         `json:"truncated"`
+	GroupCount int          `json:"groupCount"`
+	Groups     []ErrorGroup `json:"groups"`
+}
+
+func NewGroupErrorsTool(client *Client) *GroupErrorsTool {
+	return &GroupErrorsTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *GroupErrorsTool) Name() string {
+	return "group_errors"
+}
+
+func (t *GroupErrorsTool) Description() string {
+	return "Group error log entries by message fingerprint (IDs, numbers, UUIDs and timestamps removed; stack traces fingerprinted by their top frames). Returns each group's count, first and last seen, an example insertId and affected services. Defaults to ERROR and above in the last hour."
+}
+
+func (t *GroupErrorsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"minSeverity": {
+				Type: "string",
+				Enum: logSeverities,
+			},
+			"maxGroups": {
+				Type: "integer",
+			},
+			"maxEntries": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *GroupErrorsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params GroupErrorsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	if params.MinSeverity == "" {
+		params.MinSeverity = "ERROR"
+	}
+	params.MinSeverity = strings.ToUpper(params.MinSeverity)
+	if !slices.Contains(logSeverities, params.MinSeverity) {
+		return ErrorResult(fmt.Sprintf("Error: minSeverity must be one of %s", strings.Join(logSeverities, ", "))), nil
+	}
+	if params.MaxGroups <= 0 {
+		params.MaxGroups = defaultErrorGroups
+	}
+	params.MaxEntries = scanLimit(params.MaxEntries)
+
+	filter := NewFilterBuilder().
+		AddTimeRange(window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339)).
+		AddSeverity(params.MinSeverity).
+		AddFilter(params.Filter).
+		Build()
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":       t.Name(),
+		"filter":     NormalizeFilter(filter, t.cache.TimeBucket()),
+		"maxEntries": params.MaxEntries,
+	})
+
+	var result groupErrorsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for error groups: %s", filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.groupErrors(ctx, filter, params.MaxEntries)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to group errors: %v", err)
+			return ErrorResult(fmt.Sprintf("Error grouping errors: %v", err)), nil
+		}
+
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	if len(result.Groups) > params.MaxGroups {
+		result.Groups = result.Groups[:params.MaxGroups]
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal error groups: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *GroupErrorsTool) groupErrors(ctx context.Context, filter string, maxEntries int) (groupErrorsResult, error) {
+	grouper := NewErrorGrouper()
+	scanned, truncated, err := scanEntries(ctx, t.client, filter, maxEntries, func(entry *logging.Entry) {
+		grouper.Add(newLogEntry(entry))
+	})
+	if err != nil {
+		return groupErrorsResult{}, err
+	}
+
+	groups := grouper.Groups()
+	return groupErrorsResult{
+		Filter:     filter,
+		Scanned:    scanned,
+		Truncated:  truncated,
+		GroupCount: len(groups),
+		Groups:     groups,
+	}, nil
+}
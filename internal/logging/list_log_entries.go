@@ -7,8 +7,11 @@ import (
 	"log"
 	"time"
 
+	"cloud.google.com/go/logging"
 	"cloud.google.com/go/logging/logadmin"
 	"google.golang.org/api/iterator"
+	"google.golang.org/genproto/googleapis/cloud/audit"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/takashabe/gco-o11y-mcp/pkg/types"
 )
@@ -20,9 +23,14 @@ type ListLogEntriesTools struct {
 }
 
 type ListLogEntriesArgs struct {
-	Filter   string `json:"filter,omitempty"`
-	PageSize int    `json:"pageSize,omitempty"`
-	OrderBy  string `json:"orderBy,omitempty"`
+	Filter          string `json:"filter,omitempty"`
+	PageSize        int    `json:"pageSize,omitempty"`
+	OrderBy         string `json:"orderBy,omitempty"`
+	Fields          string `json:"fields,omitempty"`
+	MaxPayloadBytes int    `json:"maxPayloadBytes,omitempty"`
+	Compact         bool   `json:"compact,omitempty"`
+	MaxOutputTokens int    `json:"maxOutputTokens,omitempty"`
+	PageToken       string `json:"pageToken,omitempty"`
 }
 
 type LogEntry struct {
@@ -35,12 +43,31 @@ type LogEntry struct {
 	JSONPayload map[string]interface{} `json:"jsonPayload,omitempty"`
 	InsertID    string                 `json:"insertId,omitempty"`
 	TraceID     string                 `json:"traceId,omitempty"`
+	SpanID      string                 `json:"spanId,omitempty"`
+
+	// Set for Cloud Audit Logs entries
+	ProtoPayload *AuditEntry `json:"protoPayload,omitempty"`
+	// Set for request logs such as those of Cloud Run and load balancers
+	HTTPRequest *LogHTTPRequest `json:"httpRequest,omitempty"`
+}
+
+// LogHTTPRequest holds the httpRequest fields worth reading, named as in the
+// Logging API so that they can be projected with the same paths
+type LogHTTPRequest struct {
+	RequestMethod string `json:"requestMethod,omitempty"`
+	RequestURL    string `json:"requestUrl,omitempty"`
+	Status        int    `json:"status,omitempty"`
+	Latency       string `json:"latency,omitempty"`
+	ResponseSize  int64  `json:"responseSize,omitempty"`
+	RemoteIP      string `json:"remoteIp,omitempty"`
+	UserAgent     string `json:"userAgent,omitempty"`
+	Referer       string `json:"referer,omitempty"`
 }
 
 func NewListLogEntriesTools(client *Client) *ListLogEntriesTools {
 	return &ListLogEntriesTools{
 		client:      client,
-		cache:       NewLogCache(),
+		cache:       client.Cache(),
 		rateLimiter: NewRateLimiter(),
 	}
 }
@@ -50,7 +77,7 @@ func (t *ListLogEntriesTools) Name() string {
 }
 
 func (t *ListLogEntriesTools) Description() string {
-	return "List log entries from Google Cloud Logging. Supports filtering by timestamp, severity, resource, and custom filters."
+	return "List log entries from Google Cloud Logging. Supports filtering by timestamp, severity, resource, and custom filters. fields projects entries onto comma separated paths (e.g. jsonPayload.message,httpRequest.status), maxPayloadBytes cuts each payload to a byte budget with markers for what was omitted, and compact drops indentation. Results larger than maxOutputTokens (default 20000) degrade to an object whose degraded field says how: payloads trimmed, repeated messages collapsed with counts, or a summary of severities, top messages and time span with the leading entries and a nextPageToken; pass it back as pageToken to read on."
 }
 
 func (t *ListLogEntriesTools) Schema() types.Schema {
@@ -66,12 +93,27 @@ func (t *ListLogEntriesTools) Schema() types.Schema {
 			"orderBy": {
 				Type: "string",
 			},
+			"fields": {
+				Type: "string",
+			},
+			"maxPayloadBytes": {
+				Type: "integer",
+			},
+			"compact": {
+				Type: "boolean",
+			},
+			"maxOutputTokens": {
+				Type: "integer",
+			},
+			"pageToken": {
+				Type: "string",
+			},
 		},
 		AdditionalProperties: false,
 	}
 }
 
-func (t *ListLogEntriesTools) Execute(args map[string]interface{}) (*types.CallToolResult, error) {
+func (t *ListLogEntriesTools) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
 	var params ListLogEntriesArgs
 	if argsBytes, err := json.Marshal(args); err != nil {
 		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
@@ -91,11 +133,22 @@ func (t *ListLogEntriesTools) Execute(args map[string]interface{}) (*types.CallT
 		params.OrderBy = "timestamp desc"
 	}
 
+	view := NewEntryView(params.Fields, params.MaxPayloadBytes, params.Compact)
+	budget := outputBudget(params.MaxOutputTokens)
+
+	if _, err := parsePageToken(params.PageToken); err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
 	// Check cache first
-	cacheKey := t.cache.GenerateKey(params)
+	cacheKey := t.cache.GenerateQueryKey(params.Filter, "", params.PageSize, params.OrderBy)
+	if params.PageToken != "" {
+		// Kept out of the filter, where timestamps are rounded
+		cacheKey = t.cache.GenerateKey([]string{cacheKey, params.PageToken})
+	}
 	if cachedEntries, found := t.cache.Get(cacheKey); found {
 		log.Printf("Cache hit for filter: %s", params.Filter)
-		entriesJSON, err := json.MarshalIndent(cachedEntries, "", "  ")
+		entriesJSON, err := fitEntries(cachedEntries, view, budget, nil)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal cached entries: %w", err)
 		}
@@ -107,7 +160,6 @@ func (t *ListLogEntriesTools) Execute(args map[string]interface{}) (*types.CallT
 		}, nil
 	}
 
-	ctx := context.Background()
 	var entries []LogEntry
 	var err error
 
@@ -127,10 +179,14 @@ func (t *ListLogEntriesTools) Execute(args map[string]interface{}) (*types.CallT
 		}, nil
 	}
 
-	// Cache the results (TTL: 2 minutes)
-	t.cache.Set(cacheKey, entries, 2*time.Minute)
+	// Cache the results (TTL: 2 minutes, persistent for closed historical windows)
+	if IsClosedWindow(FilterEndTime(params.Filter)) {
+		t.cache.SetHistorical(cacheKey, entries)
+	} else {
+		t.cache.Set(cacheKey, entries, 2*time.Minute)
+	}
 
-	entriesJSON, err := json.MarshalIndent(entries, "", "  ")
+	entriesJSON, err := fitEntries(entries, view, budget, nil)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal log entries: %w", err)
 	}
@@ -144,14 +200,21 @@ func (t *ListLogEntriesTools) Execute(args map[string]interface{}) (*types.CallT
 }
 
 func (t *ListLogEntriesTools) listLogEntries(ctx context.Context, params ListLogEntriesArgs) ([]LogEntry, error) {
-	client := t.client.LogAdminClient()
-
 	// Add timeout to prevent long-running queries
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	iter := client.Entries(ctxWithTimeout,
-		logadmin.Filter(params.Filter),
+	cursor, err := parsePageToken(params.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	filter := params.Filter
+	if cursor != nil {
+		filter = NewFilterBuilder().AddFilter(filter).AddFilter(cursor.Filter()).Build()
+	}
+
+	iter := t.client.Entries(ctxWithTimeout,
+		logadmin.Filter(filter),
 		logadmin.NewestFirst(),
 	)
 
@@ -170,36 +233,76 @@ func (t *ListLogEntriesTools) listLogEntries(ctx context.Context, params ListLog
 		if err != nil {
 			return nil, fmt.Errorf("failed to iterate log entries: %w", err)
 		}
-
-		logEntry := LogEntry{
-			Timestamp: entry.Timestamp.Format(time.RFC3339),
-			Severity:  entry.Severity.String(),
-			LogName:   entry.LogName,
-			InsertID:  entry.InsertID,
-			TraceID:   entry.Trace,
+		if cursor.Skip(entry) {
+			continue
 		}
 
-		if entry.Resource != nil {
-			logEntry.Resource = map[string]interface{}{
-				"type":   entry.Resource.Type,
-				"labels": entry.Resource.Labels,
-			}
-		}
+		logEntry := newLogEntry(entry)
+
+		entries = append(entries, logEntry)
+		count++
+	}
+
+	return entries, nil
+}
+
+// newLogEntry converts an entry returned by the Logging API into the
+// representation returned by the tools
+func newLogEntry(entry *logging.Entry) LogEntry {
+	logEntry := LogEntry{
+		// Keep sub-second precision so entries of a single request stay ordered
+		Timestamp: entry.Timestamp.Format(time.RFC3339Nano),
+		Severity:  entry.Severity.String(),
+		LogName:   entry.LogName,
+		InsertID:  entry.InsertID,
+		TraceID:   entry.Trace,
+		SpanID:    entry.SpanID,
+	}
 
-		if entry.Labels != nil {
-			logEntry.Labels = entry.Labels
+	if entry.Resource != nil {
+		labels := make(map[string]interface{}, len(entry.Resource.Labels))
+		for k, v := range entry.Resource.Labels {
+			labels[k] = v
 		}
+		logEntry.Resource = map[string]interface{}{
+			"type":   entry.Resource.Type,
+			"labels": labels,
+		}
+	}
+
+	if entry.Labels != nil {
+		logEntry.Labels = entry.Labels
+	}
 
-		switch payload := entry.Payload.(type) {
-		case string:
-			logEntry.TextPayload = payload
-		case map[string]interface{}:
-			logEntry.JSONPayload = payload
+	if req := entry.HTTPRequest; req != nil {
+		logEntry.HTTPRequest = &LogHTTPRequest{
+			Status:       req.Status,
+			ResponseSize: req.ResponseSize,
+			RemoteIP:     req.RemoteIP,
+		}
+		if req.Latency > 0 {
+			logEntry.HTTPRequest.Latency = req.Latency.String()
 		}
+		if req.Request != nil {
+			logEntry.HTTPRequest.RequestMethod = req.Request.Method
+			logEntry.HTTPRequest.UserAgent = req.Request.UserAgent()
+			logEntry.HTTPRequest.Referer = req.Request.Referer()
+			if req.Request.URL != nil {
+				logEntry.HTTPRequest.RequestURL = req.Request.URL.String()
+			}
+		}
+	}
 
-		entries = append(entries, logEntry)
-		count++
+	switch payload := entry.Payload.(type) {
+	case string:
+		logEntry.TextPayload = payload
+	case *structpb.Struct:
+		logEntry.JSONPayload = payload.AsMap()
+	case map[string]interface{}:
+		logEntry.JSONPayload = payload
+	case *audit.AuditLog:
+		logEntry.ProtoPayload = newAuditEntry(payload)
 	}
 
-	return entries, nil
+	return logEntry
 }
@@ -0,0 +1,107 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+type ListPresetQueriesTool struct{}
+
+type ListPresetQueriesArgs struct {
+	QueryName string `json:"queryName,omitempty"`
+}
+
+type presetQueryInfo struct {
+	Name        string            `json:"name"`
+	Description string            `json:"description"`
+	Parameters  []PresetParameter `json:"parameters,omitempty"`
+	Window      string            `json:"window"`
+	PageSize    int               `json:"pageSize"`
+	Schema      types.Schema      `json:"schema"`
+	Filter      string            `json:"filter"`
+}
+
+func NewListPresetQueriesTool() *ListPresetQueriesTool {
+	return &ListPresetQueriesTool{}
+}
+
+func (t *ListPresetQueriesTool) Name() string {
+	return "list_preset_queries"
+}
+
+func (t *ListPresetQueriesTool) Description() string {
+	return "List available preset queries with their description, named parameters, default window and the filter they expand to. Use the result to call preset_query."
+}
+
+func (t *ListPresetQueriesTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"queryName": {
+				Type: "string",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListPresetQueriesTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListPresetQueriesArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	now := time.Now()
+	var presets []presetQueryInfo
+	for _, preset := range ListPresetQueries() {
+		if params.QueryName != "" && preset.Name != params.QueryName {
+			continue
+		}
+		presets = append(presets, presetQueryInfo{
+			Name:        preset.Name,
+			Description: preset.Description,
+			Parameters:  preset.Parameters,
+			Window:      preset.Window,
+			PageSize:    preset.PageSize,
+			Schema:      preset.Schema(),
+			Filter:      preset.Preview(now),
+		})
+	}
+
+	if params.QueryName != "" && len(presets) == 0 {
+		return &types.CallToolResult{
+			Content: []types.Content{{
+				Type: "text",
+				Text: fmt.Sprintf("Error: preset query '%s' not found", params.QueryName),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	result := map[string]interface{}{
+		"count":   len(presets),
+		"presets": presets,
+	}
+	// Surface a broken preset file so it can be fixed without checking server logs
+	if err := PresetFileError(); err != nil {
+		result["presetFileError"] = err.Error()
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal preset queries: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
@@ -0,0 +1,360 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	maxHistogramBuckets    = 200
+	defaultHistogramGroups = 6
+	otherGroup             = "(other)"
+	noneGroup              = "(none)"
+)
+
+// Bucket sizes chosen automatically, aiming for at most 60 buckets
+var histogramBucketSizes = []time.Duration{
+	time.Minute, 5 * time.Minute, 10 * time.Minute, 15 * time.Minute, 30 * time.Minute,
+	time.Hour, 3 * time.Hour, 6 * time.Hour, 12 * time.Hour, 24 * time.Hour,
+}
+
+var sparklineLevels = []rune("▁▂▃▄▅▆▇█")
+
+type LogHistogramTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type LogHistogramArgs struct {
+	Filter     string `json:"filter,omitempty"`
+	StartTime  string `json:"startTime,omitempty"`
+	EndTime    string `json:"endTime,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`
+	GroupBy    string `json:"groupBy,omitempty"`
+	MaxGroups  int    `json:"maxGroups,omitempty"`
+	MaxEntries int    `json:"maxEntries,omitempty"`
+	Sparkline  bool   `json:"sparkline,omitempty"`
+}
+
+type histogramBucket struct {
+	Start  time.Time      `json:"start"`
+	Total  int            `json:"total"`
+	Counts map[string]int `json:"counts,omitempty"`
+}
+
+type histogramResult struct {
+	Filter    string            `json:"filter"`
+	Start     time.Time         `json:"start"`
+	End       time.Time         `json:"end"`
+	Bucket    string            `json:"bucket"`
+	GroupBy   string            `json:"groupBy,omitempty"`
+	Total     int               `json:"total"`
+	Truncated bool              `json:"truncated"`
+	Groups    []string          `json:"groups,omitempty"`
+	Buckets   []histogramBucket `json:"buckets"`
+}
+
+func NewLogHistogramTool(client *Client) *LogHistogramTool {
+	return &LogHistogramTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *LogHistogramTool) Name() string {
+	return "log_histogram"
+}
+
+func (t *LogHistogramTool) Description() string {
+	return "Count matching log entries in time buckets to see when volume or errors started spiking. Optionally group by severity, service, logName, label:<key> or resource.labels.<key>. Defaults to the last hour."
+}
+
+func (t *LogHistogramTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"bucket": {
+				Type: "string",
+			},
+			"groupBy": {
+				Type: "string",
+			},
+			"maxGroups": {
+				Type: "integer",
+			},
+			"maxEntries": {
+				Type: "integer",
+			},
+			"sparkline": {
+				Type: "boolean",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *LogHistogramTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params LogHistogramArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	bucket, err := histogramBucketSize(params.Bucket, window.Duration())
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	// Align buckets to round times so that results are stable across calls
+	window.Start = window.Start.Truncate(bucket)
+
+	if params.MaxGroups <= 0 {
+		params.MaxGroups = defaultHistogramGroups
+	}
+	params.MaxEntries = scanLimit(params.MaxEntries)
+	filter := window.Filter(params.Filter)
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":       t.Name(),
+		"filter":     NormalizeFilter(filter, t.cache.TimeBucket()),
+		"bucket":     bucket,
+		"groupBy":    params.GroupBy,
+		"maxEntries": params.MaxEntries,
+	})
+
+	var result histogramResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for histogram: %s", filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.buildHistogram(ctx, filter, window, bucket, params)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to build log histogram: %v", err)
+			return ErrorResult(fmt.Sprintf("Error building log histogram: %v", err)), nil
+		}
+
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: renderHistogram(result, params.MaxGroups, params.Sparkline),
+		}},
+	}, nil
+}
+
+func (t *LogHistogramTool) buildHistogram(ctx context.Context, filter string, window TimeWindow, bucket time.Duration, params LogHistogramArgs) (histogramResult, error) {
+	count := int(math.Ceil(float64(window.Duration()) / float64(bucket)))
+	result := histogramResult{
+		Filter:  filter,
+		Start:   window.Start,
+		End:     window.End,
+		Bucket:  bucket.String(),
+		GroupBy: params.GroupBy,
+		Buckets: make([]histogramBucket, count),
+	}
+	for i := range result.Buckets {
+		result.Buckets[i].Start = window.Start.Add(time.Duration(i) * bucket)
+	}
+
+	groupTotals := make(map[string]int)
+	scanned, truncated, err := scanEntries(ctx, t.client, filter, params.MaxEntries, func(entry *logging.Entry) {
+		index := int(entry.Timestamp.Sub(window.Start) / bucket)
+		if index < 0 || index >= count {
+			return
+		}
+		b := &result.Buckets[index]
+		b.Total++
+		if params.GroupBy != "" {
+			group := entryGroup(entry, params.GroupBy)
+			if b.Counts == nil {
+				b.Counts = make(map[string]int)
+			}
+			b.Counts[group]++
+			groupTotals[group]++
+		}
+	})
+	if err != nil {
+		return result, err
+	}
+
+	result.Total = scanned
+	result.Truncated = truncated
+
+	for group := range groupTotals {
+		result.Groups = append(result.Groups, group)
+	}
+	sort.Slice(result.Groups, func(i, j int) bool {
+		if groupTotals[result.Groups[i]] != groupTotals[result.Groups[j]] {
+			return groupTotals[result.Groups[i]] > groupTotals[result.Groups[j]]
+		}
+		return result.Groups[i] < result.Groups[j]
+	})
+
+	return result, nil
+}
+
+// entryGroup extracts the value an entry is grouped by
+func entryGroup(entry *logging.Entry, groupBy string) string {
+	var value string
+	switch {
+	case groupBy == "severity":
+		value = entry.Severity.String()
+	case groupBy == "service":
+		if entry.Resource != nil {
+			value = ResourceServiceName(entry.Resource.Type, entry.Resource.Labels)
+		}
+	case groupBy == "logName":
+		value = entry.LogName
+		if i := strings.LastIndex(value, "/logs/"); i >= 0 {
+			value = value[i+len("/logs/"):]
+		}
+	case strings.HasPrefix(groupBy, "label:"):
+		value = entry.Labels[strings.TrimPrefix(groupBy, "label:")]
+	case strings.HasPrefix(groupBy, "resource.labels."):
+		if entry.Resource != nil {
+			value = entry.Resource.Labels[strings.TrimPrefix(groupBy, "resource.labels.")]
+		}
+	}
+	if value == "" {
+		return noneGroup
+	}
+	return value
+}
+
+func histogramBucketSize(requested string, window time.Duration) (time.Duration, error) {
+	if requested != "" {
+		bucket, err := time.ParseDuration(requested)
+		if err != nil || bucket <= 0 {
+			return 0, fmt.Errorf("bucket %q is not a positive duration", requested)
+		}
+		if window/bucket > maxHistogramBuckets {
+			return 0, fmt.Errorf("bucket %s is too small for a %s window (max %d buckets)", bucket, window, maxHistogramBuckets)
+		}
+		return bucket, nil
+	}
+
+	for _, size := range histogramBucketSizes {
+		if window/size <= 60 {
+			return size, nil
+		}
+	}
+	return histogramBucketSizes[len(histogramBucketSizes)-1], nil
+}
+
+// renderHistogram formats the result as a compact table, folding groups
+// beyond maxGroups into an "(other)" column
+func renderHistogram(result histogramResult, maxGroups int, sparkline bool) string {
+	columns := result.Groups
+	folded := false
+	if len(columns) > maxGroups {
+		columns = columns[:maxGroups]
+		folded = true
+	}
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "filter: %s\n", result.Filter)
+	fmt.Fprintf(&b, "window: %s to %s, bucket %s, %d entries", result.Start.Format(time.RFC3339), result.End.Format(time.RFC3339), result.Bucket, result.Total)
+	if result.Truncated {
+		b.WriteString(" (truncated: raise maxEntries or narrow the filter for exact counts)")
+	}
+	b.WriteString("\n\n")
+
+	timeFormat := "2006-01-02 15:04"
+	if bucket, _ := time.ParseDuration(result.Bucket); bucket >= 24*time.Hour {
+		timeFormat = "2006-01-02"
+	}
+
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', tabwriter.AlignRight)
+	header := []string{"bucket (UTC)", "total"}
+	header = append(header, columns...)
+	if folded {
+		header = append(header, otherGroup)
+	}
+	fmt.Fprintln(tw, strings.Join(header, "\t")+"\t")
+
+	series := make(map[string][]int)
+	totals := make([]int, len(result.Buckets))
+	for i, bucket := range result.Buckets {
+		row := []string{bucket.Start.UTC().Format(timeFormat), fmt.Sprint(bucket.Total)}
+		totals[i] = bucket.Total
+		shown := 0
+		for _, group := range columns {
+			row = append(row, fmt.Sprint(bucket.Counts[group]))
+			series[group] = append(series[group], bucket.Counts[group])
+			shown += bucket.Counts[group]
+		}
+		if folded {
+			row = append(row, fmt.Sprint(bucket.Total-shown))
+		}
+		fmt.Fprintln(tw, strings.Join(row, "\t")+"\t")
+	}
+	tw.Flush()
+
+	if sparkline {
+		b.WriteString("\n")
+		lines := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+		fmt.Fprintf(lines, "total\t%s\n", renderSparkline(totals))
+		for _, group := range columns {
+			fmt.Fprintf(lines, "%s\t%s\n", group, renderSparkline(series[group]))
+		}
+		lines.Flush()
+	}
+
+	return b.String()
+}
+
+// renderSparkline scales values to block characters; zero is shown as a space
+func renderSparkline(values []int) string {
+	max := 0
+	for _, v := range values {
+		if v > max {
+			max = v
+		}
+	}
+
+	var b strings.Builder
+	for _, v := range values {
+		if v == 0 || max == 0 {
+			b.WriteRune(' ')
+			continue
+		}
+		level := int(math.Ceil(float64(v)/float64(max)*float64(len(sparklineLevels)))) - 1
+		b.WriteRune(sparklineLevels[level])
+	}
+	return b.String()
+}
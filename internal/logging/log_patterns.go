@@ -0,0 +1,305 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sort"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultPatternLimit  = 20
+	defaultPatternGrowth = 2.0
+	// Patterns seen fewer times than this in the window are never flagged as grown
+	minGrownPatternCount = 5
+)
+
+const (
+	patternNew    = "new"
+	patternGrown  = "grown"
+	patternShrunk = "shrunk"
+	patternGone   = "gone"
+)
+
+type LogPatternsTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type LogPatternsArgs struct {
+	Filter            string  `json:"filter,omitempty"`
+	StartTime         string  `json:"startTime,omitempty"`
+	EndTime           string  `json:"endTime,omitempty"`
+	BaselineStartTime string  `json:"baselineStartTime,omitempty"`
+	BaselineEndTime   string  `json:"baselineEndTime,omitempty"`
+	Compare           bool    `json:"compare,omitempty"`
+	GrowthFactor      float64 `json:"growthFactor,omitempty"`
+	Similarity        float64 `json:"similarity,omitempty"`
+	MaxPatterns       int     `json:"maxPatterns,omitempty"`
+	MaxEntries        int     `json:"maxEntries,omitempty"`
+}
+
+type windowPattern struct {
+	LogPattern
+	BaselineCount int     `json:"baselineCount"`
+	Change        float64 `json:"change,omitempty"`
+	Status        string  `json:"status,omitempty"`
+}
+
+type logPatternsResult struct {
+	Filter          string          `json:"filter"`
+	Start           time.Time       `json:"start"`
+	End             time.Time       `json:"end"`
+	Scanned         int             `json:"scanned"`
+	BaselineStart   *time.Time      `json:"baselineStart,omitempty"`
+	BaselineEnd     *time.Time      `json:"baselineEnd,omitempty"`
+	BaselineScanned int             `json:"baselineScanned,omitempty"`
+	Truncated       bool            `json:"truncated"`
+	PatternCount    int             `json:"patternCount"`
+	NewPatterns     int             `json:"newPatterns,omitempty"`
+	GrownPatterns   int             `json:"grownPatterns,omitempty"`
+	Patterns        []windowPattern `json:"patterns"`
+}
+
+func NewLogPatternsTool(client *Client) *LogPatternsTool {
+	return &LogPatternsTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *LogPatternsTool) Name() string {
+	return "log_patterns"
+}
+
+func (t *LogPatternsTool) Description() string {
+	return "Mine log message templates (Drain-style) over a time window, with <*> wildcard slots, counts and sample values. Set baselineStartTime/baselineEndTime, or compare=true for the preceding window of equal length, to flag patterns that are new, have grown, shrunk or disappeared. Defaults to the last hour."
+}
+
+func (t *LogPatternsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"baselineStartTime": {
+				Type: "string",
+			},
+			"baselineEndTime": {
+				Type: "string",
+			},
+			"compare": {
+				Type: "boolean",
+			},
+			"growthFactor": {
+				Type: "number",
+			},
+			"similarity": {
+				Type: "number",
+			},
+			"maxPatterns": {
+				Type: "integer",
+			},
+			"maxEntries": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *LogPatternsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params LogPatternsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	var baseline *TimeWindow
+	if params.BaselineStartTime != "" || params.BaselineEndTime != "" {
+		w, err := ParseTimeWindow(params.BaselineStartTime, params.BaselineEndTime, window.Duration())
+		if err != nil {
+			return ErrorResult(fmt.Sprintf("Error: baseline: %v", err)), nil
+		}
+		baseline = &w
+	} else if params.Compare {
+		baseline = &TimeWindow{Start: window.Start.Add(-window.Duration()), End: window.Start}
+	}
+
+	if params.MaxPatterns <= 0 {
+		params.MaxPatterns = defaultPatternLimit
+	}
+	if params.GrowthFactor <= 1 {
+		params.GrowthFactor = defaultPatternGrowth
+	}
+	params.MaxEntries = scanLimit(params.MaxEntries)
+
+	filter := window.Filter(params.Filter)
+	keyParams := map[string]interface{}{
+		"tool":       t.Name(),
+		"filter":     NormalizeFilter(filter, t.cache.TimeBucket()),
+		"similarity": params.Similarity,
+		"maxEntries": params.MaxEntries,
+	}
+	if baseline != nil {
+		keyParams["baseline"] = NormalizeFilter(baseline.Filter(params.Filter), t.cache.TimeBucket())
+	}
+	cacheKey := t.cache.GenerateKey(keyParams)
+
+	var result logPatternsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for log patterns: %s", filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.minePatterns(ctx, params, window, baseline)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to mine log patterns: %v", err)
+			return ErrorResult(fmt.Sprintf("Error mining log patterns: %v", err)), nil
+		}
+
+		// An explicit baseline can still be open when the window is closed
+		if window.IsClosed() && (baseline == nil || baseline.IsClosed()) {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	classifyPatterns(&result, params.GrowthFactor)
+	if len(result.Patterns) > params.MaxPatterns {
+		result.Patterns = result.Patterns[:params.MaxPatterns]
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal log patterns: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// minePatterns mines both windows with a single miner so that their
+// templates line up, counting per window by pattern ID
+func (t *LogPatternsTool) minePatterns(ctx context.Context, params LogPatternsArgs, window TimeWindow, baseline *TimeWindow) (logPatternsResult, error) {
+	miner := NewPatternMiner(params.Similarity)
+	counts := make(map[int]int)
+	baselineCounts := make(map[int]int)
+
+	mine := func(entry *logging.Entry, into map[int]int) {
+		info := ExtractServiceInfoFromLogEntry(newLogEntry(entry))
+		into[miner.Add(info.Message)]++
+	}
+
+	result := logPatternsResult{
+		Filter: window.Filter(params.Filter),
+		Start:  window.Start,
+		End:    window.End,
+	}
+
+	if baseline != nil {
+		scanned, truncated, err := scanEntries(ctx, t.client, baseline.Filter(params.Filter), params.MaxEntries, func(entry *logging.Entry) {
+			mine(entry, baselineCounts)
+		})
+		if err != nil {
+			return result, err
+		}
+		result.BaselineStart = &baseline.Start
+		result.BaselineEnd = &baseline.End
+		result.BaselineScanned = scanned
+		result.Truncated = truncated
+	}
+
+	scanned, truncated, err := scanEntries(ctx, t.client, result.Filter, params.MaxEntries, func(entry *logging.Entry) {
+		mine(entry, counts)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Scanned = scanned
+	result.Truncated = result.Truncated || truncated
+
+	for _, pattern := range miner.Patterns() {
+		pattern.Count = counts[pattern.ID]
+		result.Patterns = append(result.Patterns, windowPattern{
+			LogPattern:    pattern,
+			BaselineCount: baselineCounts[pattern.ID],
+		})
+	}
+	result.PatternCount = len(result.Patterns)
+	return result, nil
+}
+
+// classifyPatterns flags changes against the baseline, comparing rates so
+// that windows of different lengths can be compared, and orders new and
+// grown patterns first
+func classifyPatterns(result *logPatternsResult, growthFactor float64) {
+	if result.BaselineStart == nil {
+		sort.SliceStable(result.Patterns, func(i, j int) bool {
+			return result.Patterns[i].Count > result.Patterns[j].Count
+		})
+		return
+	}
+
+	scale := float64(result.End.Sub(result.Start)) / float64(result.BaselineEnd.Sub(*result.BaselineStart))
+	result.NewPatterns, result.GrownPatterns = 0, 0
+	for i := range result.Patterns {
+		p := &result.Patterns[i]
+		expected := float64(p.BaselineCount) * scale
+		p.Status, p.Change = "", 0
+		switch {
+		case p.BaselineCount == 0:
+			p.Status = patternNew
+			result.NewPatterns++
+		case p.Count == 0:
+			p.Status = patternGone
+		default:
+			p.Change = float64(p.Count) / expected
+			if p.Change >= growthFactor && p.Count >= minGrownPatternCount {
+				p.Status = patternGrown
+				result.GrownPatterns++
+			} else if p.Change <= 1/growthFactor && p.BaselineCount >= minGrownPatternCount {
+				p.Status = patternShrunk
+			}
+		}
+	}
+
+	rank := map[string]int{patternNew: 0, patternGrown: 1, patternShrunk: 2, patternGone: 3, "": 2}
+	sort.SliceStable(result.Patterns, func(i, j int) bool {
+		a, b := result.Patterns[i], result.Patterns[j]
+		if rank[a.Status] != rank[b.Status] {
+			return rank[a.Status] < rank[b.Status]
+		}
+		if a.Count != b.Count {
+			return a.Count > b.Count
+		}
+		return a.BaselineCount > b.BaselineCount
+	})
+}
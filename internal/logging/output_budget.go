@@ -0,0 +1,237 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"maps"
+	"sort"
+	"strings"
+	"sync/atomic"
+)
+
+const (
+	// Rough number of bytes per token in the JSON the tools return
+	bytesPerToken = 4
+	// DefaultMaxOutputTokens keeps results well below the size at which MCP
+	// clients start rejecting tool output
+	DefaultMaxOutputTokens = 20000
+	// Payload budget tried first when entries do not fit
+	trimmedPayloadBytes = 256
+	// Messages listed in a summary, and the length they are cut to
+	maxSummaryMessages     = 10
+	maxSummaryMessageBytes = 200
+)
+
+// Ways a result can be degraded to fit the budget, reported as "degraded"
+const (
+	degradedPayloadsTrimmed   = "payloads_trimmed"
+	degradedMessagesCollapsed = "messages_collapsed"
+	degradedSummarized        = "summarized"
+)
+
+var defaultMaxOutputTokens atomic.Int64
+
+// SetDefaultMaxOutputTokens sets the budget used when a call does not pass
+// maxOutputTokens
+func SetDefaultMaxOutputTokens(tokens int) {
+	defaultMaxOutputTokens.Store(int64(tokens))
+}
+
+// outputBudget converts a maxOutputTokens argument to bytes, falling back to
+// the server default
+func outputBudget(maxOutputTokens int) int {
+	if maxOutputTokens <= 0 {
+		maxOutputTokens = int(defaultMaxOutputTokens.Load())
+	}
+	if maxOutputTokens <= 0 {
+		maxOutputTokens = DefaultMaxOutputTokens
+	}
+	return maxOutputTokens * bytesPerToken
+}
+
+// FitOutput cuts any tool result down to the output budget. JSON results stay
+// valid JSON, with markers for what was dropped; other text is cut at the
+// budget. Results that fit are returned unchanged.
+func FitOutput(text string, maxOutputTokens int) string {
+	budget := outputBudget(maxOutputTokens)
+	if len(text) <= budget {
+		return text
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	var value interface{}
+	if err := decoder.Decode(&value); err == nil {
+		if data, err := json.Marshal(truncateValue(value, budget)); err == nil {
+			return string(data)
+		}
+	}
+	if cut, ok := truncateValue(text, budget).(string); ok {
+		return cut
+	}
+	return text
+}
+
+// collapsedEntry stands for the entries of a page sharing a severity and a
+// normalized message
+type collapsedEntry struct {
+	Severity string `json:"severity"`
+	Message  string `json:"message"`
+	Count    int    `json:"count"`
+	Newest   string `json:"newest"`
+	Oldest   string `json:"oldest"`
+}
+
+type entrySummary struct {
+	Count       int              `json:"count"`
+	Newest      string           `json:"newest"`
+	Oldest      string           `json:"oldest"`
+	Severities  map[string]int   `json:"severities"`
+	TopMessages []collapsedEntry `json:"topMessages"`
+}
+
+// fitEntries renders a page of entries, newest first, within budget bytes.
+// result holds the other fields of the tool's result, or is nil for tools
+// that return a bare list. Pages that do not fit degrade step by step:
+// payloads are cut to trimmedPayloadBytes, then repeated messages are
+// collapsed with counts, then the page is replaced by a summary and the
+// entries that still fit, with a pageToken to read on from there.
+func fitEntries(entries []LogEntry, view EntryView, budget int, result map[string]interface{}) ([]byte, error) {
+	render := func(fields map[string]interface{}) ([]byte, error) {
+		out := maps.Clone(result)
+		if out == nil {
+			out = make(map[string]interface{}, len(fields))
+		}
+		maps.Copy(out, fields)
+		return view.Marshal(out)
+	}
+
+	var data []byte
+	var err error
+	if result == nil {
+		data, err = view.Marshal(ApplyAll(view, entries))
+	} else {
+		data, err = render(map[string]interface{}{"entries": ApplyAll(view, entries)})
+	}
+	// An empty page has nothing left to degrade
+	if err != nil || len(data) <= budget || len(entries) == 0 {
+		return data, err
+	}
+
+	trimmed := view
+	if trimmed.MaxPayloadBytes <= 0 || trimmed.MaxPayloadBytes > trimmedPayloadBytes {
+		trimmed.MaxPayloadBytes = trimmedPayloadBytes
+	}
+	viewed := ApplyAll(trimmed, entries)
+	data, err = render(map[string]interface{}{
+		"degraded": degradedPayloadsTrimmed,
+		"entries":  viewed,
+	})
+	if err != nil || len(data) <= budget {
+		return data, err
+	}
+
+	collapsed := collapseEntries(entries)
+	data, err = render(map[string]interface{}{
+		"degraded": degradedMessagesCollapsed,
+		"messages": collapsed,
+	})
+	if err != nil || len(data) <= budget {
+		return data, err
+	}
+
+	summary := summarizeEntries(entries, collapsed)
+	fields := map[string]interface{}{
+		"degraded":      degradedSummarized,
+		"summary":       &summary,
+		"nextPageToken": newPageToken(entries[0], true),
+	}
+	for {
+		if data, err = render(fields); err != nil {
+			return nil, err
+		}
+		if len(data) <= budget || len(summary.TopMessages) <= 1 {
+			break
+		}
+		summary.TopMessages = summary.TopMessages[:len(summary.TopMessages)-1]
+	}
+	// Show as many leading entries as still fit, and continue after them
+	for n := 1; n <= len(viewed); n++ {
+		fields["entries"] = viewed[:n]
+		fields["nextPageToken"] = newPageToken(entries[n-1], false)
+		next, err := render(fields)
+		if err != nil {
+			return nil, err
+		}
+		if len(next) > budget {
+			break
+		}
+		data = next
+	}
+	return data, nil
+}
+
+// collapseEntries groups entries by severity and normalized message, in the
+// order each group first appears
+func collapseEntries(entries []LogEntry) []collapsedEntry {
+	var collapsed []collapsedEntry
+	index := make(map[string]int)
+	for _, entry := range entries {
+		message := entryMessage(entry)
+		key := entry.Severity + "\x00" + NormalizeMessage(message)
+		if i, ok := index[key]; ok {
+			collapsed[i].Count++
+			collapsed[i].Oldest = entry.Timestamp
+			continue
+		}
+		index[key] = len(collapsed)
+		if cut, ok := truncateValue(message, maxSummaryMessageBytes).(string); ok {
+			message = cut
+		}
+		collapsed = append(collapsed, collapsedEntry{
+			Severity: entry.Severity,
+			Message:  message,
+			Count:    1,
+			Newest:   entry.Timestamp,
+			Oldest:   entry.Timestamp,
+		})
+	}
+	return collapsed
+}
+
+func summarizeEntries(entries []LogEntry, collapsed []collapsedEntry) entrySummary {
+	summary := entrySummary{
+		Count:      len(entries),
+		Newest:     entries[0].Timestamp,
+		Oldest:     entries[len(entries)-1].Timestamp,
+		Severities: make(map[string]int),
+	}
+	for _, entry := range entries {
+		summary.Severities[entry.Severity]++
+	}
+
+	top := append([]collapsedEntry(nil), collapsed...)
+	sort.SliceStable(top, func(i, j int) bool {
+		return top[i].Count > top[j].Count
+	})
+	if len(top) > maxSummaryMessages {
+		top = top[:maxSummaryMessages]
+	}
+	summary.TopMessages = top
+	return summary
+}
+
+// entryMessage returns the text an entry is summarized by: its message, or
+// the method of an audit log or the request of a request log
+func entryMessage(entry LogEntry) string {
+	if message := ExtractServiceInfoFromLogEntry(entry).Message; message != "" {
+		return message
+	}
+	if audit := entry.ProtoPayload; audit != nil {
+		return audit.ServiceName + " " + audit.MethodName
+	}
+	if req := entry.HTTPRequest; req != nil {
+		return fmt.Sprintf("%s %s %d", req.RequestMethod, req.RequestURL, req.Status)
+	}
+	return entry.LogName
+}
@@ -0,0 +1,56 @@
+package logging
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestFitEntriesEmptyPage(t *testing.T) {
+	view := NewEntryView("", 0, false)
+
+	data, err := fitEntries(nil, view, outputBudget(1), map[string]interface{}{"query": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["query"] != "x" || got["degraded"] != nil {
+		t.Errorf("empty page = %s, want it rendered as is", data)
+	}
+
+	if data, err := fitEntries([]LogEntry{}, view, outputBudget(1), nil); err != nil || string(data) != "[]" {
+		t.Errorf("empty bare list = %s, %v", data, err)
+	}
+}
+
+func TestFitEntriesSummarizes(t *testing.T) {
+	entries := make([]LogEntry, 50)
+	for i := range entries {
+		entries[i] = LogEntry{
+			Timestamp:   fmt.Sprintf("2024-01-01T10:%02d:00Z", 59-i),
+			Severity:    "ERROR",
+			TextPayload: strings.Repeat(string(rune('a'+i%26))+string(rune('a'+i/26)), 100) + " failed",
+			InsertID:    fmt.Sprintf("id-%d", i),
+		}
+	}
+
+	budget := outputBudget(500)
+	data, err := fitEntries(entries, NewEntryView("", 0, false), budget, map[string]interface{}{"query": "x"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) > budget {
+		t.Errorf("result has %d bytes, budget is %d", len(data), budget)
+	}
+	var got map[string]interface{}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got["degraded"] != degradedSummarized || got["nextPageToken"] == nil {
+		t.Errorf("degraded = %v, nextPageToken = %v, want a summary with a page token", got["degraded"], got["nextPageToken"])
+	}
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"cloud.google.com/go/logging"
+)
+
+// pageCursor marks where a page of entries stopped. It is handed to clients
+// as an opaque pageToken.
+type pageCursor struct {
+	Timestamp string `json:"t"`
+	InsertID  string `json:"i"`
+	// Set when the entry itself has not been returned yet
+	Inclusive bool `json:"n,omitempty"`
+	// Set when entries are read oldest first
+	OldestFirst bool `json:"o,omitempty"`
+
+	time   time.Time
+	passed bool
+}
+
+// newPageToken returns a token that continues after the given entry, or at it
+// when inclusive is set
+func newPageToken(entry LogEntry, inclusive bool) string {
+	return pageCursor{Timestamp: entry.Timestamp, InsertID: entry.InsertID, Inclusive: inclusive}.Token()
+}
+
+// Token encodes the cursor as a pageToken
+func (c pageCursor) Token() string {
+	data, err := json.Marshal(c)
+	if err != nil {
+		return ""
+	}
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// parsePageToken decodes a pageToken argument. An empty token yields a nil
+// cursor, which reads from the newest entry.
+func parsePageToken(token string) (*pageCursor, error) {
+	if token == "" {
+		return nil, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(token)
+	if err != nil {
+		return nil, fmt.Errorf("invalid pageToken")
+	}
+	var cursor pageCursor
+	if err := json.Unmarshal(data, &cursor); err != nil {
+		return nil, fmt.Errorf("invalid pageToken")
+	}
+	if cursor.time, err = time.Parse(time.RFC3339Nano, cursor.Timestamp); err != nil {
+		return nil, fmt.Errorf("invalid pageToken")
+	}
+	return &cursor, nil
+}
+
+// Filter restricts a query to entries at or past the cursor in read order
+func (c *pageCursor) Filter() string {
+	if c == nil {
+		return ""
+	}
+	op := "<="
+	if c.OldestFirst {
+		op = ">="
+	}
+	return fmt.Sprintf(`timestamp%s"%s"`, op, c.time.UTC().Format(time.RFC3339Nano))
+}
+
+// Skip reports whether an entry was already returned before the cursor.
+// Entries sharing a timestamp come back in a stable order, so those up to the
+// cursor entry are skipped.
+func (c *pageCursor) Skip(entry *logging.Entry) bool {
+	if c == nil || c.passed {
+		return false
+	}
+	if !entry.Timestamp.Equal(c.time) {
+		c.passed = true
+		return false
+	}
+	if entry.InsertID == c.InsertID {
+		c.passed = true
+		return !c.Inclusive
+	}
+	return true
+}
@@ -0,0 +1,227 @@
+package logging
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+)
+
+const (
+	patternWildcard = "<*>"
+	// Leading tokens after the length level of the parse tree used to pick a leaf;
+	// kept shallow so that messages varying from the second word on still merge
+	patternTreeDepth = 1
+	// Children per tree node before new tokens fall back to the wildcard child
+	patternMaxChildren = 100
+	// Tokens beyond this are ignored; very long lines are rarely templated usefully
+	patternMaxTokens  = 64
+	patternMaxSamples = 5
+	// DefaultPatternSimilarity is the fraction of matching tokens required to
+	// add a message to an existing template
+	DefaultPatternSimilarity = 0.5
+)
+
+// LogPattern is a template mined from log messages. Variable positions are
+// shown as <*> and sample values are kept for each of them.
+type LogPattern struct {
+	ID       int           `json:"id"`
+	Template string        `json:"template"`
+	Count    int           `json:"count"`
+	Slots    []PatternSlot `json:"slots,omitempty"`
+}
+
+type PatternSlot struct {
+	Position int      `json:"position"`
+	Samples  []string `json:"samples"`
+}
+
+type patternCluster struct {
+	id      int
+	tokens  []string
+	count   int
+	samples map[int][]string
+}
+
+type patternNode struct {
+	children map[string]*patternNode
+	clusters []*patternCluster
+}
+
+// PatternMiner groups log messages into templates online, following the
+// Drain algorithm: messages are routed through a fixed-depth tree keyed by
+// token count and leading tokens, then matched against the templates in the
+// leaf by token similarity.
+type PatternMiner struct {
+	root       *patternNode
+	clusters   []*patternCluster
+	similarity float64
+}
+
+func NewPatternMiner(similarity float64) *PatternMiner {
+	if similarity <= 0 || similarity > 1 {
+		similarity = DefaultPatternSimilarity
+	}
+	return &PatternMiner{
+		root:       newPatternNode(),
+		similarity: similarity,
+	}
+}
+
+func newPatternNode() *patternNode {
+	return &patternNode{children: make(map[string]*patternNode)}
+}
+
+// Add mines a message and returns the ID of the pattern it was assigned to.
+// IDs are stable even though the template may generalize later.
+func (m *PatternMiner) Add(message string) int {
+	values := patternTokens(message)
+	tokens := make([]string, len(values))
+	for i, value := range values {
+		if isVariableToken(value) {
+			tokens[i] = patternWildcard
+		} else {
+			tokens[i] = value
+		}
+	}
+
+	leaf := m.leaf(tokens)
+	cluster := m.match(leaf.clusters, tokens)
+	if cluster == nil {
+		cluster = &patternCluster{
+			id:      len(m.clusters) + 1,
+			tokens:  tokens,
+			samples: make(map[int][]string),
+		}
+		leaf.clusters = append(leaf.clusters, cluster)
+		m.clusters = append(m.clusters, cluster)
+	} else {
+		for i, token := range cluster.tokens {
+			if token != patternWildcard && token != tokens[i] {
+				// Keep the value the template had so far as a sample as well
+				cluster.addSample(i, token)
+				cluster.tokens[i] = patternWildcard
+			}
+		}
+	}
+
+	cluster.count++
+	for i, token := range cluster.tokens {
+		if token == patternWildcard {
+			cluster.addSample(i, values[i])
+		}
+	}
+	return cluster.id
+}
+
+func (m *PatternMiner) leaf(tokens []string) *patternNode {
+	node := m.root.child(strconv.Itoa(len(tokens)))
+	for i := 0; i < patternTreeDepth && i < len(tokens); i++ {
+		key := tokens[i]
+		if _, exists := node.children[key]; !exists && len(node.children) >= patternMaxChildren {
+			key = patternWildcard
+		}
+		node = node.child(key)
+	}
+	return node
+}
+
+func (n *patternNode) child(key string) *patternNode {
+	child, exists := n.children[key]
+	if !exists {
+		child = newPatternNode()
+		n.children[key] = child
+	}
+	return child
+}
+
+// match returns the most similar template, preferring the one with fewer
+// wildcards on ties, or nil when none reaches the similarity threshold
+func (m *PatternMiner) match(clusters []*patternCluster, tokens []string) *patternCluster {
+	var best *patternCluster
+	bestSimilarity, bestWildcards := -1.0, 0
+	for _, cluster := range clusters {
+		same, wildcards := 0, 0
+		for i, token := range cluster.tokens {
+			switch {
+			case token == patternWildcard:
+				wildcards++
+			case token == tokens[i]:
+				same++
+			}
+		}
+		similarity := 1.0
+		if len(tokens) > 0 {
+			similarity = float64(same) / float64(len(tokens))
+		}
+		if similarity > bestSimilarity || (similarity == bestSimilarity && wildcards < bestWildcards) {
+			best, bestSimilarity, bestWildcards = cluster, similarity, wildcards
+		}
+	}
+	if best == nil {
+		return nil
+	}
+	// A template made only of wildcards matches anything of the same length
+	if bestSimilarity < m.similarity && bestWildcards < len(tokens) {
+		return nil
+	}
+	return best
+}
+
+func (c *patternCluster) addSample(position int, value string) {
+	samples := c.samples[position]
+	if len(samples) >= patternMaxSamples {
+		return
+	}
+	for _, sample := range samples {
+		if sample == value {
+			return
+		}
+	}
+	c.samples[position] = append(samples, value)
+}
+
+// Patterns returns all mined patterns ordered by count, most frequent first
+func (m *PatternMiner) Patterns() []LogPattern {
+	patterns := make([]LogPattern, 0, len(m.clusters))
+	for _, cluster := range m.clusters {
+		pattern := LogPattern{
+			ID:       cluster.id,
+			Template: strings.Join(cluster.tokens, " "),
+			Count:    cluster.count,
+		}
+		for i, token := range cluster.tokens {
+			if token == patternWildcard {
+				pattern.Slots = append(pattern.Slots, PatternSlot{Position: i, Samples: cluster.samples[i]})
+			}
+		}
+		patterns = append(patterns, pattern)
+	}
+	sort.SliceStable(patterns, func(i, j int) bool {
+		return patterns[i].Count > patterns[j].Count
+	})
+	return patterns
+}
+
+// patternTokens splits the first line of a message into whitespace separated tokens
+func patternTokens(message string) []string {
+	if i := strings.IndexByte(message, '\n'); i >= 0 {
+		message = message[:i]
+	}
+	tokens := strings.Fields(message)
+	if len(tokens) > patternMaxTokens {
+		tokens = tokens[:patternMaxTokens]
+	}
+	return tokens
+}
+
+// isVariableToken masks tokens that are almost always parameters, such as
+// numbers, IDs, addresses and timestamps, before they are compared
+func isVariableToken(token string) bool {
+	for _, r := range token {
+		if unicode.IsDigit(r) || r == '@' {
+			return true
+		}
+	}
+	return false
+}
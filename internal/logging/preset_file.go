@@ -0,0 +1,318 @@
+package logging
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Maximum page size a preset may request (quota optimization)
+const maxPresetPageSize = 20
+
+var (
+	presetFields    = []string{"name", "description", "filter", "parameters", "window", "pageSize"}
+	parameterFields = []string{"name", "type", "description", "default", "required"}
+	presetRegistry  = &PresetRegistry{}
+)
+
+// PresetRegistry merges the built-in presets with presets defined in a
+// YAML or JSON file. The file is reloaded whenever it changes; if a reload
+// fails, the previously loaded presets stay in effect.
+type PresetRegistry struct {
+	mu          sync.RWMutex
+	path        string
+	modTime     time.Time
+	size        int64
+	filePresets map[string]PresetQuery
+	loadErr     error
+}
+
+// LoadPresetFile configures the file that user-defined presets are read from.
+// Presets in the file take precedence over built-in presets with the same name.
+func LoadPresetFile(path string) error {
+	return presetRegistry.load(path)
+}
+
+// PresetFileError returns the error from the most recent failed reload, if any
+func PresetFileError() error {
+	presetRegistry.mu.RLock()
+	defer presetRegistry.mu.RUnlock()
+	return presetRegistry.loadErr
+}
+
+func (r *PresetRegistry) Get(name string) (PresetQuery, bool) {
+	r.reloadIfChanged()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	if preset, ok := r.filePresets[name]; ok {
+		return preset, true
+	}
+	preset, ok := CommonPresetQueries[name]
+	return preset, ok
+}
+
+func (r *PresetRegistry) List() []PresetQuery {
+	r.reloadIfChanged()
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	merged := make(map[string]PresetQuery, len(CommonPresetQueries)+len(r.filePresets))
+	for name, preset := range CommonPresetQueries {
+		merged[name] = preset
+	}
+	for name, preset := range r.filePresets {
+		merged[name] = preset
+	}
+	return sortedPresets(merged)
+}
+
+func (r *PresetRegistry) load(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return fmt.Errorf("failed to read preset file: %w", err)
+	}
+	presets, err := parsePresetFile(path)
+	if err != nil {
+		return err
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.path = path
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	r.filePresets = presets
+	r.loadErr = nil
+
+	log.Printf("Loaded %d preset queries from %s", len(presets), path)
+	return nil
+}
+
+func (r *PresetRegistry) reloadIfChanged() {
+	r.mu.RLock()
+	path, modTime, size := r.path, r.modTime, r.size
+	r.mu.RUnlock()
+
+	if path == "" {
+		return
+	}
+	info, err := os.Stat(path)
+	if err != nil || (info.ModTime().Equal(modTime) && info.Size() == size) {
+		return
+	}
+
+	presets, err := parsePresetFile(path)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Remember the attempted version so a broken file is not re-parsed on every call
+	r.modTime = info.ModTime()
+	r.size = info.Size()
+	if err != nil {
+		log.Printf("Failed to reload preset file, keeping previous presets: %v", err)
+		r.loadErr = err
+		return
+	}
+	r.filePresets = presets
+	r.loadErr = nil
+	log.Printf("Reloaded %d preset queries from %s", len(presets), path)
+}
+
+// parsePresetFile decodes a preset file and validates every preset. All
+// validation errors are reported together, each prefixed with path:line.
+func parsePresetFile(path string) (map[string]PresetQuery, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read preset file: %w", err)
+	}
+
+	// JSON is a subset of YAML, so both formats share the same parser
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	v := &presetValidator{path: path}
+	presets := make(map[string]PresetQuery)
+
+	if len(root.Content) == 0 {
+		return presets, nil
+	}
+	doc := root.Content[0]
+	if doc.Kind != yaml.MappingNode {
+		v.errorf(doc, "expected a mapping with a \"presets\" list")
+		return nil, v.err()
+	}
+
+	list := mappingValue(doc, "presets")
+	if list == nil {
+		v.errorf(doc, "missing \"presets\" list")
+		return nil, v.err()
+	}
+	if list.Kind != yaml.SequenceNode {
+		v.errorf(list, "\"presets\" must be a list")
+		return nil, v.err()
+	}
+
+	for _, item := range list.Content {
+		preset, ok := v.decodePreset(item)
+		if !ok {
+			continue
+		}
+		if _, exists := presets[preset.Name]; exists {
+			v.errorf(mappingValue(item, "name"), "duplicate preset name %q", preset.Name)
+			continue
+		}
+		presets[preset.Name] = preset
+	}
+
+	if err := v.err(); err != nil {
+		return nil, err
+	}
+	return presets, nil
+}
+
+type presetValidator struct {
+	path   string
+	errors []string
+}
+
+func (v *presetValidator) errorf(node *yaml.Node, format string, args ...interface{}) {
+	line := 0
+	if node != nil {
+		line = node.Line
+	}
+	v.errors = append(v.errors, fmt.Sprintf("%s:%d: %s", v.path, line, fmt.Sprintf(format, args...)))
+}
+
+func (v *presetValidator) err() error {
+	if len(v.errors) == 0 {
+		return nil
+	}
+	return errors.New("invalid preset file:\n" + strings.Join(v.errors, "\n"))
+}
+
+func (v *presetValidator) decodePreset(node *yaml.Node) (PresetQuery, bool) {
+	var preset PresetQuery
+	if node.Kind != yaml.MappingNode {
+		v.errorf(node, "preset must be a mapping")
+		return preset, false
+	}
+	before := len(v.errors)
+	v.checkFields(node, presetFields)
+
+	if err := node.Decode(&preset); err != nil {
+		v.errorf(node, "%v", err)
+		return preset, false
+	}
+
+	nameNode := mappingValue(node, "name")
+	if preset.Name == "" {
+		v.errorf(node, "preset name is required")
+	} else if !presetNamePattern.MatchString(preset.Name) {
+		v.errorf(nameNode, "preset name %q must be lower snake_case", preset.Name)
+	}
+	if preset.Description == "" {
+		v.errorf(node, "preset %q: description is required", preset.Name)
+	}
+
+	if preset.Window == "" {
+		v.errorf(node, "preset %q: window is required", preset.Name)
+	} else if window, err := time.ParseDuration(preset.Window); err != nil || window <= 0 {
+		v.errorf(mappingValue(node, "window"), "preset %q: window %q is not a positive duration", preset.Name, preset.Window)
+	}
+
+	if preset.PageSize == 0 {
+		preset.PageSize = 10
+	} else if preset.PageSize < 0 || preset.PageSize > maxPresetPageSize {
+		v.errorf(mappingValue(node, "pageSize"), "preset %q: pageSize must be between 1 and %d", preset.Name, maxPresetPageSize)
+	}
+
+	declared := make(map[string]bool, len(preset.Parameters))
+	if paramsNode := mappingValue(node, "parameters"); paramsNode != nil && paramsNode.Kind == yaml.SequenceNode {
+		for i, paramNode := range paramsNode.Content {
+			v.checkFields(paramNode, parameterFields)
+			if i >= len(preset.Parameters) {
+				break
+			}
+			param := &preset.Parameters[i]
+			if param.Type == "" {
+				param.Type = PresetParamString
+			}
+			v.validateParameter(paramNode, preset.Name, *param)
+			if declared[param.Name] {
+				v.errorf(paramNode, "preset %q: duplicate parameter %q", preset.Name, param.Name)
+			}
+			declared[param.Name] = true
+		}
+	}
+
+	for _, placeholder := range preset.Placeholders() {
+		if !declared[placeholder] {
+			v.errorf(mappingValue(node, "filter"), "preset %q: filter references undeclared parameter ${%s}", preset.Name, placeholder)
+		}
+	}
+
+	return preset, len(v.errors) == before
+}
+
+func (v *presetValidator) validateParameter(node *yaml.Node, presetName string, param PresetParameter) {
+	if !presetNamePattern.MatchString(param.Name) {
+		v.errorf(node, "preset %q: parameter name %q must be lower snake_case", presetName, param.Name)
+	}
+	if param.Name == PresetWindowParameter {
+		v.errorf(mappingValue(node, "name"), "preset %q: parameter name %q is reserved", presetName, param.Name)
+	}
+	switch param.Type {
+	case PresetParamString, PresetParamInteger, PresetParamDuration, PresetParamSeverity:
+	default:
+		v.errorf(mappingValue(node, "type"), "preset %q: parameter %q has unknown type %q", presetName, param.Name, param.Type)
+		return
+	}
+	if param.Default != "" {
+		if _, err := param.normalize(param.Default); err != nil {
+			v.errorf(mappingValue(node, "default"), "preset %q: invalid default for %q: %v", presetName, param.Name, err)
+		}
+	}
+}
+
+func (v *presetValidator) checkFields(node *yaml.Node, allowed []string) {
+	if node.Kind != yaml.MappingNode {
+		return
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		key := node.Content[i]
+		known := false
+		for _, field := range allowed {
+			if key.Value == field {
+				known = true
+				break
+			}
+		}
+		if !known {
+			v.errorf(key, "unknown field %q", key.Value)
+		}
+	}
+}
+
+// mappingValue returns the value node for key, or nil if it is absent
+func mappingValue(node *yaml.Node, key string) *yaml.Node {
+	if node == nil || node.Kind != yaml.MappingNode {
+		return nil
+	}
+	for i := 0; i+1 < len(node.Content); i += 2 {
+		if node.Content[i].Value == key {
+			return node.Content[i+1]
+		}
+	}
+	return nil
+}
@@ -2,83 +2,394 @@ package logging
 
 import (
 	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+// PresetWindowParameter is accepted by every preset and overrides its window
+const PresetWindowParameter = "window"
+
+// Parameter types supported in preset filter templates
+const (
+	PresetParamString   = "string"
+	PresetParamInteger  = "integer"
+	PresetParamDuration = "duration"
+	PresetParamSeverity = "severity"
+
+	// Used internally when previewing a template without values
+	presetParamPlaceholder = "placeholder"
 )
 
+var (
+	presetPlaceholderPattern = regexp.MustCompile(`\$\{([a-zA-Z_][a-zA-Z0-9_]*)\}`)
+	presetNamePattern        = regexp.MustCompile(`^[a-z][a-z0-9_]*$`)
+	logSeverities            = []string{"DEFAULT", "DEBUG", "INFO", "NOTICE", "WARNING", "ERROR", "CRITICAL", "ALERT", "EMERGENCY"}
+)
+
+type PresetParameter struct {
+	Name        string `json:"name" yaml:"name"`
+	Type        string `json:"type" yaml:"type"`
+	Description string `json:"description,omitempty" yaml:"description"`
+	Default     string `json:"default,omitempty" yaml:"default"`
+	Required    bool   `json:"required,omitempty" yaml:"required"`
+}
+
+// PresetQuery is a filter template. Placeholders of the form ${name} are
+// replaced with parameter values, and a timestamp bound covering Window is
+// appended when the query is expanded.
 type PresetQuery struct {
-	Name        string
-	Description string
-	Filter      string
-	PageSize    int
+	Name        string            `json:"name" yaml:"name"`
+	Description string            `json:"description" yaml:"description"`
+	Filter      string            `json:"filter" yaml:"filter"`
+	Parameters  []PresetParameter `json:"parameters,omitempty" yaml:"parameters"`
+	Window      string            `json:"window" yaml:"window"`
+	PageSize    int               `json:"pageSize" yaml:"pageSize"`
 }
 
 var CommonPresetQueries = map[string]PresetQuery{
 	"cloud_run_errors": {
 		Name:        "cloud_run_errors",
 		Description: "Get recent errors from Cloud Run services",
-		Filter:      `resource.type="cloud_run_revision" AND severity>=ERROR AND timestamp>="%s"`,
+		Filter:      `resource.type="cloud_run_revision" AND severity>=ERROR`,
+		Window:      "1h",
 		PageSize:    10,
 	},
 	"cloud_run_service_errors": {
 		Name:        "cloud_run_service_errors",
 		Description: "Get errors for specific Cloud Run service",
-		Filter:      `resource.type="cloud_run_revision" AND resource.labels.service_name="%s" AND severity>=ERROR AND timestamp>="%s"`,
-		PageSize:    15,
+		Filter:      `resource.type="cloud_run_revision" AND resource.labels.service_name="${service}" AND severity>=ERROR`,
+		Parameters: []PresetParameter{
+			{Name: "service", Type: PresetParamString, Description: "Cloud Run service name", Required: true},
+		},
+		Window:   "2h",
+		PageSize: 15,
 	},
 	"recent_logs": {
 		Name:        "recent_logs",
 		Description: "Get recent logs from last hour",
-		Filter:      `timestamp>="%s"`,
+		Window:      "1h",
 		PageSize:    20,
 	},
 	"high_severity": {
 		Name:        "high_severity",
 		Description: "Get critical and error logs from last 6 hours",
-		Filter:      `severity>=ERROR AND timestamp>="%s"`,
+		Filter:      `severity>=ERROR`,
+		Window:      "6h",
 		PageSize:    10,
 	},
+	"gke_pod_errors": {
+		Name:        "gke_pod_errors",
+		Description: "Get errors from GKE containers in a namespace, optionally narrowed to pods whose name contains the given value",
+		Filter:      `resource.type="k8s_container" AND resource.labels.namespace_name="${namespace}" AND resource.labels.pod_name:"${pod}" AND severity>=ERROR`,
+		Parameters: []PresetParameter{
+			{Name: "namespace", Type: PresetParamString, Description: "Kubernetes namespace", Required: true},
+			{Name: "pod", Type: PresetParamString, Description: "Pod name or name prefix"},
+		},
+		Window:   "1h",
+		PageSize: 15,
+	},
+	"lb_5xx": {
+		Name:        "lb_5xx",
+		Description: "Get 5xx responses served by external HTTP(S) load balancers, optionally for one backend service",
+		Filter:      `resource.type="http_load_balancer" AND httpRequest.status>=500 AND resource.labels.backend_service_name="${backend}"`,
+		Parameters: []PresetParameter{
+			{Name: "backend", Type: PresetParamString, Description: "Backend service name"},
+		},
+		Window:   "1h",
+		PageSize: 20,
+	},
+	"function_errors": {
+		Name:        "function_errors",
+		Description: "Get errors from Cloud Functions, optionally for one function",
+		Filter:      `resource.type="cloud_function" AND resource.labels.function_name="${name}" AND severity>=ERROR`,
+		Parameters: []PresetParameter{
+			{Name: "name", Type: PresetParamString, Description: "Cloud Function name"},
+		},
+		Window:   "1h",
+		PageSize: 15,
+	},
+	"app_engine_errors": {
+		Name:        "app_engine_errors",
+		Description: "Get errors from App Engine, optionally for one service (module)",
+		Filter:      `resource.type="gae_app" AND resource.labels.module_id="${service}" AND severity>=ERROR`,
+		Parameters: []PresetParameter{
+			{Name: "service", Type: PresetParamString, Description: "App Engine service (module) id"},
+		},
+		Window:   "1h",
+		PageSize: 15,
+	},
+	"cloud_sql_errors": {
+		Name:        "cloud_sql_errors",
+		Description: "Get errors from Cloud SQL instances, optionally for one instance",
+		Filter:      `resource.type="cloudsql_database" AND resource.labels.database_id:"${instance}" AND severity>=ERROR`,
+		Parameters: []PresetParameter{
+			{Name: "instance", Type: PresetParamString, Description: "Cloud SQL instance name"},
+		},
+		Window:   "6h",
+		PageSize: 15,
+	},
+	"iam_policy_changes": {
+		Name:        "iam_policy_changes",
+		Description: "Get IAM policy changes from Admin Activity audit logs, optionally by one principal",
+		Filter:      `logName:"cloudaudit.googleapis.com%2Factivity" AND protoPayload.methodName:"SetIamPolicy" AND protoPayload.authenticationInfo.principalEmail="${principal}"`,
+		Parameters: []PresetParameter{
+			{Name: "principal", Type: PresetParamString, Description: "Email of the user or service account that made the change"},
+		},
+		Window:   "24h",
+		PageSize: 20,
+	},
+	"oom_killed": {
+		Name:        "oom_killed",
+		Description: "Get out-of-memory kills from GKE nodes and containers and Cloud Run memory limit errors",
+		Filter:      `(jsonPayload.reason="OOMKilling" OR jsonPayload.message:"OOMKilled" OR textPayload:"Memory limit of") AND resource.labels.namespace_name="${namespace}"`,
+		Parameters: []PresetParameter{
+			{Name: "namespace", Type: PresetParamString, Description: "Kubernetes namespace"},
+		},
+		Window:   "6h",
+		PageSize: 20,
+	},
+	"container_restarts": {
+		Name:        "container_restarts",
+		Description: "Get Kubernetes events for containers that are crash looping or being restarted",
+		Filter:      `resource.type="k8s_pod" AND log_id("events") AND jsonPayload.reason=("BackOff" OR "Killing" OR "Unhealthy") AND resource.labels.namespace_name="${namespace}"`,
+		Parameters: []PresetParameter{
+			{Name: "namespace", Type: PresetParamString, Description: "Kubernetes namespace"},
+		},
+		Window:   "6h",
+		PageSize: 20,
+	},
 }
 
-func GetPresetQuery(queryName string, params ...string) (string, int, error) {
-	preset, exists := CommonPresetQueries[queryName]
+// GetPresetQuery expands a preset with named parameter values. The reserved
+// "window" parameter overrides the preset's relative time window.
+func GetPresetQuery(queryName string, params map[string]string) (string, int, error) {
+	preset, exists := presetRegistry.Get(queryName)
 	if !exists {
 		return "", 0, fmt.Errorf("preset query '%s' not found", queryName)
 	}
 
-	switch queryName {
-	case "cloud_run_errors":
-		defaultTime := time.Now().Add(-1 * time.Hour).Format(time.RFC3339)
-		filter := fmt.Sprintf(preset.Filter, defaultTime)
-		return filter, preset.PageSize, nil
+	for name := range params {
+		if name != PresetWindowParameter && !preset.hasParameter(name) {
+			return "", 0, fmt.Errorf("unknown parameter '%s' for preset query '%s' (accepted: %s)", name, queryName, strings.Join(preset.parameterNames(), ", "))
+		}
+	}
+
+	if window, ok := params[PresetWindowParameter]; ok && window != "" {
+		if d, err := time.ParseDuration(window); err != nil || d <= 0 {
+			return "", 0, fmt.Errorf("invalid window parameter for %s: %q is not a positive duration", queryName, window)
+		}
+		preset.Window = window
+	}
+
+	filter, err := preset.Expand(params, time.Now())
+	if err != nil {
+		return "", 0, err
+	}
+	return filter, preset.PageSize, nil
+}
+
+func ListPresetQueries() []PresetQuery {
+	return presetRegistry.List()
+}
 
-	case "cloud_run_service_errors":
-		if len(params) < 1 {
-			return "", 0, fmt.Errorf("service name parameter required for cloud_run_service_errors")
+// Expand substitutes parameter values into the filter template and appends
+// the relative time window ending at now. Top-level AND clauses that reference
+// an optional parameter without a value are omitted.
+func (p PresetQuery) Expand(values map[string]string, now time.Time) (string, error) {
+	resolved := make(map[string]string, len(p.Parameters))
+	omitted := make(map[string]bool)
+	for _, param := range p.Parameters {
+		value, ok := values[param.Name]
+		if !ok || value == "" {
+			if param.Required && param.Default == "" {
+				return "", fmt.Errorf("%s parameter required for %s", param.Name, p.Name)
+			}
+			value = param.Default
+		}
+		if value == "" {
+			omitted[param.Name] = true
+			continue
+		}
+		normalized, err := param.normalize(value)
+		if err != nil {
+			return "", fmt.Errorf("invalid %s parameter for %s: %w", param.Name, p.Name, err)
 		}
-		serviceName := params[0]
-		defaultTime := time.Now().Add(-2 * time.Hour).Format(time.RFC3339)
-		filter := fmt.Sprintf(preset.Filter, serviceName, defaultTime)
-		return filter, preset.PageSize, nil
+		resolved[param.Name] = normalized
+	}
 
-	case "recent_logs":
-		defaultTime := time.Now().Add(-1 * time.Hour).Format(time.RFC3339)
-		filter := fmt.Sprintf(preset.Filter, defaultTime)
-		return filter, preset.PageSize, nil
+	template := p.Filter
+	if len(omitted) > 0 {
+		template = omitClauses(template, omitted)
+	}
 
-	case "high_severity":
-		defaultTime := time.Now().Add(-6 * time.Hour).Format(time.RFC3339)
-		filter := fmt.Sprintf(preset.Filter, defaultTime)
-		return filter, preset.PageSize, nil
+	filter := presetPlaceholderPattern.ReplaceAllStringFunc(template, func(placeholder string) string {
+		name := presetPlaceholderPattern.FindStringSubmatch(placeholder)[1]
+		return resolved[name]
+	})
+
+	window, err := time.ParseDuration(p.Window)
+	if err != nil || window <= 0 {
+		return filter, nil
+	}
+	timeFilter := fmt.Sprintf(`timestamp>="%s"`, now.Add(-window).UTC().Format(time.RFC3339))
+	if strings.TrimSpace(filter) == "" {
+		return timeFilter, nil
+	}
+	return filter + " AND " + timeFilter, nil
+}
 
+// Preview expands the filter with default values, leaving placeholders for
+// parameters that have no default
+func (p PresetQuery) Preview(now time.Time) string {
+	values := make(map[string]string, len(p.Parameters))
+	for _, param := range p.Parameters {
+		if param.Default == "" {
+			values[param.Name] = "${" + param.Name + "}"
+		}
+	}
+	preview := p
+	preview.Parameters = append([]PresetParameter(nil), p.Parameters...)
+	for i := range preview.Parameters {
+		// Placeholders must pass through unescaped and unvalidated
+		preview.Parameters[i].Type = presetParamPlaceholder
+	}
+	filter, err := preview.Expand(values, now)
+	if err != nil {
+		return p.Filter
+	}
+	return filter
+}
+
+// Schema describes the preset's parameters as a JSON schema
+func (p PresetQuery) Schema() types.Schema {
+	schema := types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			PresetWindowParameter: {
+				Type:        "string",
+				Description: "Relative time window ending now (Go duration, e.g. 2h)",
+				Default:     p.Window,
+			},
+		},
+		AdditionalProperties: false,
+	}
+	for _, param := range p.Parameters {
+		prop := types.Schema{
+			Type:        "string",
+			Description: param.Description,
+		}
+		if param.Default != "" {
+			prop.Default = param.Default
+		}
+		switch param.Type {
+		case PresetParamInteger:
+			prop.Pattern = `^-?[0-9]+$`
+		case PresetParamDuration:
+			prop.Format = "duration"
+		case PresetParamSeverity:
+			prop.Enum = logSeverities
+		}
+		schema.Properties[param.Name] = prop
+		if param.Required && param.Default == "" {
+			schema.Required = append(schema.Required, param.Name)
+		}
+	}
+	return schema
+}
+
+func (p PresetQuery) hasParameter(name string) bool {
+	for _, param := range p.Parameters {
+		if param.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func (p PresetQuery) parameterNames() []string {
+	names := []string{PresetWindowParameter}
+	for _, param := range p.Parameters {
+		names = append(names, param.Name)
+	}
+	return names
+}
+
+// omitClauses removes top-level AND clauses that reference any of the given parameters
+func omitClauses(filter string, params map[string]bool) string {
+	var kept []string
+	for _, clause := range splitTopLevelAnd(filter) {
+		clause = strings.TrimSpace(clause)
+		if clause == "" {
+			continue
+		}
+		drop := false
+		for _, match := range presetPlaceholderPattern.FindAllStringSubmatch(clause, -1) {
+			if params[match[1]] {
+				drop = true
+				break
+			}
+		}
+		if !drop {
+			kept = append(kept, clause)
+		}
+	}
+	return strings.Join(kept, " AND ")
+}
+
+// Placeholders returns the parameter names referenced by the filter template
+func (p PresetQuery) Placeholders() []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, match := range presetPlaceholderPattern.FindAllStringSubmatch(p.Filter, -1) {
+		if !seen[match[1]] {
+			seen[match[1]] = true
+			names = append(names, match[1])
+		}
+	}
+	return names
+}
+
+// normalize validates a value against the parameter type and escapes it for
+// use inside a quoted filter string
+func (pp PresetParameter) normalize(value string) (string, error) {
+	switch pp.Type {
+	case PresetParamInteger:
+		if _, err := strconv.Atoi(value); err != nil {
+			return "", fmt.Errorf("%q is not an integer", value)
+		}
+		return value, nil
+	case PresetParamDuration:
+		if _, err := time.ParseDuration(value); err != nil {
+			return "", fmt.Errorf("%q is not a duration", value)
+		}
+		return value, nil
+	case PresetParamSeverity:
+		upper := strings.ToUpper(value)
+		for _, severity := range logSeverities {
+			if upper == severity {
+				return upper, nil
+			}
+		}
+		return "", fmt.Errorf("%q is not a log severity", value)
+	case presetParamPlaceholder:
+		return value, nil
 	default:
-		return preset.Filter, preset.PageSize, nil
+		return EscapeFilterValue(value), nil
 	}
 }
 
-func ListPresetQueries() []PresetQuery {
-	queries := make([]PresetQuery, 0, len(CommonPresetQueries))
-	for _, query := range CommonPresetQueries {
+func sortedPresets(presets map[string]PresetQuery) []PresetQuery {
+	queries := make([]PresetQuery, 0, len(presets))
+	for _, query := range presets {
 		queries = append(queries, query)
 	}
+	sort.Slice(queries, func(i, j int) bool {
+		return queries[i].Name < queries[j].Name
+	})
 	return queries
 }
@@ -0,0 +1,117 @@
+package logging
+
+import (
+	"testing"
+	"time"
+)
+
+func TestPresetQueryExpand(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+
+	tests := []struct {
+		preset string
+		params map[string]string
+		want   string
+	}{
+		{
+			preset: "gke_pod_errors",
+			params: map[string]string{"namespace": "payments", "pod": "api-7d9f"},
+			want:   `resource.type="k8s_container" AND resource.labels.namespace_name="payments" AND resource.labels.pod_name:"api-7d9f" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "gke_pod_errors",
+			params: map[string]string{"namespace": "payments"},
+			want:   `resource.type="k8s_container" AND resource.labels.namespace_name="payments" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "lb_5xx",
+			params: map[string]string{"backend": "web-backend"},
+			want:   `resource.type="http_load_balancer" AND httpRequest.status>=500 AND resource.labels.backend_service_name="web-backend" AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "lb_5xx",
+			want:   `resource.type="http_load_balancer" AND httpRequest.status>=500 AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "function_errors",
+			params: map[string]string{"name": "resize-image"},
+			want:   `resource.type="cloud_function" AND resource.labels.function_name="resize-image" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "function_errors",
+			want:   `resource.type="cloud_function" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "app_engine_errors",
+			params: map[string]string{"service": "default"},
+			want:   `resource.type="gae_app" AND resource.labels.module_id="default" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "app_engine_errors",
+			want:   `resource.type="gae_app" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+		{
+			preset: "cloud_sql_errors",
+			params: map[string]string{"instance": "orders-db"},
+			want:   `resource.type="cloudsql_database" AND resource.labels.database_id:"orders-db" AND severity>=ERROR AND timestamp>="2024-05-01T06:00:00Z"`,
+		},
+		{
+			preset: "cloud_sql_errors",
+			want:   `resource.type="cloudsql_database" AND severity>=ERROR AND timestamp>="2024-05-01T06:00:00Z"`,
+		},
+		{
+			preset: "iam_policy_changes",
+			params: map[string]string{"principal": "deployer@example.iam.gserviceaccount.com"},
+			want:   `logName:"cloudaudit.googleapis.com%2Factivity" AND protoPayload.methodName:"SetIamPolicy" AND protoPayload.authenticationInfo.principalEmail="deployer@example.iam.gserviceaccount.com" AND timestamp>="2024-04-30T12:00:00Z"`,
+		},
+		{
+			preset: "iam_policy_changes",
+			want:   `logName:"cloudaudit.googleapis.com%2Factivity" AND protoPayload.methodName:"SetIamPolicy" AND timestamp>="2024-04-30T12:00:00Z"`,
+		},
+		{
+			preset: "oom_killed",
+			params: map[string]string{"namespace": "batch"},
+			want:   `(jsonPayload.reason="OOMKilling" OR jsonPayload.message:"OOMKilled" OR textPayload:"Memory limit of") AND resource.labels.namespace_name="batch" AND timestamp>="2024-05-01T06:00:00Z"`,
+		},
+		{
+			preset: "oom_killed",
+			want:   `(jsonPayload.reason="OOMKilling" OR jsonPayload.message:"OOMKilled" OR textPayload:"Memory limit of") AND timestamp>="2024-05-01T06:00:00Z"`,
+		},
+		{
+			preset: "container_restarts",
+			params: map[string]string{"namespace": "batch"},
+			want:   `resource.type="k8s_pod" AND log_id("events") AND jsonPayload.reason=("BackOff" OR "Killing" OR "Unhealthy") AND resource.labels.namespace_name="batch" AND timestamp>="2024-05-01T06:00:00Z"`,
+		},
+		{
+			preset: "container_restarts",
+			want:   `resource.type="k8s_pod" AND log_id("events") AND jsonPayload.reason=("BackOff" OR "Killing" OR "Unhealthy") AND timestamp>="2024-05-01T06:00:00Z"`,
+		},
+		{
+			preset: "gke_pod_errors",
+			params: map[string]string{"namespace": `a" OR "b`},
+			want:   `resource.type="k8s_container" AND resource.labels.namespace_name="a\" OR \"b" AND severity>=ERROR AND timestamp>="2024-05-01T11:00:00Z"`,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.preset, func(t *testing.T) {
+			preset, ok := CommonPresetQueries[tt.preset]
+			if !ok {
+				t.Fatalf("preset %s not found", tt.preset)
+			}
+			got, err := preset.Expand(tt.params, now)
+			if err != nil {
+				t.Fatalf("Expand: %v", err)
+			}
+			if got != tt.want {
+				t.Errorf("Expand(%v) =\n%s\nwant\n%s", tt.params, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPresetQueryExpandRequiresParameters(t *testing.T) {
+	if _, err := CommonPresetQueries["gke_pod_errors"].Expand(nil, time.Now()); err == nil {
+		t.Error("gke_pod_errors expanded without a namespace")
+	}
+}
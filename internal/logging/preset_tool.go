@@ -16,14 +16,19 @@ type PresetQueryTool struct {
 }
 
 type PresetQueryArgs struct {
-	QueryName  string   `json:"queryName"`
-	Parameters []string `json:"parameters,omitempty"`
+	QueryName       string            `json:"queryName"`
+	Parameters      map[string]string `json:"parameters,omitempty"`
+	Fields          string            `json:"fields,omitempty"`
+	MaxPayloadBytes int               `json:"maxPayloadBytes,omitempty"`
+	Compact         bool              `json:"compact,omitempty"`
+	MaxOutputTokens int               `json:"maxOutputTokens,omitempty"`
+	PageToken       string            `json:"pageToken,omitempty"`
 }
 
 func NewPresetQueryTool(client *Client) *PresetQueryTool {
 	return &PresetQueryTool{
 		client:      client,
-		cache:       NewLogCache(),
+		cache:       client.Cache(),
 		rateLimiter: NewRateLimiter(),
 	}
 }
@@ -33,7 +38,7 @@ func (t *PresetQueryTool) Name() string {
 }
 
 func (t *PresetQueryTool) Description() string {
-	return "Execute predefined optimized queries for common use cases like Cloud Run errors, recent logs, etc."
+	return "Execute predefined optimized queries for common use cases like Cloud Run errors, recent logs, etc. Parameters are named (e.g. {\"service\": \"api\", \"window\": \"2h\"}); use list_preset_queries to discover presets and their parameters. fields, maxPayloadBytes and compact trim the output, and maxOutputTokens and pageToken bound and page it, as in list_log_entries."
 }
 
 func (t *PresetQueryTool) Schema() types.Schema {
@@ -44,18 +49,33 @@ func (t *PresetQueryTool) Schema() types.Schema {
 				Type: "string",
 			},
 			"parameters": {
-				Type: "array",
-				Items: &types.Schema{
+				Type: "object",
+				AdditionalProperties: types.Schema{
 					Type: "string",
 				},
 			},
+			"fields": {
+				Type: "string",
+			},
+			"maxPayloadBytes": {
+				Type: "integer",
+			},
+			"compact": {
+				Type: "boolean",
+			},
+			"maxOutputTokens": {
+				Type: "integer",
+			},
+			"pageToken": {
+				Type: "string",
+			},
 		},
 		Required:             []string{"queryName"},
 		AdditionalProperties: false,
 	}
 }
 
-func (t *PresetQueryTool) Execute(args map[string]interface{}) (*types.CallToolResult, error) {
+func (t *PresetQueryTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
 	var params PresetQueryArgs
 	if argsBytes, err := json.Marshal(args); err != nil {
 		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
@@ -73,17 +93,39 @@ func (t *PresetQueryTool) Execute(args map[string]interface{}) (*types.CallToolR
 		}, nil
 	}
 
+	// Get preset query
+	filter, pageSize, err := GetPresetQuery(params.QueryName, params.Parameters)
+	if err != nil {
+		return &types.CallToolResult{
+			Content: []types.Content{{
+				Type: "text",
+				Text: fmt.Sprintf("Error: %v", err),
+			}},
+			IsError: true,
+		}, nil
+	}
+
+	view := NewEntryView(params.Fields, params.MaxPayloadBytes, params.Compact)
+	budget := outputBudget(params.MaxOutputTokens)
+
+	if _, err := parsePageToken(params.PageToken); err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
 	// Check cache first
-	cacheKey := t.cache.GenerateKey(params)
+	cacheKey := t.cache.GenerateQueryKey(filter, "", pageSize, "timestamp desc")
+	if params.PageToken != "" {
+		cacheKey = t.cache.GenerateKey([]string{cacheKey, params.PageToken})
+	}
 	if cachedEntries, found := t.cache.Get(cacheKey); found {
 		log.Printf("Cache hit for preset query: %s", params.QueryName)
 		result := map[string]interface{}{
 			"queryName": params.QueryName,
+			"filter":    filter,
 			"count":     len(cachedEntries),
-			"entries":   cachedEntries,
 			"cached":    true,
 		}
-		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		resultJSON, err := fitEntries(cachedEntries, view, budget, result)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal cached results: %w", err)
 		}
@@ -95,24 +137,11 @@ func (t *PresetQueryTool) Execute(args map[string]interface{}) (*types.CallToolR
 		}, nil
 	}
 
-	// Get preset query
-	filter, pageSize, err := GetPresetQuery(params.QueryName, params.Parameters...)
-	if err != nil {
-		return &types.CallToolResult{
-			Content: []types.Content{{
-				Type: "text",
-				Text: fmt.Sprintf("Error: %v", err),
-			}},
-			IsError: true,
-		}, nil
-	}
-
-	ctx := context.Background()
 	var entries []LogEntry
 
 	// Execute with rate limiting and backoff
 	err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
-		entries, err = t.executePresetQuery(ctx, filter, pageSize)
+		entries, err = t.executePresetQuery(ctx, filter, pageSize, params.PageToken)
 		return err
 	})
 
@@ -134,10 +163,9 @@ func (t *PresetQueryTool) Execute(args map[string]interface{}) (*types.CallToolR
 		"queryName": params.QueryName,
 		"filter":    filter,
 		"count":     len(entries),
-		"entries":   entries,
 	}
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	resultJSON, err := fitEntries(entries, view, budget, result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal preset query results: %w", err)
 	}
@@ -150,12 +178,13 @@ func (t *PresetQueryTool) Execute(args map[string]interface{}) (*types.CallToolR
 	}, nil
 }
 
-func (t *PresetQueryTool) executePresetQuery(ctx context.Context, filter string, pageSize int) ([]LogEntry, error) {
+func (t *PresetQueryTool) executePresetQuery(ctx context.Context, filter string, pageSize int, pageToken string) ([]LogEntry, error) {
 	// Use the same logic as list_log_entries but with preset parameters
 	listTool := NewListLogEntriesTools(t.client)
 	return listTool.listLogEntries(ctx, ListLogEntriesArgs{
-		Filter:   filter,
-		PageSize: pageSize,
-		OrderBy:  "timestamp desc",
+		Filter:    filter,
+		PageSize:  pageSize,
+		OrderBy:   "timestamp desc",
+		PageToken: pageToken,
 	})
 }
@@ -2,31 +2,82 @@ package logging
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"math"
-	"strings"
+	"math/rand"
+	"net/http"
 	"time"
 
+	"google.golang.org/api/googleapi"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 )
 
+// Clock abstracts time so retry behaviour can be driven by a fake in tests
+type Clock interface {
+	After(d time.Duration) <-chan time.Time
+}
+
+type realClock struct{}
+
+func (realClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
+
+// RetryPolicy controls which errors are retried and how long to wait between attempts
+type RetryPolicy struct {
+	// InitialBackoff is the upper bound of the first jittered delay
+	InitialBackoff time.Duration
+	// MaxBackoff caps the upper bound of any single delay
+	MaxBackoff time.Duration
+	// Multiplier grows the upper bound after every attempt
+	Multiplier float64
+	// MaxRetries is the number of retries after the first attempt
+	MaxRetries int
+	// RetryBudget caps the total time a single call may spend waiting between attempts
+	RetryBudget time.Duration
+	// RetryableCodes lists the gRPC codes that are considered transient
+	RetryableCodes []codes.Code
+}
+
+func DefaultRetryPolicy() RetryPolicy {
+	return RetryPolicy{
+		InitialBackoff: 1 * time.Second,
+		MaxBackoff:     16 * time.Second,
+		Multiplier:     2,
+		MaxRetries:     3,
+		RetryBudget:    30 * time.Second,
+		RetryableCodes: []codes.Code{
+			codes.ResourceExhausted,
+			codes.Unavailable,
+			codes.DeadlineExceeded,
+		},
+	}
+}
+
 type RateLimiter struct {
-	backoffDuration time.Duration
-	maxRetries      int
+	policy RetryPolicy
+	clock  Clock
+	jitter func() float64
 }
 
 func NewRateLimiter() *RateLimiter {
+	return NewRateLimiterWithPolicy(DefaultRetryPolicy(), realClock{})
+}
+
+func NewRateLimiterWithPolicy(policy RetryPolicy, clock Clock) *RateLimiter {
 	return &RateLimiter{
-		backoffDuration: 1 * time.Second,
-		maxRetries:      3,
+		policy: policy,
+		clock:  clock,
+		jitter: rand.Float64,
 	}
 }
 
 func (r *RateLimiter) ExecuteWithBackoff(ctx context.Context, operation func() error) error {
 	var lastErr error
+	var waited time.Duration
 
-	for attempt := 0; attempt <= r.maxRetries; attempt++ {
+	for attempt := 0; attempt <= r.policy.MaxRetries; attempt++ {
 		err := operation()
 		if err == nil {
 			return nil
@@ -34,40 +85,93 @@ func (r *RateLimiter) ExecuteWithBackoff(ctx context.Context, operation func() e
 
 		lastErr = err
 
-		// Check if it's a quota exceeded error
-		if !r.isQuotaExceededError(err) {
+		// Stop immediately once the caller is gone
+		if ctx.Err() != nil {
+			return err
+		}
+
+		if !r.isRetryable(err) {
 			return err
 		}
 
-		if attempt < r.maxRetries {
-			// Exponential backoff with jitter
-			backoffTime := r.calculateBackoff(attempt)
-			time.Sleep(backoffTime)
+		if attempt == r.policy.MaxRetries {
+			break
+		}
+
+		delay := r.retryDelay(err, attempt)
+		if r.policy.RetryBudget > 0 && waited+delay > r.policy.RetryBudget {
+			return fmt.Errorf("retry budget of %s exhausted: %w", r.policy.RetryBudget, lastErr)
 		}
+
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("retry cancelled: %w", lastErr)
+		case <-r.clock.After(delay):
+		}
+		waited += delay
 	}
 
 	return fmt.Errorf("max retries exceeded: %w", lastErr)
 }
 
-func (r *RateLimiter) isQuotaExceededError(err error) bool {
-	if st, ok := status.FromError(err); ok {
-		if st.Code() == codes.ResourceExhausted {
+func (r *RateLimiter) isRetryable(err error) bool {
+	code, ok := errorCode(err)
+	if !ok {
+		return false
+	}
+	for _, retryable := range r.policy.RetryableCodes {
+		if code == retryable {
 			return true
 		}
 	}
+	return false
+}
+
+// retryDelay honours a server-provided RetryInfo delay, and otherwise uses
+// full jitter: a uniformly random delay between zero and the backoff cap.
+func (r *RateLimiter) retryDelay(err error, attempt int) time.Duration {
+	if delay, ok := serverRetryDelay(err); ok {
+		return delay
+	}
+
+	backoff := float64(r.policy.InitialBackoff) * math.Pow(r.policy.Multiplier, float64(attempt))
+	if r.policy.MaxBackoff > 0 && backoff > float64(r.policy.MaxBackoff) {
+		backoff = float64(r.policy.MaxBackoff)
+	}
 
-	errStr := strings.ToLower(err.Error())
-	return strings.Contains(errStr, "quota exceeded") ||
-		strings.Contains(errStr, "rate limit") ||
-		strings.Contains(errStr, "resource exhausted")
+	return time.Duration(r.jitter() * backoff)
 }
 
-func (r *RateLimiter) calculateBackoff(attempt int) time.Duration {
-	// Exponential backoff: 1s, 2s, 4s, 8s...
-	backoff := r.backoffDuration * time.Duration(math.Pow(2, float64(attempt)))
+// errorCode classifies gRPC status errors and Google API HTTP errors
+func errorCode(err error) (codes.Code, bool) {
+	if st, ok := status.FromError(err); ok && st.Code() != codes.Unknown {
+		return st.Code(), true
+	}
+
+	var apiErr *googleapi.Error
+	if errors.As(err, &apiErr) {
+		switch apiErr.Code {
+		case http.StatusTooManyRequests:
+			return codes.ResourceExhausted, true
+		case http.StatusServiceUnavailable, http.StatusBadGateway:
+			return codes.Unavailable, true
+		case http.StatusGatewayTimeout:
+			return codes.DeadlineExceeded, true
+		}
+	}
 
-	// Add jitter to avoid thundering herd
-	jitter := time.Duration(float64(backoff) * 0.1)
+	return codes.Unknown, false
+}
 
-	return backoff + jitter
+func serverRetryDelay(err error) (time.Duration, bool) {
+	st, ok := status.FromError(err)
+	if !ok {
+		return 0, false
+	}
+	for _, detail := range st.Details() {
+		if info, ok := detail.(*errdetails.RetryInfo); ok && info.GetRetryDelay() != nil {
+			return info.GetRetryDelay().AsDuration(), true
+		}
+	}
+	return 0, false
 }
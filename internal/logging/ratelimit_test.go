@@ -0,0 +1,193 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
+)
+
+// fakeClock records the delays it is asked to wait and fires at once, unless
+// after overrides what waiting does
+type fakeClock struct {
+	now    time.Time
+	delays []time.Duration
+	after  func(d time.Duration) <-chan time.Time
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.delays = append(c.delays, d)
+	if c.after != nil {
+		return c.after(d)
+	}
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+func testRetryPolicy() RetryPolicy {
+	policy := DefaultRetryPolicy()
+	policy.MaxRetries = 5
+	policy.RetryBudget = time.Hour
+	return policy
+}
+
+// failing returns an operation that fails with err the given number of times
+// and then succeeds, counting its calls
+func failing(err error, failures int, calls *int) func() error {
+	return func() error {
+		*calls++
+		if *calls <= failures {
+			return err
+		}
+		return nil
+	}
+}
+
+func TestExecuteWithBackoffFullJitter(t *testing.T) {
+	policy := testRetryPolicy()
+	// Upper bounds of the delays: 1s, 2s, 4s, 8s, then capped at 16s
+	caps := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 8 * time.Second, 16 * time.Second}
+
+	for _, jitter := range []float64{0, 0.5, 0.999999} {
+		clock := &fakeClock{}
+		limiter := NewRateLimiterWithPolicy(policy, clock)
+		limiter.jitter = func() float64 { return jitter }
+
+		var calls int
+		err := limiter.ExecuteWithBackoff(context.Background(), failing(status.Error(codes.Unavailable, "unavailable"), 5, &calls))
+		if err != nil {
+			t.Fatalf("jitter %v: unexpected error: %v", jitter, err)
+		}
+		if calls != 6 {
+			t.Errorf("jitter %v: got %d calls, want 6", jitter, calls)
+		}
+		if len(clock.delays) != len(caps) {
+			t.Fatalf("jitter %v: got %d delays, want %d", jitter, len(clock.delays), len(caps))
+		}
+		for i, delay := range clock.delays {
+			if delay < 0 || delay >= caps[i] {
+				t.Errorf("jitter %v: delay %d = %s, want within [0, %s)", jitter, i, delay, caps[i])
+			}
+			if want := time.Duration(jitter * float64(caps[i])); delay != want {
+				t.Errorf("jitter %v: delay %d = %s, want %s", jitter, i, delay, want)
+			}
+		}
+	}
+}
+
+func TestExecuteWithBackoffHonoursRetryInfo(t *testing.T) {
+	st, err := status.New(codes.ResourceExhausted, "quota").WithDetails(&errdetails.RetryInfo{
+		RetryDelay: durationpb.New(7 * time.Second),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	clock := &fakeClock{}
+	limiter := NewRateLimiterWithPolicy(testRetryPolicy(), clock)
+	limiter.jitter = func() float64 { return 0 }
+
+	var calls int
+	if err := limiter.ExecuteWithBackoff(context.Background(), failing(st.Err(), 2, &calls)); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	for i, delay := range clock.delays {
+		if delay != 7*time.Second {
+			t.Errorf("delay %d = %s, want the server's 7s", i, delay)
+		}
+	}
+	if len(clock.delays) != 2 {
+		t.Errorf("got %d delays, want 2", len(clock.delays))
+	}
+}
+
+func TestExecuteWithBackoffBudgetExhausted(t *testing.T) {
+	policy := testRetryPolicy()
+	policy.RetryBudget = 5 * time.Second
+
+	clock := &fakeClock{}
+	limiter := NewRateLimiterWithPolicy(policy, clock)
+	// Waits the full cap: 1s, 2s, then 4s would pass the 5s budget
+	limiter.jitter = func() float64 { return 1 }
+
+	var calls int
+	lastErr := status.Error(codes.Unavailable, "unavailable")
+	err := limiter.ExecuteWithBackoff(context.Background(), failing(lastErr, 10, &calls))
+	if err == nil || !strings.Contains(err.Error(), "retry budget") {
+		t.Fatalf("got %v, want a retry budget error", err)
+	}
+	if !errors.Is(err, lastErr) {
+		t.Errorf("error %v does not wrap the last failure", err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+	if len(clock.delays) != 2 {
+		t.Errorf("got %d delays, want 2", len(clock.delays))
+	}
+}
+
+func TestExecuteWithBackoffMaxRetries(t *testing.T) {
+	policy := testRetryPolicy()
+	policy.MaxRetries = 2
+	limiter := NewRateLimiterWithPolicy(policy, &fakeClock{})
+
+	var calls int
+	err := limiter.ExecuteWithBackoff(context.Background(), failing(status.Error(codes.Unavailable, "unavailable"), 10, &calls))
+	if err == nil || !strings.Contains(err.Error(), "max retries exceeded") {
+		t.Fatalf("got %v, want max retries exceeded", err)
+	}
+	if calls != 3 {
+		t.Errorf("got %d calls, want 3", calls)
+	}
+}
+
+func TestExecuteWithBackoffNotRetryable(t *testing.T) {
+	clock := &fakeClock{}
+	limiter := NewRateLimiterWithPolicy(testRetryPolicy(), clock)
+
+	var calls int
+	lastErr := status.Error(codes.InvalidArgument, "bad filter")
+	if err := limiter.ExecuteWithBackoff(context.Background(), failing(lastErr, 10, &calls)); err != lastErr {
+		t.Fatalf("got %v, want %v", err, lastErr)
+	}
+	if calls != 1 || len(clock.delays) != 0 {
+		t.Errorf("got %d calls and %d delays, want 1 and 0", calls, len(clock.delays))
+	}
+}
+
+func TestExecuteWithBackoffCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	// The caller goes away while the limiter waits
+	clock := &fakeClock{after: func(time.Duration) <-chan time.Time {
+		cancel()
+		return make(chan time.Time)
+	}}
+	limiter := NewRateLimiterWithPolicy(testRetryPolicy(), clock)
+
+	var calls int
+	err := limiter.ExecuteWithBackoff(ctx, failing(status.Error(codes.Unavailable, "unavailable"), 10, &calls))
+	if err == nil || !strings.Contains(err.Error(), "retry cancelled") {
+		t.Fatalf("got %v, want retry cancelled", err)
+	}
+	if calls != 1 {
+		t.Errorf("got %d calls, want 1", calls)
+	}
+
+	// An operation that fails after the context is cancelled is not retried
+	calls = 0
+	err = limiter.ExecuteWithBackoff(ctx, failing(status.Error(codes.Unavailable, "unavailable"), 10, &calls))
+	if err == nil || calls != 1 {
+		t.Errorf("got %v after %d calls, want one failed call", err, calls)
+	}
+}
@@ -0,0 +1,388 @@
+package logging
+
+import (
+	"bytes"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"net"
+	"os"
+	"regexp"
+	"slices"
+	"strings"
+	"sync/atomic"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Name counted for values redacted because of a deny list entry
+const deniedFieldName = "field"
+
+// Detectors that find personal data and secrets in any string value
+var builtinDetectors = []redactionDetector{
+	{name: "jwt", pattern: regexp.MustCompile(`\beyJ[A-Za-z0-9_-]+\.[A-Za-z0-9_-]+\.[A-Za-z0-9_-]*`)},
+	{name: "gcp_api_key", pattern: regexp.MustCompile(`\bAIza[0-9A-Za-z_-]{35}\b`)},
+	{name: "email", pattern: regexp.MustCompile(`\b[A-Za-z0-9._%+-]+@[A-Za-z0-9-]+(?:\.[A-Za-z0-9-]+)*\.[A-Za-z]{2,}\b`)},
+	{name: "credit_card", pattern: regexp.MustCompile(`\b\d(?:[ -]?\d){12,18}\b`), valid: luhnValid},
+	{name: "ipv6", pattern: regexp.MustCompile(`(?i)(?:\b[0-9a-f]{1,4})?(?::[0-9a-f]{0,4}){2,7}`), valid: isIPv6},
+	{name: "ipv4", pattern: regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}\b`), valid: isIPv4},
+}
+
+// Fields that identify entries rather than describe them; they are never
+// redacted so that entries can still be looked up and paged through
+var defaultAllowedFields = []string{"timestamp", "insertId", "traceId", "spanId", "severity", "logName", "nextPageToken"}
+
+var activeRedactor atomic.Pointer[Redactor]
+
+// RedactionConfig is the file format of -redaction-file. Field paths are
+// dotted keys such as jsonPayload.headers.authorization, matched against the
+// end of the path of each value so that they apply wherever entries are
+// nested in a result; * matches any single key.
+type RedactionConfig struct {
+	// Built-in detectors to run; all of them when omitted
+	Detectors []string        `yaml:"detectors"`
+	Rules     []RedactionRule `yaml:"rules"`
+	// Fields that are never redacted
+	Allow []string `yaml:"allow"`
+	// Fields whose whole value is always redacted
+	Deny []string `yaml:"deny"`
+	// Key of the hashes in placeholders; random per process when empty
+	HashKey string `yaml:"hashKey"`
+}
+
+// RedactionRule redacts every match of a regular expression
+type RedactionRule struct {
+	Name    string `yaml:"name"`
+	Pattern string `yaml:"pattern"`
+}
+
+type redactionDetector struct {
+	name    string
+	pattern *regexp.Regexp
+	// Checks a match further, for patterns that also match other values
+	valid func(string) bool
+}
+
+// Redactor replaces personal data and secrets in tool results before they
+// reach the client. Each value becomes a placeholder with a keyed hash of
+// it, so the same value always redacts the same way and can still be
+// correlated across entries and calls.
+type Redactor struct {
+	detectors []redactionDetector
+	allow     [][]string
+	deny      [][]string
+	hashKey   []byte
+}
+
+// EnableRedaction turns on redaction of tool results, configured by the given
+// YAML or JSON file, or with the built-in detectors only when path is empty
+func EnableRedaction(path string) error {
+	var config RedactionConfig
+	if path != "" {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return fmt.Errorf("failed to read redaction file: %w", err)
+		}
+		// JSON is a subset of YAML, so both formats share the same parser
+		decoder := yaml.NewDecoder(bytes.NewReader(data))
+		decoder.KnownFields(true)
+		if err := decoder.Decode(&config); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("%s: %w", path, err)
+		}
+	}
+
+	redactor, err := NewRedactor(config)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	activeRedactor.Store(redactor)
+	log.Printf("Redaction enabled with %d detectors and rules", len(redactor.detectors))
+	return nil
+}
+
+// NewRedactor validates a configuration and compiles its rules
+func NewRedactor(config RedactionConfig) (*Redactor, error) {
+	r := &Redactor{hashKey: []byte(config.HashKey)}
+	if len(r.hashKey) == 0 {
+		r.hashKey = make([]byte, 32)
+		if _, err := rand.Read(r.hashKey); err != nil {
+			return nil, fmt.Errorf("failed to generate hash key: %w", err)
+		}
+	}
+
+	if config.Detectors == nil {
+		r.detectors = append(r.detectors, builtinDetectors...)
+	}
+	for _, name := range config.Detectors {
+		i := slices.IndexFunc(builtinDetectors, func(d redactionDetector) bool { return d.name == name })
+		if i < 0 {
+			return nil, fmt.Errorf("unknown detector %q: use %s", name, strings.Join(detectorNames(), ", "))
+		}
+		r.detectors = append(r.detectors, builtinDetectors[i])
+	}
+
+	for _, rule := range config.Rules {
+		if rule.Name == "" || rule.Pattern == "" {
+			return nil, fmt.Errorf("redaction rules need a name and a pattern")
+		}
+		pattern, err := regexp.Compile(rule.Pattern)
+		if err != nil {
+			return nil, fmt.Errorf("rule %q: %w", rule.Name, err)
+		}
+		r.detectors = append(r.detectors, redactionDetector{name: rule.Name, pattern: pattern})
+	}
+
+	for _, field := range append(slices.Clone(defaultAllowedFields), config.Allow...) {
+		r.allow = append(r.allow, strings.Split(field, "."))
+	}
+	for _, field := range config.Deny {
+		r.deny = append(r.deny, strings.Split(field, "."))
+	}
+	return r, nil
+}
+
+func detectorNames() []string {
+	names := make([]string, len(builtinDetectors))
+	for i, d := range builtinDetectors {
+		names[i] = d.name
+	}
+	return names
+}
+
+// Redact applies the enabled redaction to a tool result and returns how many
+// values each detector, rule or deny list replaced. Results are returned
+// unchanged when redaction is off.
+func Redact(text string) (string, map[string]int) {
+	r := activeRedactor.Load()
+	if r == nil {
+		return text, nil
+	}
+	return r.Redact(text)
+}
+
+// Redact replaces sensitive values in a tool result. JSON results are
+// rewritten value by value, so that allow and deny lists apply and the
+// formatting is kept; other text is scanned as a whole.
+func (r *Redactor) Redact(text string) (string, map[string]int) {
+	counts := make(map[string]int)
+	if redacted, ok := r.redactJSON(text, counts); ok {
+		return redacted, counts
+	}
+	clear(counts)
+	return r.redactString(text, counts), counts
+}
+
+type redactionEdit struct {
+	start, end  int
+	replacement string
+}
+
+// redactJSON walks the tokens of a JSON document and replaces the string
+// values that need redaction in place. It reports false when the text is not
+// JSON as produced by encoding/json.
+func (r *Redactor) redactJSON(text string, counts map[string]int) (string, bool) {
+	type frame struct {
+		object    bool
+		expectKey bool
+		key       string
+	}
+	var stack []*frame
+	path := func() []string {
+		var keys []string
+		for _, f := range stack {
+			if f.object {
+				keys = append(keys, f.key)
+			}
+		}
+		return keys
+	}
+	valueDone := func() {
+		if n := len(stack); n > 0 && stack[n-1].object {
+			stack[n-1].expectKey = true
+		}
+	}
+
+	var edits []redactionEdit
+	// A denied object or array is replaced as a whole once it is closed
+	deniedDepth, deniedStart := -1, 0
+
+	decoder := json.NewDecoder(strings.NewReader(text))
+	decoder.UseNumber()
+	for {
+		token, err := decoder.Token()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return "", false
+		}
+		end := int(decoder.InputOffset())
+
+		switch t := token.(type) {
+		case json.Delim:
+			switch t {
+			case '{', '[':
+				if deniedDepth < 0 && matchesField(r.deny, path()) {
+					deniedDepth, deniedStart = len(stack), end-1
+				}
+				stack = append(stack, &frame{object: t == '{', expectKey: true})
+			case '}', ']':
+				stack = stack[:len(stack)-1]
+				if len(stack) == deniedDepth {
+					edits = append(edits, r.placeholderEdit(deniedStart, end, text[deniedStart:end], counts))
+					deniedDepth = -1
+				}
+				valueDone()
+			}
+
+		case string:
+			if n := len(stack); n > 0 && stack[n-1].object && stack[n-1].expectKey {
+				stack[n-1].key = t
+				stack[n-1].expectKey = false
+				continue
+			}
+			if deniedDepth < 0 {
+				encoded, _ := json.Marshal(t)
+				start := end - len(encoded)
+				if start < 0 || text[start:end] != string(encoded) {
+					return "", false
+				}
+				if redacted := r.redactValue(path(), t, counts); redacted != t {
+					replacement, _ := json.Marshal(redacted)
+					edits = append(edits, redactionEdit{start, end, string(replacement)})
+				}
+			}
+			valueDone()
+
+		default:
+			// Numbers may be denied too, e.g. account numbers
+			if number, ok := t.(json.Number); ok && deniedDepth < 0 && matchesField(r.deny, path()) {
+				start := end - len(number)
+				if start < 0 || text[start:end] != string(number) {
+					return "", false
+				}
+				edits = append(edits, r.placeholderEdit(start, end, string(number), counts))
+			}
+			valueDone()
+		}
+	}
+	if len(stack) > 0 {
+		return "", false
+	}
+
+	var b strings.Builder
+	last := 0
+	for _, edit := range edits {
+		b.WriteString(text[last:edit.start])
+		b.WriteString(edit.replacement)
+		last = edit.end
+	}
+	b.WriteString(text[last:])
+	return b.String(), true
+}
+
+func (r *Redactor) placeholderEdit(start, end int, value string, counts map[string]int) redactionEdit {
+	replacement, _ := json.Marshal(r.placeholder(deniedFieldName, value, counts))
+	return redactionEdit{start, end, string(replacement)}
+}
+
+func (r *Redactor) redactValue(path []string, value string, counts map[string]int) string {
+	if matchesField(r.deny, path) {
+		return r.placeholder(deniedFieldName, value, counts)
+	}
+	if matchesField(r.allow, path) {
+		return value
+	}
+	return r.redactString(value, counts)
+}
+
+func (r *Redactor) redactString(value string, counts map[string]int) string {
+	for _, d := range r.detectors {
+		value = d.pattern.ReplaceAllStringFunc(value, func(match string) string {
+			if d.valid != nil && !d.valid(match) {
+				return match
+			}
+			return r.placeholder(d.name, match, counts)
+		})
+	}
+	return value
+}
+
+// placeholder replaces a value with the name of what found it and a keyed
+// hash of the value
+func (r *Redactor) placeholder(name, value string, counts map[string]int) string {
+	counts[name]++
+	mac := hmac.New(sha256.New, r.hashKey)
+	mac.Write([]byte(value))
+	return fmt.Sprintf("[REDACTED:%s:%s]", name, hex.EncodeToString(mac.Sum(nil))[:12])
+}
+
+// matchesField reports whether one of the field paths matches the end of path
+func matchesField(fields [][]string, path []string) bool {
+	for _, field := range fields {
+		if len(field) > len(path) {
+			continue
+		}
+		tail := path[len(path)-len(field):]
+		matched := true
+		for i, key := range field {
+			if key != "*" && !strings.EqualFold(key, tail[i]) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+	return false
+}
+
+// luhnValid checks the Luhn checksum of a candidate card number
+func luhnValid(number string) bool {
+	var sum, digits int
+	double := false
+	for i := len(number) - 1; i >= 0; i-- {
+		c := number[i]
+		if c == ' ' || c == '-' {
+			continue
+		}
+		d := int(c - '0')
+		if double {
+			if d *= 2; d > 9 {
+				d -= 9
+			}
+		}
+		sum += d
+		digits++
+		double = !double
+	}
+	return digits >= 13 && digits <= 19 && sum%10 == 0
+}
+
+func isIPv4(candidate string) bool {
+	ip := net.ParseIP(candidate)
+	return ip != nil && ip.To4() != nil
+}
+
+// isIPv6 accepts addresses with at least two groups, so that "::" in
+// identifiers such as std::io is left alone
+func isIPv6(candidate string) bool {
+	ip := net.ParseIP(candidate)
+	if ip == nil || ip.To4() != nil {
+		return false
+	}
+	groups := 0
+	for _, group := range strings.Split(candidate, ":") {
+		if group != "" {
+			groups++
+		}
+	}
+	return groups >= 2
+}
@@ -0,0 +1,412 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultRequestItems = 10
+	maxRequestItems     = 50
+	// Distinct path templates tracked; the rest are counted under otherGroup
+	maxPathTemplates = 1000
+	// Query strings can be very long
+	maxRequestURLChars = 300
+)
+
+type RequestStatsTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type RequestStatsArgs struct {
+	Filter     string `json:"filter,omitempty"`
+	StartTime  string `json:"startTime,omitempty"`
+	EndTime    string `json:"endTime,omitempty"`
+	Bucket     string `json:"bucket,omitempty"`
+	MaxItems   int    `json:"maxItems,omitempty"`
+	MaxEntries int    `json:"maxEntries,omitempty"`
+}
+
+// latencySummary is in milliseconds
+type latencySummary struct {
+	P50 float64 `json:"p50Ms"`
+	P95 float64 `json:"p95Ms"`
+	P99 float64 `json:"p99Ms"`
+	Max float64 `json:"maxMs"`
+}
+
+type statusCount struct {
+	Status  int     `json:"status"`
+	Count   int     `json:"count"`
+	Percent float64 `json:"percent"`
+}
+
+type pathStats struct {
+	Path      string         `json:"path"`
+	Count     int            `json:"count"`
+	Errors5xx int            `json:"errors5xx"`
+	Latency   latencySummary `json:"latency"`
+	latencies []float64
+}
+
+type slowRequest struct {
+	Timestamp string  `json:"timestamp"`
+	Method    string  `json:"method"`
+	URL       string  `json:"url"`
+	Status    int     `json:"status"`
+	LatencyMs float64 `json:"latencyMs"`
+	TraceID   string  `json:"traceId,omitempty"`
+}
+
+type valueCount struct {
+	Value     string  `json:"value"`
+	Count     int     `json:"count"`
+	Percent   float64 `json:"percent"`
+	Errors5xx int     `json:"errors5xx,omitempty"`
+}
+
+type errorRateBucket struct {
+	Start     time.Time `json:"start"`
+	Total     int       `json:"total"`
+	Errors5xx int       `json:"errors5xx"`
+	Rate      float64   `json:"ratePercent"`
+}
+
+type requestStatsResult struct {
+	Filter     string            `json:"filter"`
+	Start      time.Time         `json:"start"`
+	End        time.Time         `json:"end"`
+	Requests   int               `json:"requests"`
+	Truncated  bool              `json:"truncated"`
+	Statuses   []statusCount     `json:"statuses"`
+	Classes    map[string]int    `json:"classes"`
+	Latency    latencySummary    `json:"latency"`
+	Paths      []pathStats       `json:"paths"`
+	Slowest    []slowRequest     `json:"slowest"`
+	ClientIPs  []valueCount      `json:"clientIps"`
+	UserAgents []valueCount      `json:"userAgents"`
+	Bucket     string            `json:"bucket"`
+	Timeline   []errorRateBucket `json:"errorRate"`
+}
+
+func NewRequestStatsTool(client *Client) *RequestStatsTool {
+	return &RequestStatsTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *RequestStatsTool) Name() string {
+	return "request_stats"
+}
+
+func (t *RequestStatsTool) Description() string {
+	return "Aggregate the httpRequest of Cloud Run and load balancer request logs over a window (default the last hour): status code distribution, overall latency percentiles, p50/p95/p99 per method and path template (IDs and numbers masked), the slowest requests, top client IPs and user agents, and the 5xx rate per time bucket. filter narrows the entries, e.g. resource.labels.service_name=\"api\"; only entries with httpRequest are counted."
+}
+
+func (t *RequestStatsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"bucket": {
+				Type: "string",
+			},
+			"maxItems": {
+				Type: "integer",
+			},
+			"maxEntries": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *RequestStatsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params RequestStatsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	bucket, err := histogramBucketSize(params.Bucket, window.Duration())
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	window.Start = window.Start.Truncate(bucket)
+
+	if params.MaxItems <= 0 {
+		params.MaxItems = defaultRequestItems
+	}
+	if params.MaxItems > maxRequestItems {
+		params.MaxItems = maxRequestItems
+	}
+	params.MaxEntries = scanLimit(params.MaxEntries)
+	filter := window.Filter(NewFilterBuilder().
+		AddFilter("httpRequest:*").
+		AddFilter(params.Filter).
+		Build())
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":       t.Name(),
+		"filter":     NormalizeFilter(filter, t.cache.TimeBucket()),
+		"bucket":     bucket,
+		"maxItems":   params.MaxItems,
+		"maxEntries": params.MaxEntries,
+	})
+
+	var result requestStatsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for request stats: %s", filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.requestStats(ctx, filter, window, bucket, params)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to aggregate requests: %v", err)
+			return ErrorResult(fmt.Sprintf("Error aggregating requests: %v", err)), nil
+		}
+
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request stats: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *RequestStatsTool) requestStats(ctx context.Context, filter string, window TimeWindow, bucket time.Duration, params RequestStatsArgs) (requestStatsResult, error) {
+	count := int(math.Ceil(float64(window.Duration()) / float64(bucket)))
+	result := requestStatsResult{
+		Filter:   filter,
+		Start:    window.Start,
+		End:      window.End,
+		Statuses: []statusCount{},
+		Classes:  make(map[string]int),
+		Paths:    []pathStats{},
+		Slowest:  []slowRequest{},
+		Bucket:   bucket.String(),
+		Timeline: make([]errorRateBucket, count),
+	}
+	for i := range result.Timeline {
+		result.Timeline[i].Start = window.Start.Add(time.Duration(i) * bucket)
+	}
+
+	statuses := make(map[int]int)
+	paths := make(map[string]*pathStats)
+	ips := make(map[string]*valueCount)
+	agents := make(map[string]*valueCount)
+	var latencies []float64
+
+	_, truncated, err := scanEntries(ctx, t.client, filter, params.MaxEntries, func(entry *logging.Entry) {
+		req := entry.HTTPRequest
+		if req == nil {
+			return
+		}
+		result.Requests++
+		is5xx := req.Status >= 500
+		latency := float64(req.Latency.Microseconds()) / 1000
+
+		statuses[req.Status]++
+		result.Classes[statusClass(req.Status)]++
+		latencies = append(latencies, latency)
+
+		var method, path, url, agent string
+		if req.Request != nil {
+			method = req.Request.Method
+			agent = req.Request.UserAgent()
+			if req.Request.URL != nil {
+				path = req.Request.URL.Path
+				url = Truncate(req.Request.URL.String(), maxRequestURLChars)
+			}
+		}
+
+		template := otherGroup
+		key := method + " " + NormalizeMessage(path)
+		if _, ok := paths[key]; ok || len(paths) < maxPathTemplates {
+			template = key
+		}
+		p := paths[template]
+		if p == nil {
+			p = &pathStats{Path: template}
+			paths[template] = p
+		}
+		p.Count++
+		p.latencies = append(p.latencies, latency)
+		if is5xx {
+			p.Errors5xx++
+		}
+
+		countValue(ips, req.RemoteIP, is5xx)
+		countValue(agents, agent, is5xx)
+
+		if index := int(entry.Timestamp.Sub(window.Start) / bucket); index >= 0 && index < count {
+			b := &result.Timeline[index]
+			b.Total++
+			if is5xx {
+				b.Errors5xx++
+			}
+		}
+
+		result.Slowest = addSlowRequest(result.Slowest, slowRequest{
+			Timestamp: entry.Timestamp.Format(time.RFC3339Nano),
+			Method:    method,
+			URL:       url,
+			Status:    req.Status,
+			LatencyMs: round2(latency),
+			TraceID:   entry.Trace,
+		}, params.MaxItems)
+	})
+	if err != nil {
+		return result, err
+	}
+	result.Truncated = truncated
+
+	for status, n := range statuses {
+		result.Statuses = append(result.Statuses, statusCount{Status: status, Count: n, Percent: share(n, result.Requests)})
+	}
+	sort.Slice(result.Statuses, func(i, j int) bool {
+		return result.Statuses[i].Status < result.Statuses[j].Status
+	})
+	result.Latency = summarizeLatencies(latencies)
+
+	for _, p := range paths {
+		p.Latency = summarizeLatencies(p.latencies)
+		result.Paths = append(result.Paths, *p)
+	}
+	sort.Slice(result.Paths, func(i, j int) bool {
+		if result.Paths[i].Count != result.Paths[j].Count {
+			return result.Paths[i].Count > result.Paths[j].Count
+		}
+		return result.Paths[i].Path < result.Paths[j].Path
+	})
+	if len(result.Paths) > params.MaxItems {
+		result.Paths = result.Paths[:params.MaxItems]
+	}
+
+	result.ClientIPs = topValues(ips, result.Requests, params.MaxItems)
+	result.UserAgents = topValues(agents, result.Requests, params.MaxItems)
+	for i := range result.Timeline {
+		b := &result.Timeline[i]
+		b.Rate = share(b.Errors5xx, b.Total)
+	}
+	return result, nil
+}
+
+func statusClass(status int) string {
+	if status < 100 || status > 599 {
+		return "other"
+	}
+	return strconv.Itoa(status/100) + "xx"
+}
+
+func countValue(counts map[string]*valueCount, value string, is5xx bool) {
+	if value == "" {
+		value = noneGroup
+	}
+	c := counts[value]
+	if c == nil {
+		c = &valueCount{Value: value}
+		counts[value] = c
+	}
+	c.Count++
+	if is5xx {
+		c.Errors5xx++
+	}
+}
+
+func topValues(counts map[string]*valueCount, total, limit int) []valueCount {
+	values := make([]valueCount, 0, len(counts))
+	for _, c := range counts {
+		c.Percent = share(c.Count, total)
+		values = append(values, *c)
+	}
+	sort.Slice(values, func(i, j int) bool {
+		if values[i].Count != values[j].Count {
+			return values[i].Count > values[j].Count
+		}
+		return values[i].Value < values[j].Value
+	})
+	if len(values) > limit {
+		values = values[:limit]
+	}
+	return values
+}
+
+// addSlowRequest keeps the limit slowest requests, slowest first
+func addSlowRequest(slowest []slowRequest, req slowRequest, limit int) []slowRequest {
+	if len(slowest) >= limit && req.LatencyMs <= slowest[len(slowest)-1].LatencyMs {
+		return slowest
+	}
+	i := sort.Search(len(slowest), func(i int) bool {
+		return slowest[i].LatencyMs < req.LatencyMs
+	})
+	slowest = append(slowest, slowRequest{})
+	copy(slowest[i+1:], slowest[i:])
+	slowest[i] = req
+	if len(slowest) > limit {
+		slowest = slowest[:limit]
+	}
+	return slowest
+}
+
+// summarizeLatencies takes nearest-rank percentiles, sorting values in place
+func summarizeLatencies(values []float64) latencySummary {
+	if len(values) == 0 {
+		return latencySummary{}
+	}
+	sort.Float64s(values)
+	rank := func(p float64) float64 {
+		i := int(math.Ceil(p*float64(len(values)))) - 1
+		if i < 0 {
+			i = 0
+		}
+		return round2(values[i])
+	}
+	return latencySummary{
+		P50: rank(0.50),
+		P95: rank(0.95),
+		P99: rank(0.99),
+		Max: round2(values[len(values)-1]),
+	}
+}
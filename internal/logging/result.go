@@ -0,0 +1,27 @@
+package logging
+
+import (
+	"strings"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+// ErrorResult is the result tools return for invalid arguments and failed calls
+func ErrorResult(text string) *types.CallToolResult {
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: text,
+		}},
+		IsError: true,
+	}
+}
+
+// Truncate cuts s to at most max bytes without splitting a character and
+// marks the cut with "..."
+func Truncate(s string, max int) string {
+	if len(s) <= max {
+		return s
+	}
+	return strings.ToValidUTF8(s[:max], "") + "..."
+}
@@ -0,0 +1,57 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+
+	"cloud.google.com/go/logging"
+	"cloud.google.com/go/logging/logadmin"
+	"google.golang.org/api/iterator"
+)
+
+const (
+	// Entries fetched per API call when scanning a window; larger pages use less quota
+	scanPageSize = 1000
+	// Default and upper limits on entries scanned by aggregation tools
+	defaultScanLimit = 10000
+	maxScanLimit     = 50000
+)
+
+// scanEntries streams every entry matching the filter to fn without keeping
+// them in memory. It stops after limit entries and reports whether the scan
+// was truncated.
+func scanEntries(ctx context.Context, client *Client, filter string, limit int, fn func(*logging.Entry)) (int, bool, error) {
+	iter := client.Entries(ctx,
+		logadmin.Filter(filter),
+		logadmin.PageSize(scanPageSize),
+	)
+
+	scanned := 0
+	for {
+		if scanned >= limit {
+			return scanned, true, nil
+		}
+
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			return scanned, false, nil
+		}
+		if err != nil {
+			return scanned, false, fmt.Errorf("failed to iterate log entries: %w", err)
+		}
+
+		fn(entry)
+		scanned++
+	}
+}
+
+// scanLimit applies the default and upper limits to a requested entry count
+func scanLimit(requested int) int {
+	if requested <= 0 {
+		return defaultScanLimit
+	}
+	if requested > maxScanLimit {
+		return maxScanLimit
+	}
+	return requested
+}
@@ -0,0 +1,294 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging/logadmin"
+	"google.golang.org/api/iterator"
+	"google.golang.org/genproto/googleapis/rpc/code"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultAuditPageSize = 20
+	maxAuditPageSize     = 50
+)
+
+type SearchAuditLogsTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type SearchAuditLogsArgs struct {
+	PrincipalEmail  string `json:"principalEmail,omitempty"`
+	MethodName      string `json:"methodName,omitempty"`
+	ServiceName     string `json:"serviceName,omitempty"`
+	ResourceName    string `json:"resourceName,omitempty"`
+	Status          string `json:"status,omitempty"`
+	LogType         string `json:"logType,omitempty"`
+	Filter          string `json:"filter,omitempty"`
+	StartTime       string `json:"startTime,omitempty"`
+	EndTime         string `json:"endTime,omitempty"`
+	PageSize        int    `json:"pageSize,omitempty"`
+	Fields          string `json:"fields,omitempty"`
+	MaxPayloadBytes int    `json:"maxPayloadBytes,omitempty"`
+	Compact         bool   `json:"compact,omitempty"`
+	MaxOutputTokens int    `json:"maxOutputTokens,omitempty"`
+}
+
+type auditRecord struct {
+	Timestamp string            `json:"timestamp"`
+	LogType   string            `json:"logType"`
+	Severity  string            `json:"severity"`
+	Resource  string            `json:"resourceType,omitempty"`
+	Labels    map[string]string `json:"resourceLabels,omitempty"`
+	InsertID  string            `json:"insertId,omitempty"`
+	*AuditEntry
+}
+
+type searchAuditLogsResult struct {
+	Filter  string        `json:"filter"`
+	Count   int           `json:"count"`
+	More    bool          `json:"more,omitempty"`
+	Entries []auditRecord `json:"entries"`
+}
+
+func NewSearchAuditLogsTool(client *Client) *SearchAuditLogsTool {
+	return &SearchAuditLogsTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *SearchAuditLogsTool) Name() string {
+	return "search_audit_logs"
+}
+
+func (t *SearchAuditLogsTool) Description() string {
+	return "Search Cloud Audit Logs (activity, data_access, system_event and policy; all by default) newest first over startTime..endTime (default the last 24h). principalEmail, methodName, serviceName and resourceName match substrings, e.g. methodName SetIamPolicy with a bucket name as resourceName answers who changed IAM on a bucket. status is ok, error or a code name such as PERMISSION_DENIED. Each entry is flattened to principal, delegation chain, caller IP and user agent, status, authorization checks, IAM binding changes (policyDelta), request and response. fields, maxPayloadBytes and compact trim the output as in list_log_entries; results larger than maxOutputTokens are cut with markers for what was dropped."
+}
+
+func (t *SearchAuditLogsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"principalEmail": {
+				Type: "string",
+			},
+			"methodName": {
+				Type: "string",
+			},
+			"serviceName": {
+				Type: "string",
+			},
+			"resourceName": {
+				Type: "string",
+			},
+			"status": {
+				Type: "string",
+			},
+			"logType": {
+				Type: "string",
+				Enum: auditLogTypes,
+			},
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"pageSize": {
+				Type: "integer",
+			},
+			"fields": {
+				Type: "string",
+			},
+			"maxPayloadBytes": {
+				Type: "integer",
+			},
+			"compact": {
+				Type: "boolean",
+			},
+			"maxOutputTokens": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *SearchAuditLogsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params SearchAuditLogsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, 24*time.Hour)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if params.PageSize <= 0 {
+		params.PageSize = defaultAuditPageSize
+	}
+	if params.PageSize > maxAuditPageSize {
+		params.PageSize = maxAuditPageSize
+	}
+	filter, err := buildAuditFilter(params)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	filter = window.Filter(filter)
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":     t.Name(),
+		"filter":   NormalizeFilter(filter, t.cache.TimeBucket()),
+		"pageSize": params.PageSize,
+	})
+
+	var result searchAuditLogsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for audit log search: %s", filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.searchAuditLogs(ctx, filter, params.PageSize)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to search audit logs: %v", err)
+			return ErrorResult(fmt.Sprintf("Error searching audit logs: %v", err)), nil
+		}
+
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	view := NewEntryView(params.Fields, params.MaxPayloadBytes, params.Compact)
+	// The outer entries field shadows the embedded one
+	resultJSON, err := view.Marshal(struct {
+		searchAuditLogsResult
+		Entries []interface{} `json:"entries"`
+	}{result, ApplyAll(view, result.Entries)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal audit log entries: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *SearchAuditLogsTool) searchAuditLogs(ctx context.Context, filter string, pageSize int) (searchAuditLogsResult, error) {
+	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	iter := t.client.Entries(ctxWithTimeout,
+		logadmin.Filter(filter),
+		logadmin.NewestFirst(),
+		logadmin.PageSize(int32(pageSize+1)),
+	)
+
+	result := searchAuditLogsResult{
+		Filter:  filter,
+		Entries: []auditRecord{},
+	}
+	for {
+		entry, err := iter.Next()
+		if err == iterator.Done {
+			break
+		}
+		if err != nil {
+			return searchAuditLogsResult{}, fmt.Errorf("failed to iterate log entries: %w", err)
+		}
+		if len(result.Entries) >= pageSize {
+			result.More = true
+			break
+		}
+
+		payload, ok := auditLog(entry)
+		if !ok {
+			continue
+		}
+		record := auditRecord{
+			Timestamp:  entry.Timestamp.Format(time.RFC3339Nano),
+			LogType:    auditLogType(entry.LogName),
+			Severity:   entry.Severity.String(),
+			InsertID:   entry.InsertID,
+			AuditEntry: newAuditEntry(payload),
+		}
+		if entry.Resource != nil {
+			record.Resource = entry.Resource.Type
+			record.Labels = entry.Resource.Labels
+		}
+		result.Entries = append(result.Entries, record)
+	}
+	result.Count = len(result.Entries)
+	return result, nil
+}
+
+// buildAuditFilter turns the typed arguments into a Logging query over the
+// audit log streams. The time range is added by the caller.
+func buildAuditFilter(params SearchAuditLogsArgs) (string, error) {
+	fb := NewFilterBuilder()
+
+	if params.LogType == "" {
+		fb.AddFilter(fmt.Sprintf(`logName:"%s%%2F"`, auditLogPrefix))
+	} else {
+		if !slices.Contains(auditLogTypes, params.LogType) {
+			return "", fmt.Errorf("logType must be one of %s", strings.Join(auditLogTypes, ", "))
+		}
+		fb.AddFilter(fmt.Sprintf(`logName:"%s%%2F%s"`, auditLogPrefix, params.LogType))
+	}
+
+	fields := []struct {
+		field string
+		value string
+	}{
+		{"protoPayload.authenticationInfo.principalEmail", params.PrincipalEmail},
+		{"protoPayload.methodName", params.MethodName},
+		{"protoPayload.serviceName", params.ServiceName},
+		{"protoPayload.resourceName", params.ResourceName},
+	}
+	for _, f := range fields {
+		if f.value != "" {
+			fb.AddFilter(fmt.Sprintf(`%s:"%s"`, f.field, EscapeFilterValue(f.value)))
+		}
+	}
+
+	// Successful calls usually carry no status at all
+	switch status := strings.ToUpper(params.Status); status {
+	case "":
+	case "OK":
+		fb.AddFilter("NOT protoPayload.status.code>0")
+	case "ERROR":
+		fb.AddFilter("protoPayload.status.code>0")
+	default:
+		value, ok := code.Code_value[status]
+		if !ok {
+			return "", fmt.Errorf("unknown status %q: use ok, error or a code name such as PERMISSION_DENIED", params.Status)
+		}
+		fb.AddFilter(fmt.Sprintf("protoPayload.status.code=%d", value))
+	}
+
+	fb.AddFilter(params.Filter)
+	return fb.Build(), nil
+}
@@ -11,6 +11,7 @@ import (
 	"cloud.google.com/go/logging"
 	"cloud.google.com/go/logging/logadmin"
 	"google.golang.org/api/iterator"
+	"google.golang.org/protobuf/types/known/structpb"
 
 	"github.com/takashabe/gco-o11y-mcp/pkg/types"
 )
@@ -22,20 +23,25 @@ type SearchLogsTool struct {
 }
 
 type SearchLogsArgs struct {
-	Query     string `json:"query"`
-	StartTime string `json:"startTime,omitempty"`
-	EndTime   string `json:"endTime,omitempty"`
-	Severity  string `json:"severity,omitempty"`
-	Resource  string `json:"resource,omitempty"`
-	LogName   string `json:"logName,omitempty"`
-	PageSize  int    `json:"pageSize,omitempty"`
-	OrderBy   string `json:"orderBy,omitempty"`
+	Query           string `json:"query"`
+	StartTime       string `json:"startTime,omitempty"`
+	EndTime         string `json:"endTime,omitempty"`
+	Severity        string `json:"severity,omitempty"`
+	Resource        string `json:"resource,omitempty"`
+	LogName         string `json:"logName,omitempty"`
+	PageSize        int    `json:"pageSize,omitempty"`
+	OrderBy         string `json:"orderBy,omitempty"`
+	Fields          string `json:"fields,omitempty"`
+	MaxPayloadBytes int    `json:"maxPayloadBytes,omitempty"`
+	Compact         bool   `json:"compact,omitempty"`
+	MaxOutputTokens int    `json:"maxOutputTokens,omitempty"`
+	PageToken       string `json:"pageToken,omitempty"`
 }
 
 func NewSearchLogsTool(client *Client) *SearchLogsTool {
 	return &SearchLogsTool{
 		client:      client,
-		cache:       NewLogCache(),
+		cache:       client.Cache(),
 		rateLimiter: NewRateLimiter(),
 	}
 }
@@ -45,7 +51,7 @@ func (t *SearchLogsTool) Name() string {
 }
 
 func (t *SearchLogsTool) Description() string {
-	return "Search log entries with advanced filtering options including text query, time range, severity level, resource type, and log name."
+	return "Search log entries with advanced filtering options including text query, time range, severity level, resource type, and log name. fields, maxPayloadBytes and compact trim the output, and maxOutputTokens and pageToken bound and page it, as in list_log_entries."
 }
 
 func (t *SearchLogsTool) Schema() types.Schema {
@@ -76,13 +82,28 @@ func (t *SearchLogsTool) Schema() types.Schema {
 			"orderBy": {
 				Type: "string",
 			},
+			"fields": {
+				Type: "string",
+			},
+			"maxPayloadBytes": {
+				Type: "integer",
+			},
+			"compact": {
+				Type: "boolean",
+			},
+			"maxOutputTokens": {
+				Type: "integer",
+			},
+			"pageToken": {
+				Type: "string",
+			},
 		},
 		Required:             []string{"query"},
 		AdditionalProperties: false,
 	}
 }
 
-func (t *SearchLogsTool) Execute(args map[string]interface{}) (*types.CallToolResult, error) {
+func (t *SearchLogsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
 	var params SearchLogsArgs
 	if argsBytes, err := json.Marshal(args); err != nil {
 		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
@@ -112,17 +133,29 @@ func (t *SearchLogsTool) Execute(args map[string]interface{}) (*types.CallToolRe
 		params.OrderBy = "timestamp desc"
 	}
 
+	view := NewEntryView(params.Fields, params.MaxPayloadBytes, params.Compact)
+	budget := outputBudget(params.MaxOutputTokens)
+
+	if _, err := parsePageToken(params.PageToken); err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	// Build optimized filter using FilterBuilder
+	filter := t.buildOptimizedFilter(params)
+
 	// Check cache first
-	cacheKey := t.cache.GenerateKey(params)
+	cacheKey := t.cache.GenerateQueryKey(filter, params.Query, params.PageSize, params.OrderBy)
+	if params.PageToken != "" {
+		cacheKey = t.cache.GenerateKey([]string{cacheKey, params.PageToken})
+	}
 	if cachedEntries, found := t.cache.Get(cacheKey); found {
 		log.Printf("Cache hit for query: %s", params.Query)
 		result := map[string]interface{}{
-			"query":   params.Query,
-			"count":   len(cachedEntries),
-			"entries": cachedEntries,
-			"cached":  true,
+			"query":  params.Query,
+			"count":  len(cachedEntries),
+			"cached": true,
 		}
-		resultJSON, err := json.MarshalIndent(result, "", "  ")
+		resultJSON, err := fitEntries(cachedEntries, view, budget, result)
 		if err != nil {
 			return nil, fmt.Errorf("failed to marshal cached results: %w", err)
 		}
@@ -134,13 +167,12 @@ func (t *SearchLogsTool) Execute(args map[string]interface{}) (*types.CallToolRe
 		}, nil
 	}
 
-	ctx := context.Background()
 	var entries []LogEntry
 	var err error
 
 	// Execute with rate limiting and backoff
 	err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
-		entries, err = t.searchLogs(ctx, params)
+		entries, err = t.searchLogs(ctx, params, filter)
 		return err
 	})
 	if err != nil {
@@ -155,20 +187,24 @@ func (t *SearchLogsTool) Execute(args map[string]interface{}) (*types.CallToolRe
 	}
 
 	// Cache the results (TTL: 2 minutes for recent logs)
-	ttl := 2 * time.Minute
-	if params.StartTime != "" {
-		// Longer TTL for historical data
-		ttl = 10 * time.Minute
+	if IsClosedWindow(params.EndTime) {
+		// Closed historical windows never change, so persist them
+		t.cache.SetHistorical(cacheKey, entries)
+	} else {
+		ttl := 2 * time.Minute
+		if params.StartTime != "" {
+			// Longer TTL for historical data
+			ttl = 10 * time.Minute
+		}
+		t.cache.Set(cacheKey, entries, ttl)
 	}
-	t.cache.Set(cacheKey, entries, ttl)
 
 	result := map[string]interface{}{
-		"query":   params.Query,
-		"count":   len(entries),
-		"entries": entries,
+		"query": params.Query,
+		"count": len(entries),
 	}
 
-	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	resultJSON, err := fitEntries(entries, view, budget, result)
 	if err != nil {
 		return nil, fmt.Errorf("failed to marshal search results: %w", err)
 	}
@@ -181,17 +217,20 @@ func (t *SearchLogsTool) Execute(args map[string]interface{}) (*types.CallToolRe
 	}, nil
 }
 
-func (t *SearchLogsTool) searchLogs(ctx context.Context, params SearchLogsArgs) ([]LogEntry, error) {
-	client := t.client.LogAdminClient()
-
-	// Build optimized filter using FilterBuilder
-	filter := t.buildOptimizedFilter(params)
-
+func (t *SearchLogsTool) searchLogs(ctx context.Context, params SearchLogsArgs, filter string) ([]LogEntry, error) {
 	// Add timeout to prevent long-running queries
 	ctxWithTimeout, cancel := context.WithTimeout(ctx, 30*time.Second)
 	defer cancel()
 
-	iter := client.Entries(ctxWithTimeout,
+	cursor, err := parsePageToken(params.PageToken)
+	if err != nil {
+		return nil, err
+	}
+	if cursor != nil {
+		filter = NewFilterBuilder().AddFilter(filter).AddFilter(cursor.Filter()).Build()
+	}
+
+	iter := t.client.Entries(ctxWithTimeout,
 		logadmin.Filter(filter),
 		logadmin.NewestFirst(),
 	)
@@ -212,35 +251,11 @@ func (t *SearchLogsTool) searchLogs(ctx context.Context, params SearchLogsArgs)
 			return nil, fmt.Errorf("failed to iterate log entries: %w", err)
 		}
 
-		if !t.matchesQuery(entry, params.Query) {
+		if cursor.Skip(entry) || !t.matchesQuery(entry, params.Query) {
 			continue
 		}
 
-		logEntry := LogEntry{
-			Timestamp: entry.Timestamp.Format(time.RFC3339),
-			Severity:  entry.Severity.String(),
-			LogName:   entry.LogName,
-			InsertID:  entry.InsertID,
-			TraceID:   entry.Trace,
-		}
-
-		if entry.Resource != nil {
-			logEntry.Resource = map[string]interface{}{
-				"type":   entry.Resource.Type,
-				"labels": entry.Resource.Labels,
-			}
-		}
-
-		if entry.Labels != nil {
-			logEntry.Labels = entry.Labels
-		}
-
-		switch payload := entry.Payload.(type) {
-		case string:
-			logEntry.TextPayload = payload
-		case map[string]interface{}:
-			logEntry.JSONPayload = payload
-		}
+		logEntry := newLogEntry(entry)
 
 		entries = append(entries, logEntry)
 		count++
@@ -318,6 +333,11 @@ func (t *SearchLogsTool) matchesQuery(entry *logging.Entry, query string) bool {
 		if strings.Contains(strings.ToLower(payload), query) {
 			return true
 		}
+	case *structpb.Struct:
+		payloadStr, _ := json.Marshal(payload.AsMap())
+		if strings.Contains(strings.ToLower(string(payloadStr)), query) {
+			return true
+		}
 	case map[string]interface{}:
 		payloadStr, _ := json.Marshal(payload)
 		if strings.Contains(strings.ToLower(string(payloadStr)), query) {
@@ -24,6 +24,7 @@ func ExtractServiceInfoFromLogEntry(entry LogEntry) *ServiceInfo {
 	info := &ServiceInfo{
 		Severity: entry.Severity,
 		TraceID:  entry.TraceID,
+		SpanID:   entry.SpanID,
 		Labels:   entry.Labels,
 	}
 
@@ -49,6 +50,16 @@ func ExtractServiceInfoFromLogEntry(entry LogEntry) *ServiceInfo {
 					info.RevisionName = revisionName
 				}
 			}
+		} else if labels, ok := entry.Resource["labels"].(map[string]interface{}); ok {
+			// Other resource types only carry a service name under a type-specific label
+			if label, ok := resourceServiceLabels[resourceType]; ok {
+				if serviceName, ok := labels[label].(string); ok {
+					info.ServiceName = serviceName
+				}
+			}
+			if projectID, ok := labels["project_id"].(string); ok {
+				info.ProjectID = projectID
+			}
 		}
 	}
 
@@ -81,6 +92,7 @@ func (si *ServiceInfo) ToLogEntry() LogEntry {
 		TextPayload: si.Message,
 		Labels:      si.Labels,
 		TraceID:     si.TraceID,
+		SpanID:      si.SpanID,
 	}
 
 	if si.JSONPayload != nil {
@@ -100,3 +112,23 @@ func (si *ServiceInfo) ToLogEntry() LogEntry {
 
 	return entry
 }
+
+// resourceServiceLabels maps monitored resource types to the label that
+// identifies the service emitting the log
+var resourceServiceLabels = map[string]string{
+	"cloud_run_revision": "service_name",
+	"cloud_function":     "function_name",
+	"gae_app":            "module_id",
+	"k8s_container":      "container_name",
+	"http_load_balancer": "backend_service_name",
+	"cloudsql_database":  "database_id",
+}
+
+// ResourceServiceName returns the service name for any supported monitored
+// resource type, or an empty string if it cannot be determined
+func ResourceServiceName(resourceType string, labels map[string]string) string {
+	if label, ok := resourceServiceLabels[resourceType]; ok {
+		return labels[label]
+	}
+	return ""
+}
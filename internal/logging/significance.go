@@ -0,0 +1,44 @@
+package logging
+
+import "math"
+
+// Differences with a z-score at least this large are flagged as significant;
+// 3 keeps false positives rare when many groups are compared at once
+const significanceZScore = 3.0
+
+// proportionZScore compares the share x1/n1 against x2/n2 with a pooled
+// two-proportion z-test. Positive scores mean the first share is larger.
+func proportionZScore(x1, n1, x2, n2 int) float64 {
+	if n1 == 0 || n2 == 0 {
+		return 0
+	}
+	p1 := float64(x1) / float64(n1)
+	p2 := float64(x2) / float64(n2)
+	pooled := float64(x1+x2) / float64(n1+n2)
+	se := math.Sqrt(pooled * (1 - pooled) * (1/float64(n1) + 1/float64(n2)))
+	if se == 0 {
+		return 0
+	}
+	return round2((p1 - p2) / se)
+}
+
+// rateZScore compares event rates c1/t1 and c2/t2 assuming Poisson counts.
+// Conditioned on the total, c1 is binomial with p = t1/(t1+t2) when the rates
+// are equal. Positive scores mean the first rate is higher.
+func rateZScore(c1 int, t1 float64, c2 int, t2 float64) float64 {
+	n := float64(c1 + c2)
+	if n == 0 || t1 <= 0 || t2 <= 0 {
+		return 0
+	}
+	p := t1 / (t1 + t2)
+	se := math.Sqrt(n * p * (1 - p))
+	return round2((float64(c1) - n*p) / se)
+}
+
+func isSignificant(z float64) bool {
+	return math.Abs(z) >= significanceZScore
+}
+
+func round2(v float64) float64 {
+	return math.Round(v*100) / 100
+}
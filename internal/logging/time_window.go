@@ -0,0 +1,58 @@
+package logging
+
+import (
+	"fmt"
+	"time"
+)
+
+// TimeWindow is an absolute time range that aggregation tools scan
+type TimeWindow struct {
+	Start time.Time
+	End   time.Time
+}
+
+// ParseTimeWindow parses RFC3339 bounds. A missing end defaults to now and a
+// missing start defaults to defaultDuration before the end.
+func ParseTimeWindow(startTime, endTime string, defaultDuration time.Duration) (TimeWindow, error) {
+	var w TimeWindow
+
+	w.End = time.Now().UTC()
+	if endTime != "" {
+		end, err := time.Parse(time.RFC3339, endTime)
+		if err != nil {
+			return w, fmt.Errorf("invalid endTime %q: %w", endTime, err)
+		}
+		w.End = end.UTC()
+	}
+
+	w.Start = w.End.Add(-defaultDuration)
+	if startTime != "" {
+		start, err := time.Parse(time.RFC3339, startTime)
+		if err != nil {
+			return w, fmt.Errorf("invalid startTime %q: %w", startTime, err)
+		}
+		w.Start = start.UTC()
+	}
+
+	if !w.Start.Before(w.End) {
+		return w, fmt.Errorf("startTime must be before endTime")
+	}
+	return w, nil
+}
+
+func (w TimeWindow) Duration() time.Duration {
+	return w.End.Sub(w.Start)
+}
+
+// IsClosed reports whether no more entries can arrive for the window
+func (w TimeWindow) IsClosed() bool {
+	return IsClosedWindow(w.End.Format(time.RFC3339))
+}
+
+// Filter combines the window bounds with an optional user filter
+func (w TimeWindow) Filter(filter string) string {
+	return NewFilterBuilder().
+		AddTimeRange(w.Start.Format(time.RFC3339), w.End.Format(time.RFC3339)).
+		AddFilter(filter).
+		Build()
+}
@@ -0,0 +1,357 @@
+package logging
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"cloud.google.com/go/logging"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	// Entries without a span are grouped under this id
+	noSpanID = "(none)"
+	// Cloud Logging only matches traces within a time range; requests rarely
+	// span more than this
+	defaultTraceWindow   = 24 * time.Hour
+	defaultTraceEntries  = 1000
+	maxTraceMessageChars = 1000
+)
+
+var (
+	traceNamePattern = regexp.MustCompile(`^projects/[^/]+/traces/[0-9a-fA-F]+$`)
+	traceIDPattern   = regexp.MustCompile(`^[0-9a-fA-F]{1,32}$`)
+)
+
+type GetTraceLogsTool struct {
+	client      *Client
+	cache       *LogCache
+	rateLimiter *RateLimiter
+}
+
+type GetTraceLogsArgs struct {
+	TraceID    string `json:"traceId"`
+	StartTime  string `json:"startTime,omitempty"`
+	EndTime    string `json:"endTime,omitempty"`
+	MaxEntries int    `json:"maxEntries,omitempty"`
+	Fields     string `json:"fields,omitempty"`
+	// Cuts messages shorter than the default limit
+	MaxPayloadBytes int  `json:"maxPayloadBytes,omitempty"`
+	Compact         bool `json:"compact,omitempty"`
+}
+
+type traceLogLine struct {
+	Timestamp string  `json:"timestamp"`
+	OffsetMs  float64 `json:"offsetMs"`
+	Severity  string  `json:"severity"`
+	Service   string  `json:"service,omitempty"`
+	Message   string  `json:"message"`
+	InsertID  string  `json:"insertId,omitempty"`
+}
+
+type traceSpanLogs struct {
+	SpanID     string         `json:"spanId"`
+	Services   []string       `json:"services,omitempty"`
+	Start      string         `json:"start"`
+	DurationMs float64        `json:"durationMs"`
+	MaxLevel   string         `json:"maxSeverity"`
+	Entries    []traceLogLine `json:"entries"`
+}
+
+// viewedTraceLogs shows a traceLogsResult with its entries projected by an
+// EntryView. The outer Spans and Entries fields hide the embedded ones when
+// encoded.
+type viewedTraceLogs struct {
+	traceLogsResult
+	Spans []viewedSpanLogs `json:"spans"`
+}
+
+type viewedSpanLogs struct {
+	traceSpanLogs
+	Entries []interface{} `json:"entries"`
+}
+
+type traceLogsResult struct {
+	Trace      string          `json:"trace"`
+	EntryCount int             `json:"entryCount"`
+	Truncated  bool            `json:"truncated"`
+	Services   []string        `json:"services,omitempty"`
+	Start      string          `json:"start,omitempty"`
+	DurationMs float64         `json:"durationMs"`
+	Spans      []traceSpanLogs `json:"spans"`
+}
+
+func NewGetTraceLogsTool(client *Client) *GetTraceLogsTool {
+	return &GetTraceLogsTool{
+		client:      client,
+		cache:       client.Cache(),
+		rateLimiter: NewRateLimiter(),
+	}
+}
+
+func (t *GetTraceLogsTool) Name() string {
+	return "get_trace_logs"
+}
+
+func (t *GetTraceLogsTool) Description() string {
+	return "Fetch every log entry for a trace across services, ordered by timestamp and grouped by spanId, to follow a single request end to end. Accepts a trace id or a full projects/*/traces/* name. Searches the last 24 hours unless startTime/endTime are given. fields keeps only the given comma separated entry fields (timestamp, offsetMs, severity, service, message, insertId), maxPayloadBytes cuts each message to a byte budget and compact drops indentation."
+}
+
+func (t *GetTraceLogsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"traceId": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"maxEntries": {
+				Type: "integer",
+			},
+			"fields": {
+				Type: "string",
+			},
+			"maxPayloadBytes": {
+				Type: "integer",
+			},
+			"compact": {
+				Type: "boolean",
+			},
+		},
+		Required:             []string{"traceId"},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *GetTraceLogsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params GetTraceLogsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	trace, err := TraceName(t.client.ProjectID(), params.TraceID)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	window, err := ParseTimeWindow(params.StartTime, params.EndTime, defaultTraceWindow)
+	if err != nil {
+		return ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	if params.MaxEntries <= 0 {
+		params.MaxEntries = defaultTraceEntries
+	}
+	params.MaxEntries = scanLimit(params.MaxEntries)
+
+	filter := window.Filter(fmt.Sprintf(`trace="%s"`, trace))
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":       t.Name(),
+		"filter":     NormalizeFilter(filter, t.cache.TimeBucket()),
+		"maxEntries": params.MaxEntries,
+	})
+
+	var result traceLogsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for trace logs: %s", trace)
+	} else {
+		var entries []*logging.Entry
+		var truncated bool
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			entries = entries[:0]
+			_, truncated, err = scanEntries(ctx, t.client, filter, params.MaxEntries, func(entry *logging.Entry) {
+				entries = append(entries, entry)
+			})
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to fetch trace logs: %v", err)
+			return ErrorResult(fmt.Sprintf("Error fetching trace logs: %v", err)), nil
+		}
+
+		result = buildTraceLogs(trace, entries)
+		result.Truncated = truncated
+
+		// Requests finish quickly, so an old window will not receive more entries
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, 2*time.Minute)
+		}
+	}
+
+	if result.EntryCount == 0 {
+		return &types.CallToolResult{
+			Content: []types.Content{{
+				Type: "text",
+				Text: fmt.Sprintf("No log entries found for %s between %s and %s", trace, window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339)),
+			}},
+		}, nil
+	}
+
+	if params.MaxPayloadBytes > 0 {
+		for _, span := range result.Spans {
+			for i := range span.Entries {
+				if message, ok := truncateValue(span.Entries[i].Message, params.MaxPayloadBytes).(string); ok {
+					span.Entries[i].Message = message
+				}
+			}
+		}
+	}
+
+	view := NewEntryView(params.Fields, params.MaxPayloadBytes, params.Compact)
+	resultJSON, err := view.Marshal(viewTraceLogs(result, view))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal trace logs: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// TraceName returns the full trace resource name for a trace id, or validates
+// a full name that was passed in
+func TraceName(projectID, trace string) (string, error) {
+	trace = strings.TrimSpace(trace)
+	if traceNamePattern.MatchString(trace) {
+		return trace, nil
+	}
+	if !traceIDPattern.MatchString(trace) {
+		return "", fmt.Errorf("%q is neither a trace id nor a projects/*/traces/* name", trace)
+	}
+	return fmt.Sprintf("projects/%s/traces/%s", projectID, strings.ToLower(trace)), nil
+}
+
+// viewTraceLogs projects the entries of every span when the view has fields
+func viewTraceLogs(result traceLogsResult, view EntryView) interface{} {
+	if len(view.Fields) == 0 {
+		return result
+	}
+	viewed := viewedTraceLogs{traceLogsResult: result, Spans: make([]viewedSpanLogs, len(result.Spans))}
+	for i, span := range result.Spans {
+		viewed.Spans[i] = viewedSpanLogs{traceSpanLogs: span, Entries: ApplyAll(view, span.Entries)}
+	}
+	return viewed
+}
+
+// buildTraceLogs orders entries by timestamp and groups them by span, with
+// spans ordered by their first entry
+func buildTraceLogs(trace string, entries []*logging.Entry) traceLogsResult {
+	result := traceLogsResult{
+		Trace:      trace,
+		EntryCount: len(entries),
+		Spans:      []traceSpanLogs{},
+	}
+	if len(entries) == 0 {
+		return result
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	start := entries[0].Timestamp
+	end := entries[len(entries)-1].Timestamp
+	result.Start = start.Format(time.RFC3339Nano)
+	result.DurationMs = milliseconds(end.Sub(start))
+
+	type spanState struct {
+		logs     *traceSpanLogs
+		start    time.Time
+		end      time.Time
+		maxLevel logging.Severity
+		services map[string]bool
+	}
+	spans := make(map[string]*spanState)
+	var order []string
+	services := make(map[string]bool)
+
+	for _, entry := range entries {
+		info := ExtractServiceInfoFromLogEntry(newLogEntry(entry))
+		spanID := entry.SpanID
+		if spanID == "" {
+			spanID = noSpanID
+		}
+
+		span, exists := spans[spanID]
+		if !exists {
+			span = &spanState{
+				logs:     &traceSpanLogs{SpanID: spanID},
+				start:    entry.Timestamp,
+				services: make(map[string]bool),
+			}
+			spans[spanID] = span
+			order = append(order, spanID)
+		}
+		span.end = entry.Timestamp
+		if entry.Severity > span.maxLevel {
+			span.maxLevel = entry.Severity
+		}
+		if info.ServiceName != "" {
+			span.services[info.ServiceName] = true
+			services[info.ServiceName] = true
+		}
+
+		span.logs.Entries = append(span.logs.Entries, traceLogLine{
+			Timestamp: entry.Timestamp.Format(time.RFC3339Nano),
+			OffsetMs:  milliseconds(entry.Timestamp.Sub(start)),
+			Severity:  strings.ToUpper(entry.Severity.String()),
+			Service:   info.ServiceName,
+			Message:   Truncate(info.Message, maxTraceMessageChars),
+			InsertID:  entry.InsertID,
+		})
+	}
+
+	for _, spanID := range order {
+		span := spans[spanID]
+		span.logs.Start = span.start.Format(time.RFC3339Nano)
+		span.logs.DurationMs = milliseconds(span.end.Sub(span.start))
+		span.logs.MaxLevel = strings.ToUpper(span.maxLevel.String())
+		span.logs.Services = sortedKeys(span.services)
+		result.Spans = append(result.Spans, *span.logs)
+	}
+	result.Services = sortedKeys(services)
+	return result
+}
+
+func milliseconds(d time.Duration) float64 {
+	return round2(float64(d) / float64(time.Millisecond))
+}
+
+// TraceEntries returns the entries of a trace within the window in timestamp
+// order, for joining logs with data from other APIs
+func (c *Client) TraceEntries(ctx context.Context, trace string, window TimeWindow, limit int) ([]LogEntry, bool, error) {
+	var entries []*logging.Entry
+	_, truncated, err := scanEntries(ctx, c, window.Filter(fmt.Sprintf(`trace="%s"`, trace)), scanLimit(limit), func(entry *logging.Entry) {
+		entries = append(entries, entry)
+	})
+	if err != nil {
+		return nil, false, err
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Timestamp.Before(entries[j].Timestamp)
+	})
+	logEntries := make([]LogEntry, len(entries))
+	for i, entry := range entries {
+		logEntries[i] = newLogEntry(entry)
+	}
+	return logEntries, truncated, nil
+}
@@ -0,0 +1,70 @@
+package logging
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"cloud.google.com/go/logging"
+)
+
+func TestBuildTraceLogs(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	long := "xy" + strings.Repeat("注文の処理に失敗", 60)
+	entries := []*logging.Entry{
+		{Timestamp: start.Add(30 * time.Millisecond), Severity: logging.Error, SpanID: "b", Payload: long, InsertID: "3"},
+		{Timestamp: start, Severity: logging.Info, SpanID: "a", Payload: "request started", InsertID: "1"},
+		{Timestamp: start.Add(10 * time.Millisecond), Severity: logging.Warning, SpanID: "a", Payload: "slow query", InsertID: "2"},
+	}
+
+	result := buildTraceLogs("projects/example-project/traces/abc", entries)
+	if result.EntryCount != 3 || result.DurationMs != 30 || len(result.Spans) != 2 {
+		t.Fatalf("got %d entries over %vms in %d spans", result.EntryCount, result.DurationMs, len(result.Spans))
+	}
+
+	first := result.Spans[0]
+	if first.SpanID != "a" || first.MaxLevel != "WARNING" || len(first.Entries) != 2 || first.Entries[1].OffsetMs != 10 {
+		t.Errorf("unexpected first span %+v", first)
+	}
+
+	message := result.Spans[1].Entries[0].Message
+	if !utf8.ValidString(message) || !strings.HasSuffix(message, "...") || len(message) > maxTraceMessageChars+len("...") {
+		t.Errorf("long message was not cut on a character boundary: %q", message)
+	}
+}
+
+func TestViewTraceLogsFields(t *testing.T) {
+	start := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+	result := buildTraceLogs("projects/example-project/traces/abc", []*logging.Entry{
+		{Timestamp: start, Severity: logging.Error, SpanID: "a", Payload: "charge failed", InsertID: "1"},
+	})
+
+	data, err := json.Marshal(viewTraceLogs(result, NewEntryView("severity, message", 0, false)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got struct {
+		Trace string `json:"trace"`
+		Spans []struct {
+			SpanID  string                   `json:"spanId"`
+			Entries []map[string]interface{} `json:"entries"`
+		} `json:"spans"`
+	}
+	if err := json.Unmarshal(data, &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Trace != result.Trace || len(got.Spans) != 1 || got.Spans[0].SpanID != "a" {
+		t.Fatalf("projection changed the spans: %s", data)
+	}
+	want := map[string]interface{}{"timestamp": result.Spans[0].Entries[0].Timestamp, "severity": "ERROR", "message": "charge failed"}
+	if entry := got.Spans[0].Entries[0]; len(entry) != len(want) || entry["severity"] != want["severity"] || entry["message"] != want["message"] || entry["timestamp"] != want["timestamp"] {
+		t.Errorf("entry = %v, want %v", entry, want)
+	}
+
+	// Without fields the result is returned as is
+	if _, ok := viewTraceLogs(result, NewEntryView("", 100, true)).(traceLogsResult); !ok {
+		t.Error("result without fields was converted")
+	}
+}
@@ -1,6 +1,7 @@
 package mcp
 
 import (
+	"context"
 	"fmt"
 	"log"
 
@@ -11,7 +12,7 @@ type Tool interface {
 	Name() string
 	Description() string
 	Schema() types.Schema
-	Execute(args map[string]interface{}) (*types.CallToolResult, error)
+	Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error)
 }
 
 type MCPServer struct {
@@ -125,7 +126,7 @@ func (s *MCPServer) handleCallTool(id interface{}, request map[string]interface{
 
 	log.Printf("Tool arguments: %v", arguments)
 
-	result, err := tool.Execute(arguments)
+	result, err := tool.Execute(context.Background(), arguments)
 	if err != nil {
 		log.Printf("Tool execution error: %v", err)
 		return s.createErrorResponse(id, -32603, "Internal error", err.Error())
@@ -160,7 +161,23 @@ func (s *MCPServer) convertSchemaToMap(schema types.Schema) map[string]interface
 	}
 
 	if schema.AdditionalProperties != nil {
-		result["additionalProperties"] = schema.AdditionalProperties
+		if additional, ok := schema.AdditionalProperties.(types.Schema); ok {
+			result["additionalProperties"] = s.convertSchemaToMap(additional)
+		} else {
+			result["additionalProperties"] = schema.AdditionalProperties
+		}
+	}
+
+	if schema.Description != "" {
+		result["description"] = schema.Description
+	}
+
+	if schema.Default != nil {
+		result["default"] = schema.Default
+	}
+
+	if len(schema.Enum) > 0 {
+		result["enum"] = schema.Enum
 	}
 
 	return result
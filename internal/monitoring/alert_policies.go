@@ -0,0 +1,151 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultPolicyLimit = 50
+	maxPolicyLimit     = 200
+	// Alerting configuration changes rarely
+	alertConfigTTL = 5 * time.Minute
+)
+
+type ListAlertPoliciesTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListAlertPoliciesArgs struct {
+	Query       string `json:"query,omitempty"`
+	EnabledOnly bool   `json:"enabledOnly,omitempty"`
+	Limit       int    `json:"limit,omitempty"`
+}
+
+type listAlertPoliciesResult struct {
+	Count     int           `json:"count"`
+	Truncated bool          `json:"truncated,omitempty"`
+	Policies  []alertPolicy `json:"policies"`
+}
+
+func NewListAlertPoliciesTool(api API, projectID string, cache *logging.LogCache) *ListAlertPoliciesTool {
+	return &ListAlertPoliciesTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListAlertPoliciesTool) Name() string {
+	return "list_alert_policies"
+}
+
+func (t *ListAlertPoliciesTool) Description() string {
+	return "List Cloud Monitoring alerting policies with their conditions (metric filter or query, comparison, threshold, duration, aggregation), severity, notification channel ids and documentation. query matches display names case-insensitively."
+}
+
+func (t *ListAlertPoliciesTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"query": {
+				Type: "string",
+			},
+			"enabledOnly": {
+				Type: "boolean",
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListAlertPoliciesTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListAlertPoliciesArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = defaultPolicyLimit
+	}
+	if params.Limit > maxPolicyLimit {
+		params.Limit = maxPolicyLimit
+	}
+
+	policies, err := loadAlertPolicies(ctx, t.api, t.projectID, t.cache, t.rateLimiter)
+	if err != nil {
+		log.Printf("Failed to list alert policies: %v", err)
+		return logging.ErrorResult(fmt.Sprintf("Error listing alert policies: %v", err)), nil
+	}
+
+	result := listAlertPoliciesResult{Policies: []alertPolicy{}}
+	query := strings.ToLower(params.Query)
+	for _, policy := range policies {
+		if params.EnabledOnly && !policy.Enabled {
+			continue
+		}
+		if query != "" && !strings.Contains(strings.ToLower(policy.DisplayName), query) {
+			continue
+		}
+		if len(result.Policies) == params.Limit {
+			result.Truncated = true
+			break
+		}
+		result.Policies = append(result.Policies, summarizePolicy(policy))
+	}
+	result.Count = len(result.Policies)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal alert policies: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// loadAlertPolicies reads every policy of the project. Policies are few and
+// filtered locally, so one cached listing serves all queries and incidents.
+func loadAlertPolicies(ctx context.Context, api API, projectID string, cache *logging.LogCache, rateLimiter *logging.RateLimiter) ([]*monitoring.AlertPolicy, error) {
+	cacheKey := cache.GenerateKey(map[string]interface{}{
+		"kind":      "alert_policies",
+		"projectId": projectID,
+	})
+
+	var policies []*monitoring.AlertPolicy
+	if cache.GetValue(cacheKey, &policies) {
+		return policies, nil
+	}
+	err := rateLimiter.ExecuteWithBackoff(ctx, func() error {
+		var err error
+		policies, err = api.ListAlertPolicies(ctx, projectID, "")
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	cache.SetValue(cacheKey, policies, alertConfigTTL)
+	return policies, nil
+}
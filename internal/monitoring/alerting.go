@@ -0,0 +1,241 @@
+package monitoring
+
+import (
+	"fmt"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+const (
+	// Logs are searched from before the incident opened, since the condition
+	// must hold for its duration and alignment period before it fires
+	incidentLookback = 10 * time.Minute
+	incidentTrail    = 5 * time.Minute
+	maxDocumentation = 1000
+)
+
+// Monitored resource types whose logs use a different resource type
+var logResourceTypes = map[string]string{
+	"https_lb_rule": "http_load_balancer",
+}
+
+// Monitored resource types that have no logs of their own
+var resourcesWithoutLogs = map[string]bool{
+	"global":     true,
+	"uptime_url": true,
+}
+
+type policyCondition struct {
+	Name        string   `json:"name"`
+	Kind        string   `json:"kind"`
+	Filter      string   `json:"filter,omitempty"`
+	Query       string   `json:"query,omitempty"`
+	Comparison  string   `json:"comparison,omitempty"`
+	Threshold   *float64 `json:"threshold,omitempty"`
+	Duration    string   `json:"duration,omitempty"`
+	Aggregation string   `json:"aggregation,omitempty"`
+}
+
+type alertPolicy struct {
+	ID                   string            `json:"id"`
+	DisplayName          string            `json:"displayName"`
+	Enabled              bool              `json:"enabled"`
+	Severity             string            `json:"severity,omitempty"`
+	Combiner             string            `json:"combiner,omitempty"`
+	Conditions           []policyCondition `json:"conditions"`
+	NotificationChannels []string          `json:"notificationChannels,omitempty"`
+	Documentation        string            `json:"documentation,omitempty"`
+	UserLabels           map[string]string `json:"userLabels,omitempty"`
+}
+
+// incidentLogs locates the log entries around an incident. Filter already
+// contains the time range; the bounds are repeated for tools that take them
+// separately.
+type incidentLogs struct {
+	Filter    string `json:"filter"`
+	StartTime string `json:"startTime"`
+	EndTime   string `json:"endTime,omitempty"`
+	Note      string `json:"note,omitempty"`
+}
+
+func summarizePolicy(policy *monitoring.AlertPolicy) alertPolicy {
+	summary := alertPolicy{
+		ID:          path.Base(policy.Name),
+		DisplayName: policy.DisplayName,
+		Enabled:     policy.Enabled,
+		Severity:    policy.Severity,
+		Combiner:    policy.Combiner,
+		Conditions:  []policyCondition{},
+		UserLabels:  policy.UserLabels,
+	}
+	for _, c := range policy.Conditions {
+		summary.Conditions = append(summary.Conditions, summarizeCondition(c))
+	}
+	for _, channel := range policy.NotificationChannels {
+		summary.NotificationChannels = append(summary.NotificationChannels, path.Base(channel))
+	}
+	if policy.Documentation != nil {
+		summary.Documentation = logging.Truncate(policy.Documentation.Content, maxDocumentation)
+	}
+	return summary
+}
+
+func summarizeCondition(c *monitoring.Condition) policyCondition {
+	summary := policyCondition{Name: c.DisplayName}
+	switch {
+	case c.ConditionThreshold != nil:
+		t := c.ConditionThreshold
+		summary.Kind = "threshold"
+		summary.Filter = t.Filter
+		summary.Comparison = t.Comparison
+		threshold := t.ThresholdValue
+		summary.Threshold = &threshold
+		summary.Duration = t.Duration
+		summary.Aggregation = describeAggregations(t.Aggregations)
+	case c.ConditionAbsent != nil:
+		summary.Kind = "absent"
+		summary.Filter = c.ConditionAbsent.Filter
+		summary.Duration = c.ConditionAbsent.Duration
+		summary.Aggregation = describeAggregations(c.ConditionAbsent.Aggregations)
+	case c.ConditionMatchedLog != nil:
+		summary.Kind = "log_match"
+		summary.Filter = c.ConditionMatchedLog.Filter
+	case c.ConditionMonitoringQueryLanguage != nil:
+		summary.Kind = "mql"
+		summary.Query = c.ConditionMonitoringQueryLanguage.Query
+		summary.Duration = c.ConditionMonitoringQueryLanguage.Duration
+	case c.ConditionPrometheusQueryLanguage != nil:
+		summary.Kind = "promql"
+		summary.Query = c.ConditionPrometheusQueryLanguage.Query
+		summary.Duration = c.ConditionPrometheusQueryLanguage.Duration
+	case c.ConditionSql != nil:
+		summary.Kind = "sql"
+		summary.Query = c.ConditionSql.Query
+	default:
+		summary.Kind = "unknown"
+	}
+	return summary
+}
+
+// describeAggregations renders aggregations compactly, e.g.
+// "60s ALIGN_RATE REDUCE_SUM by resource.label.service_name"
+func describeAggregations(aggregations []*monitoring.Aggregation) string {
+	var parts []string
+	for _, a := range aggregations {
+		var fields []string
+		for _, f := range []string{a.AlignmentPeriod, a.PerSeriesAligner, a.CrossSeriesReducer} {
+			if f != "" {
+				fields = append(fields, f)
+			}
+		}
+		if len(a.GroupByFields) > 0 {
+			fields = append(fields, "by "+strings.Join(a.GroupByFields, ","))
+		}
+		parts = append(parts, strings.Join(fields, " "))
+	}
+	return strings.Join(parts, "; ")
+}
+
+// incidentLogQuery builds a list_log_entries filter for the entries around an
+// incident. Log-based incidents reuse the policy's log filter; metric-based
+// ones select the incident's monitored resource. policy may be nil when it
+// was deleted or could not be read.
+func incidentLogQuery(alert *Alert, policy *monitoring.AlertPolicy, now time.Time) incidentLogs {
+	var logs incidentLogs
+	fb := logging.NewFilterBuilder()
+
+	opened, err := time.Parse(time.RFC3339Nano, alert.OpenTime)
+	if err != nil {
+		opened = now
+	}
+	start := opened.Add(-incidentLookback - conditionDuration(policy))
+	logs.StartTime = start.UTC().Format(time.RFC3339)
+	if closed, err := time.Parse(time.RFC3339Nano, alert.CloseTime); err == nil {
+		logs.EndTime = closed.Add(incidentTrail).UTC().Format(time.RFC3339)
+	}
+	fb.AddTimeRange(logs.StartTime, logs.EndTime)
+
+	if logFilter := logMatchFilter(policy); logFilter != "" {
+		fb.AddFilter(logFilter)
+	} else if clause, ok := resourceClause(alert.Resource); ok {
+		fb.AddFilter(clause)
+	} else {
+		// Without a resource to scope on, fall back to everything that went wrong
+		fb.AddSeverity("WARNING")
+		logs.Note = "The incident's resource has no logs; the filter covers all warnings and errors in the window"
+	}
+
+	logs.Filter = fb.Build()
+	return logs
+}
+
+// conditionDuration is the longest time a condition of the policy must hold
+// before it fires
+func conditionDuration(policy *monitoring.AlertPolicy) time.Duration {
+	if policy == nil {
+		return 0
+	}
+	var longest time.Duration
+	for _, c := range policy.Conditions {
+		var value string
+		switch {
+		case c.ConditionThreshold != nil:
+			value = c.ConditionThreshold.Duration
+		case c.ConditionAbsent != nil:
+			value = c.ConditionAbsent.Duration
+		case c.ConditionMonitoringQueryLanguage != nil:
+			value = c.ConditionMonitoringQueryLanguage.Duration
+		case c.ConditionPrometheusQueryLanguage != nil:
+			value = c.ConditionPrometheusQueryLanguage.Duration
+		}
+		if d, err := time.ParseDuration(value); err == nil && d > longest {
+			longest = d
+		}
+	}
+	return longest
+}
+
+func logMatchFilter(policy *monitoring.AlertPolicy) string {
+	if policy == nil {
+		return ""
+	}
+	for _, c := range policy.Conditions {
+		if c.ConditionMatchedLog != nil && c.ConditionMatchedLog.Filter != "" {
+			return c.ConditionMatchedLog.Filter
+		}
+	}
+	return ""
+}
+
+// resourceClause selects the log entries of a monitored resource. Monitored
+// resources and log resources share label names, so labels carry over as is.
+func resourceClause(resource *monitoring.MonitoredResource) (string, bool) {
+	if resource == nil || resource.Type == "" || resourcesWithoutLogs[resource.Type] {
+		return "", false
+	}
+
+	resourceType := resource.Type
+	if mapped, ok := logResourceTypes[resourceType]; ok {
+		resourceType = mapped
+	}
+	clauses := []string{fmt.Sprintf(`resource.type="%s"`, resourceType)}
+
+	labels := make([]string, 0, len(resource.Labels))
+	for k := range resource.Labels {
+		// Entries are already scoped to the project
+		if k != "project_id" {
+			labels = append(labels, k)
+		}
+	}
+	sort.Strings(labels)
+	for _, k := range labels {
+		clauses = append(clauses, fmt.Sprintf(`resource.labels.%s="%s"`, k, logging.EscapeFilterValue(resource.Labels[k])))
+	}
+	return strings.Join(clauses, " AND "), true
+}
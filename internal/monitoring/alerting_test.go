@@ -0,0 +1,211 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+func TestConditionDuration(t *testing.T) {
+	tests := []struct {
+		name   string
+		policy *monitoring.AlertPolicy
+		want   time.Duration
+	}{
+		{name: "no policy", want: 0},
+		{
+			name: "longest condition",
+			policy: &monitoring.AlertPolicy{Conditions: []*monitoring.Condition{
+				{ConditionThreshold: &monitoring.MetricThreshold{Duration: "300s"}},
+				{ConditionAbsent: &monitoring.MetricAbsence{Duration: "600s"}},
+			}},
+			want: 10 * time.Minute,
+		},
+		{
+			name: "query languages",
+			policy: &monitoring.AlertPolicy{Conditions: []*monitoring.Condition{
+				{ConditionMonitoringQueryLanguage: &monitoring.MonitoringQueryLanguageCondition{Duration: "120s"}},
+				{ConditionPrometheusQueryLanguage: &monitoring.PrometheusQueryLanguageCondition{Duration: "60s"}},
+			}},
+			want: 2 * time.Minute,
+		},
+		{
+			name: "log match and unset durations",
+			policy: &monitoring.AlertPolicy{Conditions: []*monitoring.Condition{
+				{ConditionMatchedLog: &monitoring.LogMatch{Filter: "severity>=ERROR"}},
+				{ConditionThreshold: &monitoring.MetricThreshold{}},
+			}},
+			want: 0,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := conditionDuration(tt.policy); got != tt.want {
+				t.Errorf("conditionDuration() = %s, want %s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestResourceClause(t *testing.T) {
+	tests := []struct {
+		name     string
+		resource *monitoring.MonitoredResource
+		want     string
+		ok       bool
+	}{
+		{name: "no resource"},
+		{name: "no type", resource: &monitoring.MonitoredResource{Labels: map[string]string{"zone": "a"}}},
+		{
+			name: "labels sorted without project",
+			resource: &monitoring.MonitoredResource{Type: "cloud_run_revision", Labels: map[string]string{
+				"project_id":    "example-project",
+				"service_name":  "checkout",
+				"location":      "asia-northeast1",
+				"revision_name": "checkout-00042-abc",
+			}},
+			want: `resource.type="cloud_run_revision" AND resource.labels.location="asia-northeast1" AND resource.labels.revision_name="checkout-00042-abc" AND resource.labels.service_name="checkout"`,
+			ok:   true,
+		},
+		{
+			name:     "log resource type differs",
+			resource: &monitoring.MonitoredResource{Type: "https_lb_rule", Labels: map[string]string{"url_map_name": "web"}},
+			want:     `resource.type="http_load_balancer" AND resource.labels.url_map_name="web"`,
+			ok:       true,
+		},
+		{
+			name:     "escapes values",
+			resource: &monitoring.MonitoredResource{Type: "k8s_container", Labels: map[string]string{"container_name": `a"b`}},
+			want:     `resource.type="k8s_container" AND resource.labels.container_name="a\"b"`,
+			ok:       true,
+		},
+		{name: "global", resource: &monitoring.MonitoredResource{Type: "global"}},
+		{name: "uptime check", resource: &monitoring.MonitoredResource{Type: "uptime_url", Labels: map[string]string{"host": "example.com"}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := resourceClause(tt.resource)
+			if got != tt.want || ok != tt.ok {
+				t.Errorf("resourceClause() = %q, %v, want %q, %v", got, ok, tt.want, tt.ok)
+			}
+		})
+	}
+}
+
+func TestIncidentLogQuery(t *testing.T) {
+	now := time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)
+	run := &monitoring.MonitoredResource{Type: "cloud_run_revision", Labels: map[string]string{"service_name": "checkout"}}
+	threshold := &monitoring.AlertPolicy{Conditions: []*monitoring.Condition{
+		{ConditionThreshold: &monitoring.MetricThreshold{Duration: "300s"}},
+	}}
+	logMatch := &monitoring.AlertPolicy{Conditions: []*monitoring.Condition{
+		{ConditionMatchedLog: &monitoring.LogMatch{Filter: ` severity>=ERROR AND textPayload:"panic" `}},
+	}}
+
+	tests := []struct {
+		name   string
+		alert  *Alert
+		policy *monitoring.AlertPolicy
+		want   incidentLogs
+	}{
+		{
+			name:   "open metric incident",
+			alert:  &Alert{OpenTime: "2024-05-01T10:30:00Z", Resource: run},
+			policy: threshold,
+			want: incidentLogs{
+				Filter:    `timestamp >= "2024-05-01T10:15:00Z" AND (resource.type="cloud_run_revision" AND resource.labels.service_name="checkout")`,
+				StartTime: "2024-05-01T10:15:00Z",
+			},
+		},
+		{
+			name:   "closed log incident",
+			alert:  &Alert{OpenTime: "2024-05-01T10:30:00.5Z", CloseTime: "2024-05-01T11:00:00Z", Resource: run},
+			policy: logMatch,
+			want: incidentLogs{
+				Filter:    `timestamp >= "2024-05-01T10:20:00Z" AND timestamp <= "2024-05-01T11:05:00Z" AND (severity>=ERROR AND textPayload:"panic")`,
+				StartTime: "2024-05-01T10:20:00Z",
+				EndTime:   "2024-05-01T11:05:00Z",
+			},
+		},
+		{
+			name:  "deleted policy and resource without logs",
+			alert: &Alert{OpenTime: "2024-05-01T10:30:00Z", Resource: &monitoring.MonitoredResource{Type: "uptime_url"}},
+			want: incidentLogs{
+				Filter:    `timestamp >= "2024-05-01T10:20:00Z" AND severity >= WARNING`,
+				StartTime: "2024-05-01T10:20:00Z",
+				Note:      "The incident's resource has no logs; the filter covers all warnings and errors in the window",
+			},
+		},
+		{
+			name:   "unreadable open time",
+			alert:  &Alert{OpenTime: "soon", Resource: run},
+			policy: threshold,
+			want: incidentLogs{
+				Filter:    `timestamp >= "2024-05-01T11:45:00Z" AND (resource.type="cloud_run_revision" AND resource.labels.service_name="checkout")`,
+				StartTime: "2024-05-01T11:45:00Z",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := incidentLogQuery(tt.alert, tt.policy, now); got != tt.want {
+				t.Errorf("incidentLogQuery() =\n%+v\nwant\n%+v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestGetIncident(t *testing.T) {
+	policy := &monitoring.AlertPolicy{
+		Name:        "projects/example-project/alertPolicies/123",
+		DisplayName: "Checkout errors",
+		Conditions: []*monitoring.Condition{{
+			DisplayName:        "5xx rate",
+			ConditionThreshold: &monitoring.MetricThreshold{Filter: `metric.type="run.googleapis.com/request_count"`, Duration: "300s", ThresholdValue: 5},
+		}},
+	}
+	api := &fakeAPI{
+		Policies: []*monitoring.AlertPolicy{policy},
+		Alerts: []*Alert{{
+			Name:      "projects/example-project/alerts/0.abc",
+			State:     "CLOSED",
+			OpenTime:  "2024-05-01T10:30:00Z",
+			CloseTime: "2024-05-01T10:45:30Z",
+			Resource:  &monitoring.MonitoredResource{Type: "cloud_run_revision", Labels: map[string]string{"project_id": "example-project", "service_name": "checkout"}},
+			Policy:    &AlertPolicyRef{Name: policy.Name, DisplayName: policy.DisplayName, Severity: "ERROR"},
+		}},
+	}
+	tool := NewGetIncidentTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"incidentId": "0.abc"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+	var got getIncidentResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+
+	incident := got.Incident
+	if incident.ID != "0.abc" || incident.Policy != "Checkout errors" || incident.PolicyID != "123" || incident.Duration != "15m30s" {
+		t.Errorf("unexpected incident %+v", incident)
+	}
+	// The policy's condition duration widens the window the logs are read from
+	if want := `timestamp >= "2024-05-01T10:15:00Z" AND timestamp <= "2024-05-01T10:50:30Z" AND (resource.type="cloud_run_revision" AND resource.labels.service_name="checkout")`; incident.Logs.Filter != want {
+		t.Errorf("logs.filter =\n%s\nwant\n%s", incident.Logs.Filter, want)
+	}
+	if got.Policy == nil || len(got.Policy.Conditions) != 1 || got.Policy.Conditions[0].Kind != "threshold" {
+		t.Errorf("alertPolicy = %+v", got.Policy)
+	}
+}
@@ -0,0 +1,331 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+
+	"google.golang.org/api/googleapi"
+	monitoring "google.golang.org/api/monitoring/v3"
+	"google.golang.org/api/option"
+	htransport "google.golang.org/api/transport/http"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
+)
+
+// TimeSeriesQuery selects and aggregates time series; fields map directly to
+// the projects.timeSeries.list parameters
+type TimeSeriesQuery struct {
+	Filter          string
+	StartTime       string
+	EndTime         string
+	AlignmentPeriod string
+	Aligner         string
+	Reducer         string
+	GroupBy         []string
+	// Upper bound on the number of series returned across pages
+	MaxSeries int
+}
+
+// AlertsQuery selects incidents; fields map directly to the
+// projects.alerts.list parameters
+type AlertsQuery struct {
+	Filter  string
+	OrderBy string
+	// Upper bound on the number of incidents returned across pages
+	MaxAlerts int
+}
+
+// Alert is an incident as returned by projects.alerts. The generated client
+// predates that resource, so it is declared here with the fields we use.
+type Alert struct {
+	Name      string                        `json:"name"`
+	State     string                        `json:"state"`
+	OpenTime  string                        `json:"openTime"`
+	CloseTime string                        `json:"closeTime,omitempty"`
+	Resource  *monitoring.MonitoredResource `json:"resource,omitempty"`
+	Metric    *monitoring.Metric            `json:"metric,omitempty"`
+	Policy    *AlertPolicyRef               `json:"policy,omitempty"`
+	Log       *AlertLog                     `json:"log,omitempty"`
+}
+
+// AlertPolicyRef is the snapshot of the policy an incident was opened for
+type AlertPolicyRef struct {
+	Name        string            `json:"name"`
+	DisplayName string            `json:"displayName"`
+	Severity    string            `json:"severity,omitempty"`
+	UserLabels  map[string]string `json:"userLabels,omitempty"`
+}
+
+// AlertLog holds the labels extracted from the log entry that opened a
+// log-based incident
+type AlertLog struct {
+	ExtractedLabels map[string]string `json:"extractedLabels,omitempty"`
+}
+
+type listAlertsResponse struct {
+	Alerts        []*Alert `json:"alerts"`
+	NextPageToken string   `json:"nextPageToken"`
+}
+
+// API is the subset of the Cloud Monitoring API used by the tools. It is an
+// interface so that recorded responses can stand in for the real service.
+type API interface {
+	ListTimeSeries(ctx context.Context, projectID string, query TimeSeriesQuery) ([]*monitoring.TimeSeries, bool, error)
+	ListAlertPolicies(ctx context.Context, projectID, filter string) ([]*monitoring.AlertPolicy, error)
+	ListNotificationChannels(ctx context.Context, projectID, filter string) ([]*monitoring.NotificationChannel, error)
+	ListAlerts(ctx context.Context, projectID string, query AlertsQuery) ([]*Alert, bool, error)
+	GetAlert(ctx context.Context, name string) (*Alert, error)
+	ListUptimeCheckConfigs(ctx context.Context, projectID string) ([]*monitoring.UptimeCheckConfig, error)
+	ListServices(ctx context.Context, projectID string) ([]*monitoring.MService, error)
+	ListServiceLevelObjectives(ctx context.Context, serviceName string) ([]*monitoring.ServiceLevelObjective, error)
+}
+
+type restAPI struct {
+	service *monitoring.Service
+	// Authorized client for the endpoints the generated service lacks
+	client   *http.Client
+	governor *quota.Governor
+}
+
+// NewAPI creates an API backed by the Cloud Monitoring REST API. Every
+// request draws from the given read budget.
+func NewAPI(ctx context.Context, governor *quota.Governor, opts ...option.ClientOption) (API, error) {
+	opts = append([]option.ClientOption{
+		option.WithScopes(monitoring.MonitoringReadScope),
+	}, opts...)
+	client, _, err := htransport.NewClient(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create monitoring client: %w", err)
+	}
+	service, err := monitoring.NewService(ctx, append(opts, option.WithHTTPClient(client))...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create monitoring client: %w", err)
+	}
+	return &restAPI{
+		service:  service,
+		client:   client,
+		governor: governor,
+	}, nil
+}
+
+// ListTimeSeries fetches pages until MaxSeries series were read and reports
+// whether more were available
+func (a *restAPI) ListTimeSeries(ctx context.Context, projectID string, query TimeSeriesQuery) ([]*monitoring.TimeSeries, bool, error) {
+	call := a.service.Projects.TimeSeries.List("projects/" + projectID).
+		Context(ctx).
+		Filter(query.Filter).
+		IntervalStartTime(query.StartTime).
+		IntervalEndTime(query.EndTime).
+		View("FULL")
+	if query.AlignmentPeriod != "" {
+		call = call.AggregationAlignmentPeriod(query.AlignmentPeriod)
+	}
+	if query.Aligner != "" {
+		call = call.AggregationPerSeriesAligner(query.Aligner)
+	}
+	if query.Reducer != "" {
+		call = call.AggregationCrossSeriesReducer(query.Reducer)
+	}
+	if len(query.GroupBy) > 0 {
+		call = call.AggregationGroupByFields(query.GroupBy...)
+	}
+	if query.MaxSeries > 0 {
+		call = call.PageSize(int64(query.MaxSeries))
+	}
+
+	var series []*monitoring.TimeSeries
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return series, false, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return series, false, err
+		}
+		series = append(series, resp.TimeSeries...)
+
+		if query.MaxSeries > 0 && len(series) >= query.MaxSeries {
+			more := len(series) > query.MaxSeries || resp.NextPageToken != ""
+			return series[:query.MaxSeries], more, nil
+		}
+		if resp.NextPageToken == "" {
+			return series, false, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+func (a *restAPI) ListAlertPolicies(ctx context.Context, projectID, filter string) ([]*monitoring.AlertPolicy, error) {
+	call := a.service.Projects.AlertPolicies.List("projects/" + projectID).
+		Context(ctx).
+		Filter(filter)
+
+	var policies []*monitoring.AlertPolicy
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		policies = append(policies, resp.AlertPolicies...)
+		if resp.NextPageToken == "" {
+			return policies, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+func (a *restAPI) ListNotificationChannels(ctx context.Context, projectID, filter string) ([]*monitoring.NotificationChannel, error) {
+	call := a.service.Projects.NotificationChannels.List("projects/" + projectID).
+		Context(ctx).
+		Filter(filter)
+
+	var channels []*monitoring.NotificationChannel
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		channels = append(channels, resp.NotificationChannels...)
+		if resp.NextPageToken == "" {
+			return channels, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+func (a *restAPI) ListUptimeCheckConfigs(ctx context.Context, projectID string) ([]*monitoring.UptimeCheckConfig, error) {
+	call := a.service.Projects.UptimeCheckConfigs.List("projects/" + projectID).Context(ctx)
+
+	var configs []*monitoring.UptimeCheckConfig
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		configs = append(configs, resp.UptimeCheckConfigs...)
+		if resp.NextPageToken == "" {
+			return configs, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+func (a *restAPI) ListServices(ctx context.Context, projectID string) ([]*monitoring.MService, error) {
+	call := a.service.Services.List("projects/" + projectID).Context(ctx)
+
+	var services []*monitoring.MService
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		services = append(services, resp.Services...)
+		if resp.NextPageToken == "" {
+			return services, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+func (a *restAPI) ListServiceLevelObjectives(ctx context.Context, serviceName string) ([]*monitoring.ServiceLevelObjective, error) {
+	call := a.service.Services.ServiceLevelObjectives.List(serviceName).Context(ctx)
+
+	var slos []*monitoring.ServiceLevelObjective
+	for {
+		if err := a.governor.Wait(ctx); err != nil {
+			return nil, err
+		}
+		resp, err := call.Do()
+		if err != nil {
+			return nil, err
+		}
+		slos = append(slos, resp.ServiceLevelObjectives...)
+		if resp.NextPageToken == "" {
+			return slos, nil
+		}
+		call = call.PageToken(resp.NextPageToken)
+	}
+}
+
+// ListAlerts fetches pages until MaxAlerts incidents were read and reports
+// whether more were available
+func (a *restAPI) ListAlerts(ctx context.Context, projectID string, query AlertsQuery) ([]*Alert, bool, error) {
+	params := url.Values{}
+	if query.Filter != "" {
+		params.Set("filter", query.Filter)
+	}
+	if query.OrderBy != "" {
+		params.Set("orderBy", query.OrderBy)
+	}
+	if query.MaxAlerts > 0 {
+		params.Set("pageSize", strconv.Itoa(query.MaxAlerts))
+	}
+
+	var alerts []*Alert
+	for {
+		var resp listAlertsResponse
+		if err := a.get(ctx, "v3/projects/"+projectID+"/alerts", params, &resp); err != nil {
+			return alerts, false, err
+		}
+		alerts = append(alerts, resp.Alerts...)
+
+		if query.MaxAlerts > 0 && len(alerts) >= query.MaxAlerts {
+			more := len(alerts) > query.MaxAlerts || resp.NextPageToken != ""
+			return alerts[:query.MaxAlerts], more, nil
+		}
+		if resp.NextPageToken == "" {
+			return alerts, false, nil
+		}
+		params.Set("pageToken", resp.NextPageToken)
+	}
+}
+
+func (a *restAPI) GetAlert(ctx context.Context, name string) (*Alert, error) {
+	var alert Alert
+	if err := a.get(ctx, "v3/"+name, nil, &alert); err != nil {
+		return nil, err
+	}
+	return &alert, nil
+}
+
+// get issues a GET against the service's endpoint and decodes the JSON body.
+// Failures are returned as *googleapi.Error like the generated calls.
+func (a *restAPI) get(ctx context.Context, path string, params url.Values, v interface{}) error {
+	if err := a.governor.Wait(ctx); err != nil {
+		return err
+	}
+
+	u := a.service.BasePath + path
+	if len(params) > 0 {
+		u += "?" + params.Encode()
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, u, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer googleapi.CloseBody(resp)
+	if err := googleapi.CheckResponse(resp); err != nil {
+		return err
+	}
+	return json.NewDecoder(resp.Body).Decode(v)
+}
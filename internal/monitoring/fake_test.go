@@ -0,0 +1,80 @@
+package monitoring
+
+import (
+	"context"
+	"fmt"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+)
+
+// fakeAPI serves fixed responses and records the queries it receives. Time
+// series are looked up by filter; alert filters and time ranges are not
+// applied.
+type fakeAPI struct {
+	Policies   []*monitoring.AlertPolicy
+	Alerts     []*Alert
+	Services   []*monitoring.MService
+	SLOs       map[string][]*monitoring.ServiceLevelObjective
+	TimeSeries map[string][]*monitoring.TimeSeries
+	Err        error
+
+	timeSeriesQueries []TimeSeriesQuery
+	alertQueries      []AlertsQuery
+}
+
+func (f *fakeAPI) ListTimeSeries(ctx context.Context, projectID string, query TimeSeriesQuery) ([]*monitoring.TimeSeries, bool, error) {
+	f.timeSeriesQueries = append(f.timeSeriesQueries, query)
+	if f.Err != nil {
+		return nil, false, f.Err
+	}
+	return f.TimeSeries[query.Filter], false, nil
+}
+
+func (f *fakeAPI) ListAlertPolicies(ctx context.Context, projectID, filter string) ([]*monitoring.AlertPolicy, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return f.Policies, nil
+}
+
+func (f *fakeAPI) ListNotificationChannels(ctx context.Context, projectID, filter string) ([]*monitoring.NotificationChannel, error) {
+	return nil, f.Err
+}
+
+func (f *fakeAPI) ListAlerts(ctx context.Context, projectID string, query AlertsQuery) ([]*Alert, bool, error) {
+	f.alertQueries = append(f.alertQueries, query)
+	if f.Err != nil {
+		return nil, false, f.Err
+	}
+	return f.Alerts, false, nil
+}
+
+func (f *fakeAPI) GetAlert(ctx context.Context, name string) (*Alert, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	for _, alert := range f.Alerts {
+		if alert.Name == name {
+			return alert, nil
+		}
+	}
+	return nil, fmt.Errorf("alert %s not found", name)
+}
+
+func (f *fakeAPI) ListUptimeCheckConfigs(ctx context.Context, projectID string) ([]*monitoring.UptimeCheckConfig, error) {
+	return nil, f.Err
+}
+
+func (f *fakeAPI) ListServices(ctx context.Context, projectID string) ([]*monitoring.MService, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return f.Services, nil
+}
+
+func (f *fakeAPI) ListServiceLevelObjectives(ctx context.Context, serviceName string) ([]*monitoring.ServiceLevelObjective, error) {
+	if f.Err != nil {
+		return nil, f.Err
+	}
+	return f.SLOs[serviceName], nil
+}
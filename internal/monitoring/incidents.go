@@ -0,0 +1,373 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultIncidentLimit = 20
+	maxIncidentLimit     = 100
+)
+
+type ListIncidentsTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListIncidentsArgs struct {
+	State     string `json:"state,omitempty"`
+	Policy    string `json:"policy,omitempty"`
+	StartTime string `json:"startTime,omitempty"`
+	EndTime   string `json:"endTime,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+type GetIncidentTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type GetIncidentArgs struct {
+	IncidentID string `json:"incidentId"`
+}
+
+type monitoredObject struct {
+	Type   string            `json:"type"`
+	Labels map[string]string `json:"labels,omitempty"`
+}
+
+type incident struct {
+	ID        string            `json:"id"`
+	State     string            `json:"state"`
+	Policy    string            `json:"policy"`
+	PolicyID  string            `json:"policyId,omitempty"`
+	Severity  string            `json:"severity,omitempty"`
+	OpenTime  string            `json:"openTime"`
+	CloseTime string            `json:"closeTime,omitempty"`
+	Duration  string            `json:"duration"`
+	Resource  *monitoredObject  `json:"resource,omitempty"`
+	Metric    *monitoredObject  `json:"metric,omitempty"`
+	LogLabels map[string]string `json:"logLabels,omitempty"`
+	Logs      incidentLogs      `json:"logs"`
+}
+
+type listIncidentsResult struct {
+	Start     time.Time  `json:"start"`
+	End       time.Time  `json:"end"`
+	Count     int        `json:"count"`
+	Truncated bool       `json:"truncated,omitempty"`
+	Incidents []incident `json:"incidents"`
+}
+
+type getIncidentResult struct {
+	Incident incident     `json:"incident"`
+	Policy   *alertPolicy `json:"alertPolicy,omitempty"`
+}
+
+func NewListIncidentsTool(api API, projectID string, cache *logging.LogCache) *ListIncidentsTool {
+	return &ListIncidentsTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListIncidentsTool) Name() string {
+	return "list_incidents"
+}
+
+func (t *ListIncidentsTool) Description() string {
+	return "List Cloud Monitoring incidents: by default every open incident plus those opened in the last 24 hours, open first then newest first. Each incident has its policy, resource and metric labels, and a logs.filter for list_log_entries covering the incident's resource from shortly before it opened. state is open, closed or all (default); policy matches policy display names case-insensitively."
+}
+
+func (t *ListIncidentsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"state": {
+				Type: "string",
+				Enum: []string{"open", "closed", "all"},
+			},
+			"policy": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListIncidentsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListIncidentsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.State == "" {
+		params.State = "all"
+	}
+	if params.State != "open" && params.State != "closed" && params.State != "all" {
+		return logging.ErrorResult("Error: state must be one of open, closed, all"), nil
+	}
+	window, err := logging.ParseTimeWindow(params.StartTime, params.EndTime, 24*time.Hour)
+	if err != nil {
+		return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultIncidentLimit
+	}
+	if params.Limit > maxIncidentLimit {
+		params.Limit = maxIncidentLimit
+	}
+
+	// Open incidents are listed whenever they opened; the window applies to the rest
+	recent := fmt.Sprintf(`open_time >= "%s" AND open_time <= "%s"`, window.Start.Format(time.RFC3339), window.End.Format(time.RFC3339))
+	var queries []AlertsQuery
+	switch params.State {
+	case "open":
+		queries = append(queries, AlertsQuery{Filter: `state = "OPEN"`})
+	case "closed":
+		queries = append(queries, AlertsQuery{Filter: `state = "CLOSED" AND ` + recent})
+	default:
+		queries = append(queries, AlertsQuery{Filter: `state = "OPEN"`}, AlertsQuery{Filter: recent})
+	}
+
+	var alerts []*Alert
+	var truncated bool
+	seen := make(map[string]bool)
+	for _, query := range queries {
+		query.OrderBy = "open_time desc"
+		// Policy names are matched locally, so read a full page
+		query.MaxAlerts = maxIncidentLimit
+		var page []*Alert
+		var more bool
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			page, more, err = t.api.ListAlerts(ctx, t.projectID, query)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to list incidents: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error listing incidents: %v", err)), nil
+		}
+		truncated = truncated || more
+		for _, alert := range page {
+			if !seen[alert.Name] {
+				seen[alert.Name] = true
+				alerts = append(alerts, alert)
+			}
+		}
+	}
+
+	policy := strings.ToLower(params.Policy)
+	var matched []*Alert
+	for _, alert := range alerts {
+		if policy != "" && (alert.Policy == nil || !strings.Contains(strings.ToLower(alert.Policy.DisplayName), policy)) {
+			continue
+		}
+		matched = append(matched, alert)
+	}
+	sort.SliceStable(matched, func(i, j int) bool {
+		if (matched[i].State == "OPEN") != (matched[j].State == "OPEN") {
+			return matched[i].State == "OPEN"
+		}
+		return matched[i].OpenTime > matched[j].OpenTime
+	})
+	if len(matched) > params.Limit {
+		matched = matched[:params.Limit]
+		truncated = true
+	}
+
+	policies := t.policiesByName(ctx)
+	now := time.Now()
+	result := listIncidentsResult{
+		Start:     window.Start,
+		End:       window.End,
+		Truncated: truncated,
+		Incidents: []incident{},
+	}
+	for _, alert := range matched {
+		result.Incidents = append(result.Incidents, summarizeIncident(alert, policies[policyName(alert)], now))
+	}
+	result.Count = len(result.Incidents)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal incidents: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// policiesByName indexes the project's policies for condition durations and
+// log filters. Incidents are still listed if the policies cannot be read.
+func (t *ListIncidentsTool) policiesByName(ctx context.Context) map[string]*monitoring.AlertPolicy {
+	policies, err := loadAlertPolicies(ctx, t.api, t.projectID, t.cache, t.rateLimiter)
+	if err != nil {
+		log.Printf("Failed to list alert policies for incidents: %v", err)
+	}
+	byName := make(map[string]*monitoring.AlertPolicy, len(policies))
+	for _, policy := range policies {
+		byName[policy.Name] = policy
+	}
+	return byName
+}
+
+func NewGetIncidentTool(api API, projectID string, cache *logging.LogCache) *GetIncidentTool {
+	return &GetIncidentTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *GetIncidentTool) Name() string {
+	return "get_incident"
+}
+
+func (t *GetIncidentTool) Description() string {
+	return "Get a Cloud Monitoring incident by id with the full alerting policy that fired it and a logs.filter for list_log_entries (with logs.startTime/endTime) selecting the incident's log entries."
+}
+
+func (t *GetIncidentTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"incidentId": {
+				Type: "string",
+			},
+		},
+		Required:             []string{"incidentId"},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *GetIncidentTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params GetIncidentArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.IncidentID == "" {
+		return logging.ErrorResult("Error: incidentId is required"), nil
+	}
+	// Accept both the bare id and the full resource name
+	name := fmt.Sprintf("projects/%s/alerts/%s", t.projectID, path.Base(params.IncidentID))
+
+	var alert *Alert
+	err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+		var err error
+		alert, err = t.api.GetAlert(ctx, name)
+		return err
+	})
+	if err != nil {
+		log.Printf("Failed to get incident: %v", err)
+		return logging.ErrorResult(fmt.Sprintf("Error getting incident: %v", err)), nil
+	}
+
+	var policy *monitoring.AlertPolicy
+	policies, err := loadAlertPolicies(ctx, t.api, t.projectID, t.cache, t.rateLimiter)
+	if err != nil {
+		log.Printf("Failed to list alert policies for incident: %v", err)
+	}
+	for _, p := range policies {
+		if p.Name == policyName(alert) {
+			policy = p
+			break
+		}
+	}
+
+	result := getIncidentResult{Incident: summarizeIncident(alert, policy, time.Now())}
+	if policy != nil {
+		summary := summarizePolicy(policy)
+		result.Policy = &summary
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal incident: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func summarizeIncident(alert *Alert, policy *monitoring.AlertPolicy, now time.Time) incident {
+	summary := incident{
+		ID:        path.Base(alert.Name),
+		State:     alert.State,
+		OpenTime:  alert.OpenTime,
+		CloseTime: alert.CloseTime,
+		Logs:      incidentLogQuery(alert, policy, now),
+	}
+	if alert.Policy != nil {
+		summary.Policy = alert.Policy.DisplayName
+		summary.PolicyID = path.Base(alert.Policy.Name)
+		summary.Severity = alert.Policy.Severity
+	}
+	if alert.Resource != nil {
+		summary.Resource = &monitoredObject{Type: alert.Resource.Type, Labels: alert.Resource.Labels}
+	}
+	if alert.Metric != nil {
+		summary.Metric = &monitoredObject{Type: alert.Metric.Type, Labels: alert.Metric.Labels}
+	}
+	if alert.Log != nil {
+		summary.LogLabels = alert.Log.ExtractedLabels
+	}
+
+	opened, err := time.Parse(time.RFC3339Nano, alert.OpenTime)
+	if err == nil {
+		end := now
+		if closed, err := time.Parse(time.RFC3339Nano, alert.CloseTime); err == nil {
+			end = closed
+		}
+		summary.Duration = end.Sub(opened).Truncate(time.Second).String()
+	}
+	return summary
+}
+
+func policyName(alert *Alert) string {
+	if alert.Policy == nil {
+		return ""
+	}
+	return alert.Policy.Name
+}
@@ -0,0 +1,143 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+type ListNotificationChannelsTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListNotificationChannelsArgs struct {
+	Type string `json:"type,omitempty"`
+}
+
+type notificationChannel struct {
+	ID                 string            `json:"id"`
+	DisplayName        string            `json:"displayName"`
+	Type               string            `json:"type"`
+	Enabled            bool              `json:"enabled"`
+	VerificationStatus string            `json:"verificationStatus,omitempty"`
+	Labels             map[string]string `json:"labels,omitempty"`
+	Description        string            `json:"description,omitempty"`
+	// Display names of the policies that notify this channel
+	Policies []string `json:"policies,omitempty"`
+}
+
+type listNotificationChannelsResult struct {
+	Count    int                   `json:"count"`
+	Channels []notificationChannel `json:"channels"`
+}
+
+func NewListNotificationChannelsTool(api API, projectID string, cache *logging.LogCache) *ListNotificationChannelsTool {
+	return &ListNotificationChannelsTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListNotificationChannelsTool) Name() string {
+	return "list_notification_channels"
+}
+
+func (t *ListNotificationChannelsTool) Description() string {
+	return "List Cloud Monitoring notification channels (email, slack, pagerduty, webhook, ...) with their verification status and the alerting policies that notify each. Channel ids match the notificationChannels of list_alert_policies."
+}
+
+func (t *ListNotificationChannelsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"type": {
+				Type: "string",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListNotificationChannelsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListNotificationChannelsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	var filter string
+	if params.Type != "" {
+		filter = fmt.Sprintf(`type="%s"`, logging.EscapeFilterValue(params.Type))
+	}
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"filter":    filter,
+	})
+
+	var channels []*monitoring.NotificationChannel
+	if !t.cache.GetValue(cacheKey, &channels) {
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			channels, err = t.api.ListNotificationChannels(ctx, t.projectID, filter)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to list notification channels: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error listing notification channels: %v", err)), nil
+		}
+		t.cache.SetValue(cacheKey, channels, alertConfigTTL)
+	}
+
+	// Policies only add context; the channels are still useful without them
+	policiesByChannel := make(map[string][]string)
+	policies, err := loadAlertPolicies(ctx, t.api, t.projectID, t.cache, t.rateLimiter)
+	if err != nil {
+		log.Printf("Failed to list alert policies for channels: %v", err)
+	}
+	for _, policy := range policies {
+		for _, channel := range policy.NotificationChannels {
+			policiesByChannel[channel] = append(policiesByChannel[channel], policy.DisplayName)
+		}
+	}
+
+	result := listNotificationChannelsResult{Channels: []notificationChannel{}}
+	for _, c := range channels {
+		result.Channels = append(result.Channels, notificationChannel{
+			ID:                 path.Base(c.Name),
+			DisplayName:        c.DisplayName,
+			Type:               c.Type,
+			Enabled:            c.Enabled,
+			VerificationStatus: c.VerificationStatus,
+			Labels:             c.Labels,
+			Description:        c.Description,
+			Policies:           policiesByChannel[c.Name],
+		})
+	}
+	result.Count = len(result.Channels)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal notification channels: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
@@ -0,0 +1,100 @@
+package monitoring
+
+import (
+	"sort"
+)
+
+// MetricPreset is a ready-made aggregation of a commonly used metric
+type MetricPreset struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	MetricType  string   `json:"metricType"`
+	Aligner     string   `json:"aligner"`
+	Reducer     string   `json:"reducer"`
+	GroupBy     []string `json:"groupBy,omitempty"`
+}
+
+const cloudRunServiceLabel = "resource.labels.service_name"
+
+var metricPresets = map[string]MetricPreset{
+	"run_request_count": {
+		Name:        "run_request_count",
+		Description: "Cloud Run requests per second by service and response code class",
+		MetricType:  "run.googleapis.com/request_count",
+		Aligner:     "ALIGN_RATE",
+		Reducer:     "REDUCE_SUM",
+		GroupBy:     []string{cloudRunServiceLabel, "metric.labels.response_code_class"},
+	},
+	"run_latency_p50": {
+		Name:        "run_latency_p50",
+		Description: "Cloud Run request latency p50 (ms) by service",
+		MetricType:  "run.googleapis.com/request_latencies",
+		Aligner:     "ALIGN_DELTA",
+		Reducer:     "REDUCE_PERCENTILE_50",
+		GroupBy:     []string{cloudRunServiceLabel},
+	},
+	"run_latency_p95": {
+		Name:        "run_latency_p95",
+		Description: "Cloud Run request latency p95 (ms) by service",
+		MetricType:  "run.googleapis.com/request_latencies",
+		Aligner:     "ALIGN_DELTA",
+		Reducer:     "REDUCE_PERCENTILE_95",
+		GroupBy:     []string{cloudRunServiceLabel},
+	},
+	"run_latency_p99": {
+		Name:        "run_latency_p99",
+		Description: "Cloud Run request latency p99 (ms) by service",
+		MetricType:  "run.googleapis.com/request_latencies",
+		Aligner:     "ALIGN_DELTA",
+		Reducer:     "REDUCE_PERCENTILE_99",
+		GroupBy:     []string{cloudRunServiceLabel},
+	},
+	"run_instance_count": {
+		Name:        "run_instance_count",
+		Description: "Cloud Run container instances by service and state (active/idle)",
+		MetricType:  "run.googleapis.com/container/instance_count",
+		Aligner:     "ALIGN_MAX",
+		Reducer:     "REDUCE_SUM",
+		GroupBy:     []string{cloudRunServiceLabel, "metric.labels.state"},
+	},
+	"run_cpu_utilization": {
+		Name:        "run_cpu_utilization",
+		Description: "Cloud Run container CPU utilization p95 (0-1) by service",
+		MetricType:  "run.googleapis.com/container/cpu/utilizations",
+		Aligner:     "ALIGN_DELTA",
+		Reducer:     "REDUCE_PERCENTILE_95",
+		GroupBy:     []string{cloudRunServiceLabel},
+	},
+	"run_memory_utilization": {
+		Name:        "run_memory_utilization",
+		Description: "Cloud Run container memory utilization p95 (0-1) by service",
+		MetricType:  "run.googleapis.com/container/memory/utilizations",
+		Aligner:     "ALIGN_DELTA",
+		Reducer:     "REDUCE_PERCENTILE_95",
+		GroupBy:     []string{cloudRunServiceLabel},
+	},
+}
+
+func GetMetricPreset(name string) (MetricPreset, bool) {
+	preset, ok := metricPresets[name]
+	return preset, ok
+}
+
+func ListMetricPresets() []MetricPreset {
+	presets := make([]MetricPreset, 0, len(metricPresets))
+	for _, preset := range metricPresets {
+		presets = append(presets, preset)
+	}
+	sort.Slice(presets, func(i, j int) bool {
+		return presets[i].Name < presets[j].Name
+	})
+	return presets
+}
+
+func presetNames() []string {
+	var names []string
+	for _, preset := range ListMetricPresets() {
+		names = append(names, preset.Name)
+	}
+	return names
+}
@@ -0,0 +1,434 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultMaxSeries = 20
+	maxSeriesLimit   = 100
+	// Points per series when the alignment period is chosen automatically
+	defaultMaxPoints = 60
+	minAlignment     = time.Minute
+)
+
+type QueryMetricsTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type QueryMetricsArgs struct {
+	Preset          string   `json:"preset,omitempty"`
+	MetricType      string   `json:"metricType,omitempty"`
+	Filter          string   `json:"filter,omitempty"`
+	Service         string   `json:"service,omitempty"`
+	StartTime       string   `json:"startTime,omitempty"`
+	EndTime         string   `json:"endTime,omitempty"`
+	AlignmentPeriod string   `json:"alignmentPeriod,omitempty"`
+	Aligner         string   `json:"aligner,omitempty"`
+	Reducer         string   `json:"reducer,omitempty"`
+	GroupBy         []string `json:"groupBy,omitempty"`
+	MaxSeries       int      `json:"maxSeries,omitempty"`
+	MaxPoints       int      `json:"maxPoints,omitempty"`
+}
+
+// series is a compact time series. Aligned series are a grid of values
+// with one value per step, null where there is no point. The grid starts at
+// the result's start, or at the series' own start when older steps were
+// dropped to stay within maxPoints. Unaligned series list their points.
+type series struct {
+	Labels map[string]string `json:"labels,omitempty"`
+	Start  *time.Time        `json:"start,omitempty"`
+	Values []*float64        `json:"values,omitempty"`
+	Points [][2]interface{}  `json:"points,omitempty"`
+	Min    float64           `json:"min"`
+	Max    float64           `json:"max"`
+	Mean   float64           `json:"mean"`
+	Last   float64           `json:"last"`
+}
+
+type queryMetricsResult struct {
+	MetricType string    `json:"metricType"`
+	Filter     string    `json:"filter"`
+	Aligner    string    `json:"aligner,omitempty"`
+	Reducer    string    `json:"reducer,omitempty"`
+	GroupBy    []string  `json:"groupBy,omitempty"`
+	Unit       string    `json:"unit,omitempty"`
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Step       string    `json:"step,omitempty"`
+	Truncated  bool      `json:"truncated,omitempty"`
+	Series     []series  `json:"series"`
+}
+
+func NewQueryMetricsTool(api API, projectID string, cache *logging.LogCache) *QueryMetricsTool {
+	return &QueryMetricsTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *QueryMetricsTool) Name() string {
+	return "query_metrics"
+}
+
+func (t *QueryMetricsTool) Description() string {
+	return fmt.Sprintf("Query Cloud Monitoring time series and return them compactly with min/max/mean/last per series. Use a preset (%s) optionally narrowed by service, or give metricType with aligner (e.g. ALIGN_RATE, ALIGN_MEAN, ALIGN_DELTA), reducer (e.g. REDUCE_SUM, REDUCE_PERCENTILE_99) and groupBy fields. Defaults to the last hour with an alignment period giving about 60 points.", strings.Join(presetNames(), ", "))
+}
+
+func (t *QueryMetricsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"preset": {
+				Type: "string",
+				Enum: presetNames(),
+			},
+			"metricType": {
+				Type: "string",
+			},
+			"filter": {
+				Type: "string",
+			},
+			"service": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"alignmentPeriod": {
+				Type: "string",
+			},
+			"aligner": {
+				Type: "string",
+			},
+			"reducer": {
+				Type: "string",
+			},
+			"groupBy": {
+				Type:  "array",
+				Items: &types.Schema{Type: "string"},
+			},
+			"maxSeries": {
+				Type: "integer",
+			},
+			"maxPoints": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *QueryMetricsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params QueryMetricsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	if params.Preset != "" {
+		preset, ok := GetMetricPreset(params.Preset)
+		if !ok {
+			return logging.ErrorResult(fmt.Sprintf("Error: unknown preset %q (available: %s)", params.Preset, strings.Join(presetNames(), ", "))), nil
+		}
+		// Explicit arguments override the preset
+		if params.MetricType == "" {
+			params.MetricType = preset.MetricType
+		}
+		if params.Aligner == "" {
+			params.Aligner = preset.Aligner
+		}
+		if params.Reducer == "" {
+			params.Reducer = preset.Reducer
+		}
+		if params.GroupBy == nil {
+			params.GroupBy = preset.GroupBy
+		}
+	}
+	if params.MetricType == "" {
+		return logging.ErrorResult("Error: either preset or metricType is required"), nil
+	}
+	if (params.Reducer != "" || len(params.GroupBy) > 0) && params.Aligner == "" {
+		return logging.ErrorResult("Error: reducer and groupBy require an aligner"), nil
+	}
+
+	window, err := logging.ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	if params.MaxSeries <= 0 {
+		params.MaxSeries = defaultMaxSeries
+	}
+	if params.MaxSeries > maxSeriesLimit {
+		params.MaxSeries = maxSeriesLimit
+	}
+	if params.MaxPoints <= 0 {
+		params.MaxPoints = defaultMaxPoints
+	}
+
+	var alignment time.Duration
+	if params.Aligner != "" {
+		alignment, err = alignmentPeriod(params.AlignmentPeriod, window.Duration(), params.MaxPoints)
+		if err != nil {
+			return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+		}
+		// Align the window so that points fall on round times and the cache key is stable
+		window.Start = window.Start.Truncate(alignment)
+		window.End = window.End.Truncate(alignment)
+		if !window.Start.Before(window.End) {
+			window.Start = window.End.Add(-alignment)
+		}
+	}
+
+	query := TimeSeriesQuery{
+		Filter:    metricFilter(params),
+		StartTime: window.Start.Format(time.RFC3339),
+		EndTime:   window.End.Format(time.RFC3339),
+		Aligner:   params.Aligner,
+		Reducer:   params.Reducer,
+		GroupBy:   params.GroupBy,
+		MaxSeries: params.MaxSeries,
+	}
+	if alignment > 0 {
+		query.AlignmentPeriod = fmt.Sprintf("%ds", int64(alignment.Seconds()))
+	}
+
+	// Aligned queries already run on round bounds; raw ones over an open window
+	// are rounded so repeated calls share an entry, while closed windows are
+	// cached for good and keep exact bounds
+	keyQuery := query
+	if alignment == 0 && !window.IsClosed() {
+		keyQuery.StartTime = window.Start.Truncate(t.cache.TimeBucket()).Format(time.RFC3339)
+		keyQuery.EndTime = window.End.Truncate(t.cache.TimeBucket()).Format(time.RFC3339)
+	}
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"query":     keyQuery,
+		"maxPoints": params.MaxPoints,
+	})
+
+	var result queryMetricsResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for metrics: %s", query.Filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.queryMetrics(ctx, params.MetricType, query, window, alignment, params.MaxPoints)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to query metrics: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error querying metrics: %v", err)), nil
+		}
+
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, time.Minute)
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal metrics: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *QueryMetricsTool) queryMetrics(ctx context.Context, metricType string, query TimeSeriesQuery, window logging.TimeWindow, alignment time.Duration, maxPoints int) (queryMetricsResult, error) {
+	timeSeries, truncated, err := t.api.ListTimeSeries(ctx, t.projectID, query)
+	if err != nil {
+		return queryMetricsResult{}, err
+	}
+
+	result := queryMetricsResult{
+		MetricType: metricType,
+		Filter:     query.Filter,
+		Aligner:    query.Aligner,
+		Reducer:    query.Reducer,
+		GroupBy:    query.GroupBy,
+		Start:      window.Start,
+		End:        window.End,
+		Truncated:  truncated,
+		Series:     []series{},
+	}
+	if alignment > 0 {
+		result.Step = alignment.String()
+	}
+
+	for _, ts := range timeSeries {
+		if result.Unit == "" {
+			result.Unit = ts.Unit
+		}
+		s, ok := compactSeries(ts, window.Start, alignment, maxPoints)
+		if ok {
+			result.Series = append(result.Series, s)
+		}
+	}
+	sort.SliceStable(result.Series, func(i, j int) bool {
+		return result.Series[i].Max > result.Series[j].Max
+	})
+	return result, nil
+}
+
+// compactSeries converts API points, which arrive newest first, into an
+// ascending grid or point list with summary statistics
+func compactSeries(ts *monitoring.TimeSeries, start time.Time, alignment time.Duration, maxPoints int) (series, bool) {
+	s := series{Labels: make(map[string]string)}
+	if ts.Resource != nil {
+		for k, v := range ts.Resource.Labels {
+			s.Labels[k] = v
+		}
+	}
+	if ts.Metric != nil {
+		for k, v := range ts.Metric.Labels {
+			s.Labels[k] = v
+		}
+	}
+	if len(s.Labels) == 0 {
+		s.Labels = nil
+	}
+
+	type point struct {
+		at    time.Time
+		value float64
+	}
+	var points []point
+	for _, p := range ts.Points {
+		value, ok := pointValue(p.Value)
+		if !ok || p.Interval == nil {
+			continue
+		}
+		at, err := time.Parse(time.RFC3339Nano, p.Interval.EndTime)
+		if err != nil {
+			continue
+		}
+		points = append(points, point{at: at, value: round4(value)})
+	}
+	if len(points) == 0 {
+		return s, false
+	}
+	sort.Slice(points, func(i, j int) bool {
+		return points[i].at.Before(points[j].at)
+	})
+
+	s.Min, s.Max = math.Inf(1), math.Inf(-1)
+	sum := 0.0
+	for _, p := range points {
+		s.Min = math.Min(s.Min, p.value)
+		s.Max = math.Max(s.Max, p.value)
+		sum += p.value
+	}
+	s.Mean = round4(sum / float64(len(points)))
+	s.Last = points[len(points)-1].value
+
+	if alignment > 0 {
+		// A point's end time closes the step it aggregates
+		for _, p := range points {
+			index := int(p.at.Sub(start)/alignment) - 1
+			if index < 0 {
+				index = 0
+			}
+			for len(s.Values) <= index {
+				s.Values = append(s.Values, nil)
+			}
+			value := p.value
+			s.Values[index] = &value
+		}
+		if dropped := len(s.Values) - maxPoints; dropped > 0 {
+			s.Values = s.Values[dropped:]
+			gridStart := start.Add(time.Duration(dropped) * alignment)
+			s.Start = &gridStart
+		}
+		return s, true
+	}
+
+	if len(points) > maxPoints {
+		points = points[len(points)-maxPoints:]
+	}
+	for _, p := range points {
+		s.Points = append(s.Points, [2]interface{}{p.at.UTC().Format(time.RFC3339), p.value})
+	}
+	return s, true
+}
+
+// pointValue reads a numeric value; distributions are represented by their mean
+func pointValue(v *monitoring.TypedValue) (float64, bool) {
+	switch {
+	case v == nil:
+		return 0, false
+	case v.DoubleValue != nil:
+		return *v.DoubleValue, true
+	case v.Int64Value != nil:
+		return float64(*v.Int64Value), true
+	case v.BoolValue != nil:
+		if *v.BoolValue {
+			return 1, true
+		}
+		return 0, true
+	case v.DistributionValue != nil:
+		return v.DistributionValue.Mean, true
+	}
+	return 0, false
+}
+
+func metricFilter(params QueryMetricsArgs) string {
+	clauses := []string{fmt.Sprintf(`metric.type="%s"`, logging.EscapeFilterValue(params.MetricType))}
+	if params.Service != "" {
+		clauses = append(clauses, fmt.Sprintf(`resource.labels.service_name="%s"`, logging.EscapeFilterValue(params.Service)))
+	}
+	if params.Filter != "" {
+		clauses = append(clauses, params.Filter)
+	}
+	return strings.Join(clauses, " AND ")
+}
+
+// alignmentPeriod parses a requested period, or picks a whole number of
+// minutes that keeps the series within maxPoints
+func alignmentPeriod(requested string, window time.Duration, maxPoints int) (time.Duration, error) {
+	if requested != "" {
+		period, err := time.ParseDuration(requested)
+		if err != nil || period < minAlignment {
+			return 0, fmt.Errorf("alignmentPeriod %q must be a duration of at least %s", requested, minAlignment)
+		}
+		return period.Truncate(time.Second), nil
+	}
+
+	period := time.Duration(math.Ceil(float64(window)/float64(maxPoints)/float64(time.Minute))) * time.Minute
+	if period < minAlignment {
+		period = minAlignment
+	}
+	return period, nil
+}
+
+func round4(v float64) float64 {
+	return math.Round(v*10000) / 10000
+}
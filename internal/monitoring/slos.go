@@ -0,0 +1,325 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"strings"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultSLOLimit = 20
+	maxSLOLimit     = 50
+)
+
+// Lengths of calendar compliance periods, for burn rates
+var calendarPeriods = map[string]time.Duration{
+	"DAY":   24 * time.Hour,
+	"WEEK":  7 * 24 * time.Hour,
+	"MONTH": 30 * 24 * time.Hour,
+}
+
+type ListSLOsTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListSLOsArgs struct {
+	Service   string `json:"service,omitempty"`
+	StartTime string `json:"startTime,omitempty"`
+	EndTime   string `json:"endTime,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+}
+
+// sloStatus reports budgets as fractions of the period's total error budget.
+// BudgetBurned is what the window consumed, and BurnRate how fast relative
+// to spending exactly the whole budget over the period (1.0).
+type sloStatus struct {
+	ID              string   `json:"id"`
+	Service         string   `json:"service"`
+	DisplayName     string   `json:"displayName"`
+	Goal            float64  `json:"goal"`
+	Period          string   `json:"period"`
+	Compliance      *float64 `json:"compliance,omitempty"`
+	BudgetRemaining *float64 `json:"budgetRemaining,omitempty"`
+	BudgetBurned    *float64 `json:"budgetBurned,omitempty"`
+	BurnRate        *float64 `json:"burnRate,omitempty"`
+	Status          string   `json:"status"`
+	Error           string   `json:"error,omitempty"`
+}
+
+type listSLOsResult struct {
+	Start     time.Time   `json:"start"`
+	End       time.Time   `json:"end"`
+	Count     int         `json:"count"`
+	Truncated bool        `json:"truncated,omitempty"`
+	SLOs      []sloStatus `json:"slos"`
+}
+
+type sloDefinition struct {
+	Service string
+	SLO     *monitoring.ServiceLevelObjective
+}
+
+func NewListSLOsTool(api API, projectID string, cache *logging.LogCache) *ListSLOsTool {
+	return &ListSLOsTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListSLOsTool) Name() string {
+	return "list_slos"
+}
+
+func (t *ListSLOsTool) Description() string {
+	return "List Service Monitoring SLOs with goal, compliance period, current compliance and remaining error budget (fraction of the period's budget; negative when overspent). For the window startTime..endTime (default the last hour) also reports budgetBurned, the fraction of the budget the window consumed, and burnRate. Pass an incident's window to see how much budget an outage burned. service matches service ids or display names case-insensitively."
+}
+
+func (t *ListSLOsTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"service": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListSLOsTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListSLOsArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := logging.ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if params.Limit <= 0 {
+		params.Limit = defaultSLOLimit
+	}
+	if params.Limit > maxSLOLimit {
+		params.Limit = maxSLOLimit
+	}
+
+	definitions, err := t.loadSLOs(ctx, params.Service)
+	if err != nil {
+		log.Printf("Failed to list SLOs: %v", err)
+		return logging.ErrorResult(fmt.Sprintf("Error listing SLOs: %v", err)), nil
+	}
+
+	result := listSLOsResult{
+		Start: window.Start,
+		End:   window.End,
+		SLOs:  []sloStatus{},
+	}
+	if len(definitions) > params.Limit {
+		definitions = definitions[:params.Limit]
+		result.Truncated = true
+	}
+	for _, def := range definitions {
+		// One SLO failing to evaluate should not hide the others
+		result.SLOs = append(result.SLOs, t.evaluate(ctx, def, window))
+	}
+	result.Count = len(result.SLOs)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal SLOs: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// loadSLOs lists the SLOs of the services matching the query
+func (t *ListSLOsTool) loadSLOs(ctx context.Context, query string) ([]sloDefinition, error) {
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"service":   query,
+	})
+
+	var definitions []sloDefinition
+	if t.cache.GetValue(cacheKey, &definitions) {
+		return definitions, nil
+	}
+
+	var services []*monitoring.MService
+	err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+		var err error
+		services, err = t.api.ListServices(ctx, t.projectID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	query = strings.ToLower(query)
+	for _, service := range services {
+		id := path.Base(service.Name)
+		if query != "" && !strings.Contains(strings.ToLower(id), query) && !strings.Contains(strings.ToLower(service.DisplayName), query) {
+			continue
+		}
+		var slos []*monitoring.ServiceLevelObjective
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			slos, err = t.api.ListServiceLevelObjectives(ctx, service.Name)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		name := service.DisplayName
+		if name == "" {
+			name = id
+		}
+		for _, slo := range slos {
+			definitions = append(definitions, sloDefinition{Service: name, SLO: slo})
+		}
+	}
+
+	t.cache.SetValue(cacheKey, definitions, alertConfigTTL)
+	return definitions, nil
+}
+
+// evaluate reads the remaining budget fraction at both ends of the window.
+// Compliance follows from it: budget = 1 - (1 - compliance) / (1 - goal).
+func (t *ListSLOsTool) evaluate(ctx context.Context, def sloDefinition, window logging.TimeWindow) sloStatus {
+	slo := def.SLO
+	status := sloStatus{
+		ID:          path.Base(slo.Name),
+		Service:     def.Service,
+		DisplayName: slo.DisplayName,
+		Goal:        slo.Goal,
+		Period:      sloPeriod(slo),
+	}
+
+	// Budget values are cumulative, so the newest point at or before each bound is its value
+	alignment, _ := alignmentPeriod("", window.Duration(), defaultMaxPoints)
+	start := window.Start.Truncate(alignment)
+	end := window.End.Truncate(alignment)
+	if !start.Before(end) {
+		start = end.Add(-alignment)
+	}
+	query := TimeSeriesQuery{
+		Filter:          fmt.Sprintf(`select_slo_budget_fraction("%s")`, slo.Name),
+		StartTime:       start.Format(time.RFC3339),
+		EndTime:         end.Format(time.RFC3339),
+		AlignmentPeriod: fmt.Sprintf("%ds", int64(alignment.Seconds())),
+		Aligner:         "ALIGN_NEXT_OLDER",
+		MaxSeries:       1,
+	}
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"kind":  "slo_budget",
+		"query": query,
+	})
+
+	var budgets []float64
+	if !t.cache.GetValue(cacheKey, &budgets) {
+		var timeSeries []*monitoring.TimeSeries
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			timeSeries, _, err = t.api.ListTimeSeries(ctx, t.projectID, query)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to read SLO budget %s: %v", slo.Name, err)
+			status.Status = "error"
+			status.Error = err.Error()
+			return status
+		}
+		// Points arrive newest first; keep them oldest first
+		if len(timeSeries) > 0 {
+			for i := len(timeSeries[0].Points) - 1; i >= 0; i-- {
+				if v, ok := pointValue(timeSeries[0].Points[i].Value); ok {
+					budgets = append(budgets, v)
+				}
+			}
+		}
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, budgets)
+		} else {
+			t.cache.SetValue(cacheKey, budgets, time.Minute)
+		}
+	}
+
+	if len(budgets) == 0 {
+		status.Status = "no_data"
+		return status
+	}
+
+	remaining := round4(budgets[len(budgets)-1])
+	status.BudgetRemaining = &remaining
+	if slo.Goal < 1 {
+		compliance := round4(1 - (1-remaining)*(1-slo.Goal))
+		status.Compliance = &compliance
+	}
+	burned := round4(budgets[0] - budgets[len(budgets)-1])
+	status.BudgetBurned = &burned
+	if period := sloPeriodDuration(slo); period > 0 && end.After(start) {
+		rate := round4(burned * float64(period) / float64(end.Sub(start)))
+		status.BurnRate = &rate
+	}
+
+	switch {
+	case remaining <= 0:
+		status.Status = "exhausted"
+	case status.BurnRate != nil && *status.BurnRate > 1:
+		status.Status = "burning"
+	default:
+		status.Status = "ok"
+	}
+	return status
+}
+
+func sloPeriod(slo *monitoring.ServiceLevelObjective) string {
+	if slo.CalendarPeriod != "" {
+		return "calendar " + strings.ToLower(slo.CalendarPeriod)
+	}
+	if d, err := time.ParseDuration(slo.RollingPeriod); err == nil && d%(24*time.Hour) == 0 {
+		return fmt.Sprintf("rolling %dd", int(d.Hours()/24))
+	}
+	if d, err := time.ParseDuration(slo.RollingPeriod); err == nil {
+		return "rolling " + d.String()
+	}
+	return slo.RollingPeriod
+}
+
+func sloPeriodDuration(slo *monitoring.ServiceLevelObjective) time.Duration {
+	if slo.CalendarPeriod != "" {
+		return calendarPeriods[slo.CalendarPeriod]
+	}
+	d, _ := time.ParseDuration(slo.RollingPeriod)
+	return d
+}
@@ -0,0 +1,185 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+func TestListSLOs(t *testing.T) {
+	const service = "projects/example-project/services/checkout"
+	type budgets struct{ oldest, newest float64 }
+	tests := []struct {
+		name           string
+		slo            *monitoring.ServiceLevelObjective
+		budgets        *budgets
+		wantStatus     string
+		wantRemaining  float64
+		wantCompliance *float64
+		wantBurned     float64
+		wantBurnRate   *float64
+	}{
+		{
+			name:           "within budget",
+			slo:            &monitoring.ServiceLevelObjective{Goal: 0.99, RollingPeriod: "2592000s"},
+			budgets:        &budgets{oldest: 0.8, newest: 0.7995},
+			wantStatus:     "ok",
+			wantRemaining:  0.7995,
+			wantCompliance: ptr(0.998),
+			wantBurned:     0.0005,
+			wantBurnRate:   ptr(0.36),
+		},
+		{
+			// A tenth of a 30 day budget in an hour burns 72 times too fast
+			name:           "burning",
+			slo:            &monitoring.ServiceLevelObjective{Goal: 0.99, RollingPeriod: "2592000s"},
+			budgets:        &budgets{oldest: 0.6, newest: 0.5},
+			wantStatus:     "burning",
+			wantRemaining:  0.5,
+			wantCompliance: ptr(0.995),
+			wantBurned:     0.1,
+			wantBurnRate:   ptr(72),
+		},
+		{
+			name:           "calendar period",
+			slo:            &monitoring.ServiceLevelObjective{Goal: 0.999, CalendarPeriod: "DAY"},
+			budgets:        &budgets{oldest: 0.5, newest: 0.45},
+			wantStatus:     "burning",
+			wantRemaining:  0.45,
+			wantCompliance: ptr(0.9995),
+			wantBurned:     0.05,
+			wantBurnRate:   ptr(1.2),
+		},
+		{
+			name:           "exhausted",
+			slo:            &monitoring.ServiceLevelObjective{Goal: 0.99, RollingPeriod: "604800s"},
+			budgets:        &budgets{oldest: -0.1, newest: -0.1},
+			wantStatus:     "exhausted",
+			wantRemaining:  -0.1,
+			wantCompliance: ptr(0.989),
+			wantBurned:     0,
+			wantBurnRate:   ptr(0),
+		},
+		{
+			name:          "goal of one has no compliance",
+			slo:           &monitoring.ServiceLevelObjective{Goal: 1, RollingPeriod: "86400s"},
+			budgets:       &budgets{oldest: 1, newest: 1},
+			wantStatus:    "ok",
+			wantRemaining: 1,
+			wantBurnRate:  ptr(0),
+		},
+		{
+			name:       "no data",
+			slo:        &monitoring.ServiceLevelObjective{Goal: 0.99, RollingPeriod: "2592000s"},
+			wantStatus: "no_data",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.slo.Name = service + "/serviceLevelObjectives/availability"
+			filter := `select_slo_budget_fraction("` + tt.slo.Name + `")`
+			api := &fakeAPI{
+				Services:   []*monitoring.MService{{Name: service, DisplayName: "Checkout"}},
+				SLOs:       map[string][]*monitoring.ServiceLevelObjective{service: {tt.slo}},
+				TimeSeries: map[string][]*monitoring.TimeSeries{},
+			}
+			if tt.budgets != nil {
+				// Points arrive newest first
+				api.TimeSeries[filter] = []*monitoring.TimeSeries{{Points: []*monitoring.Point{
+					{Value: &monitoring.TypedValue{DoubleValue: ptr(tt.budgets.newest)}},
+					{Value: &monitoring.TypedValue{DoubleValue: ptr(tt.budgets.oldest)}},
+				}}}
+			}
+			tool := NewListSLOsTool(api, "example-project", logging.NewLogCache())
+
+			result, err := tool.Execute(context.Background(), map[string]interface{}{
+				"startTime": "2024-05-01T10:00:00Z",
+				"endTime":   "2024-05-01T11:00:00Z",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+			if result.IsError {
+				t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+			}
+
+			wantQuery := TimeSeriesQuery{
+				Filter:          filter,
+				StartTime:       "2024-05-01T10:00:00Z",
+				EndTime:         "2024-05-01T11:00:00Z",
+				AlignmentPeriod: "60s",
+				Aligner:         "ALIGN_NEXT_OLDER",
+				MaxSeries:       1,
+			}
+			if len(api.timeSeriesQueries) != 1 || !equalTimeSeriesQuery(api.timeSeriesQueries[0], wantQuery) {
+				t.Errorf("queries = %+v, want %+v", api.timeSeriesQueries, wantQuery)
+			}
+
+			var got listSLOsResult
+			if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+				t.Fatal(err)
+			}
+			if got.Count != 1 {
+				t.Fatalf("got %d SLOs, want 1", got.Count)
+			}
+			status := got.SLOs[0]
+			if status.ID != "availability" || status.Service != "Checkout" || status.Status != tt.wantStatus {
+				t.Errorf("got %s of %s with status %s, want availability of Checkout with %s", status.ID, status.Service, status.Status, tt.wantStatus)
+			}
+			if tt.budgets == nil {
+				if status.BudgetRemaining != nil || status.Compliance != nil || status.BurnRate != nil {
+					t.Errorf("status without data has values: %+v", status)
+				}
+				return
+			}
+			checkValue(t, "budgetRemaining", status.BudgetRemaining, &tt.wantRemaining)
+			checkValue(t, "compliance", status.Compliance, tt.wantCompliance)
+			checkValue(t, "budgetBurned", status.BudgetBurned, &tt.wantBurned)
+			checkValue(t, "burnRate", status.BurnRate, tt.wantBurnRate)
+		})
+	}
+}
+
+func TestSLOPeriod(t *testing.T) {
+	tests := []struct {
+		slo  *monitoring.ServiceLevelObjective
+		want string
+	}{
+		{&monitoring.ServiceLevelObjective{RollingPeriod: "2419200s"}, "rolling 28d"},
+		{&monitoring.ServiceLevelObjective{RollingPeriod: "43200s"}, "rolling 12h0m0s"},
+		{&monitoring.ServiceLevelObjective{CalendarPeriod: "MONTH"}, "calendar month"},
+	}
+
+	for _, tt := range tests {
+		if got := sloPeriod(tt.slo); got != tt.want {
+			t.Errorf("sloPeriod(%+v) = %q, want %q", tt.slo, got, tt.want)
+		}
+	}
+}
+
+func checkValue(t *testing.T, name string, got, want *float64) {
+	t.Helper()
+	switch {
+	case want == nil && got != nil:
+		t.Errorf("%s = %v, want none", name, *got)
+	case want != nil && got == nil:
+		t.Errorf("%s missing, want %v", name, *want)
+	case want != nil && *got != *want:
+		t.Errorf("%s = %v, want %v", name, *got, *want)
+	}
+}
+
+func equalTimeSeriesQuery(a, b TimeSeriesQuery) bool {
+	return a.Filter == b.Filter && a.StartTime == b.StartTime && a.EndTime == b.EndTime &&
+		a.AlignmentPeriod == b.AlignmentPeriod && a.Aligner == b.Aligner && a.Reducer == b.Reducer &&
+		len(a.GroupBy) == 0 && len(b.GroupBy) == 0 && a.MaxSeries == b.MaxSeries
+}
+
+func ptr(v float64) *float64 {
+	return &v
+}
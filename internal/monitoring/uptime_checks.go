@@ -0,0 +1,290 @@
+package monitoring
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"path"
+	"sort"
+	"strings"
+	"time"
+
+	monitoring "google.golang.org/api/monitoring/v3"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	uptimeCheckPassedMetric = "monitoring.googleapis.com/uptime_check/check_passed"
+	// Long enough to hold a result of the slowest check period (15 minutes)
+	uptimeStatusWindow = 20 * time.Minute
+	// One series per check and region
+	maxUptimeSeries = 1000
+)
+
+const (
+	uptimePassing = "passing"
+	uptimeFailing = "failing"
+	uptimePartial = "partial"
+	uptimeUnknown = "unknown"
+)
+
+type ListUptimeChecksTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListUptimeChecksArgs struct {
+	Query       string `json:"query,omitempty"`
+	FailingOnly bool   `json:"failingOnly,omitempty"`
+}
+
+type uptimeRegion struct {
+	Region    string `json:"region"`
+	Passed    bool   `json:"passed"`
+	CheckedAt string `json:"checkedAt"`
+}
+
+type uptimeCheck struct {
+	ID          string         `json:"id"`
+	DisplayName string         `json:"displayName"`
+	Protocol    string         `json:"protocol"`
+	Target      string         `json:"target"`
+	Period      string         `json:"period,omitempty"`
+	Timeout     string         `json:"timeout,omitempty"`
+	Status      string         `json:"status"`
+	Regions     []uptimeRegion `json:"regions,omitempty"`
+}
+
+type listUptimeChecksResult struct {
+	Count   int           `json:"count"`
+	Failing int           `json:"failing"`
+	Checks  []uptimeCheck `json:"checks"`
+}
+
+func NewListUptimeChecksTool(api API, projectID string, cache *logging.LogCache) *ListUptimeChecksTool {
+	return &ListUptimeChecksTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListUptimeChecksTool) Name() string {
+	return "list_uptime_checks"
+}
+
+func (t *ListUptimeChecksTool) Description() string {
+	return "List Cloud Monitoring uptime checks with their target and the latest pass/fail result from each checker region. status is passing, failing (every region failed), partial or unknown (no recent results). Failing checks come first; query matches display names case-insensitively."
+}
+
+func (t *ListUptimeChecksTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"query": {
+				Type: "string",
+			},
+			"failingOnly": {
+				Type: "boolean",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListUptimeChecksTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListUptimeChecksArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"bucket":    time.Now().Truncate(t.cache.TimeBucket()),
+	})
+
+	var checks []uptimeCheck
+	if t.cache.GetValue(cacheKey, &checks) {
+		log.Printf("Cache hit for uptime checks")
+	} else {
+		err := t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			var err error
+			checks, err = t.loadChecks(ctx)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to list uptime checks: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error listing uptime checks: %v", err)), nil
+		}
+		t.cache.SetValue(cacheKey, checks, time.Minute)
+	}
+
+	result := listUptimeChecksResult{Checks: []uptimeCheck{}}
+	query := strings.ToLower(params.Query)
+	for _, check := range checks {
+		if query != "" && !strings.Contains(strings.ToLower(check.DisplayName), query) {
+			continue
+		}
+		failing := check.Status == uptimeFailing || check.Status == uptimePartial
+		if failing {
+			result.Failing++
+		}
+		if params.FailingOnly && !failing {
+			continue
+		}
+		result.Checks = append(result.Checks, check)
+	}
+	result.Count = len(result.Checks)
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal uptime checks: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+// loadChecks joins the check configurations with the newest check_passed
+// point of each check and region
+func (t *ListUptimeChecksTool) loadChecks(ctx context.Context) ([]uptimeCheck, error) {
+	configs, err := t.api.ListUptimeCheckConfigs(ctx, t.projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	end := time.Now().UTC()
+	timeSeries, _, err := t.api.ListTimeSeries(ctx, t.projectID, TimeSeriesQuery{
+		Filter:    fmt.Sprintf(`metric.type="%s"`, uptimeCheckPassedMetric),
+		StartTime: end.Add(-uptimeStatusWindow).Format(time.RFC3339),
+		EndTime:   end.Format(time.RFC3339),
+		MaxSeries: maxUptimeSeries,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	regions := make(map[string][]uptimeRegion)
+	for _, ts := range timeSeries {
+		if ts.Metric == nil || len(ts.Points) == 0 || ts.Points[0].Interval == nil {
+			continue
+		}
+		// Points arrive newest first
+		value, ok := pointValue(ts.Points[0].Value)
+		if !ok {
+			continue
+		}
+		checkID := ts.Metric.Labels["check_id"]
+		regions[checkID] = append(regions[checkID], uptimeRegion{
+			Region:    ts.Metric.Labels["checker_location"],
+			Passed:    value == 1,
+			CheckedAt: ts.Points[0].Interval.EndTime,
+		})
+	}
+
+	var checks []uptimeCheck
+	for _, config := range configs {
+		check := summarizeUptimeCheck(config)
+		check.Regions = regions[check.ID]
+		sort.Slice(check.Regions, func(i, j int) bool {
+			return check.Regions[i].Region < check.Regions[j].Region
+		})
+		check.Status = uptimeStatus(check.Regions)
+		checks = append(checks, check)
+	}
+	sort.SliceStable(checks, func(i, j int) bool {
+		return uptimeRank(checks[i].Status) < uptimeRank(checks[j].Status)
+	})
+	return checks, nil
+}
+
+func summarizeUptimeCheck(config *monitoring.UptimeCheckConfig) uptimeCheck {
+	check := uptimeCheck{
+		ID:          path.Base(config.Name),
+		DisplayName: config.DisplayName,
+		Period:      config.Period,
+		Timeout:     config.Timeout,
+	}
+
+	var host string
+	if config.MonitoredResource != nil {
+		host = config.MonitoredResource.Labels["host"]
+		if host == "" {
+			host = config.MonitoredResource.Type
+		}
+	} else if config.ResourceGroup != nil {
+		host = "group " + path.Base(config.ResourceGroup.GroupId)
+	}
+
+	switch {
+	case config.HttpCheck != nil:
+		check.Protocol = "HTTP"
+		scheme := "http"
+		if config.HttpCheck.UseSsl {
+			check.Protocol = "HTTPS"
+			scheme = "https"
+		}
+		check.Target = fmt.Sprintf("%s://%s", scheme, host)
+		if config.HttpCheck.Port != 0 {
+			check.Target += fmt.Sprintf(":%d", config.HttpCheck.Port)
+		}
+		check.Target += config.HttpCheck.Path
+	case config.TcpCheck != nil:
+		check.Protocol = "TCP"
+		check.Target = fmt.Sprintf("%s:%d", host, config.TcpCheck.Port)
+	case config.SyntheticMonitor != nil:
+		check.Protocol = "SYNTHETIC"
+		if fn := config.SyntheticMonitor.CloudFunctionV2; fn != nil {
+			check.Target = fn.Name
+		}
+	default:
+		check.Target = host
+	}
+	return check
+}
+
+func uptimeStatus(regions []uptimeRegion) string {
+	if len(regions) == 0 {
+		return uptimeUnknown
+	}
+	var failed int
+	for _, r := range regions {
+		if !r.Passed {
+			failed++
+		}
+	}
+	switch failed {
+	case 0:
+		return uptimePassing
+	case len(regions):
+		return uptimeFailing
+	default:
+		return uptimePartial
+	}
+}
+
+func uptimeRank(status string) int {
+	switch status {
+	case uptimeFailing:
+		return 0
+	case uptimePartial:
+		return 1
+	case uptimeUnknown:
+		return 2
+	default:
+		return 3
+	}
+}
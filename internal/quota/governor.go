@@ -0,0 +1,86 @@
+package quota
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sync/atomic"
+	"time"
+
+	"golang.org/x/time/rate"
+)
+
+// DefaultReadsPerMinute matches the default Cloud Logging API read quota
+const DefaultReadsPerMinute = 60
+
+// Governor is a process-wide token bucket shared by every tool and session.
+// Callers queue in Wait until a read token is available, so the server stays
+// within the API quota instead of reacting to ResourceExhausted errors.
+type Governor struct {
+	limiter        *rate.Limiter
+	readsPerMinute int
+	waiting        atomic.Int64
+	granted        atomic.Int64
+	rejected       atomic.Int64
+}
+
+// Status is a snapshot of the remaining budget
+type Status struct {
+	ReadsPerMinute int     `json:"readsPerMinute"`
+	Remaining      int     `json:"remaining"`
+	Waiting        int64   `json:"waiting"`
+	NextTokenIn    string  `json:"nextTokenIn,omitempty"`
+	FullRefillIn   string  `json:"fullRefillIn,omitempty"`
+	Granted        int64   `json:"granted"`
+	Rejected       int64   `json:"rejected"`
+	Utilization    float64 `json:"utilization"`
+}
+
+func NewGovernor(readsPerMinute int) *Governor {
+	if readsPerMinute <= 0 {
+		readsPerMinute = DefaultReadsPerMinute
+	}
+	// Allow the full per-minute budget as a burst, refilled evenly over the minute
+	limit := rate.Every(time.Minute / time.Duration(readsPerMinute))
+	return &Governor{
+		limiter:        rate.NewLimiter(limit, readsPerMinute),
+		readsPerMinute: readsPerMinute,
+	}
+}
+
+// Wait blocks until a read token is available. It fails immediately if the
+// context deadline would pass before the token is granted.
+func (g *Governor) Wait(ctx context.Context) error {
+	g.waiting.Add(1)
+	defer g.waiting.Add(-1)
+
+	if err := g.limiter.Wait(ctx); err != nil {
+		g.rejected.Add(1)
+		return fmt.Errorf("quota budget exhausted: %w", err)
+	}
+	g.granted.Add(1)
+	return nil
+}
+
+func (g *Governor) Status() Status {
+	tokens := g.limiter.Tokens()
+	perToken := time.Minute / time.Duration(g.readsPerMinute)
+
+	status := Status{
+		ReadsPerMinute: g.readsPerMinute,
+		Remaining:      int(math.Max(0, math.Floor(tokens))),
+		Waiting:        g.waiting.Load(),
+		Granted:        g.granted.Load(),
+		Rejected:       g.rejected.Load(),
+		Utilization:    math.Round((1-tokens/float64(g.readsPerMinute))*1000) / 1000,
+	}
+
+	if tokens < 1 {
+		status.NextTokenIn = (time.Duration((1 - tokens) * float64(perToken))).Round(time.Millisecond).String()
+	}
+	if missing := float64(g.readsPerMinute) - tokens; missing > 0 {
+		status.FullRefillIn = (time.Duration(missing * float64(perToken))).Round(time.Millisecond).String()
+	}
+
+	return status
+}
@@ -0,0 +1,51 @@
+package quota
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+type StatusTool struct {
+	governor *Governor
+}
+
+type StatusArgs struct{}
+
+func NewStatusTool(governor *Governor) *StatusTool {
+	return &StatusTool{
+		governor: governor,
+	}
+}
+
+func (t *StatusTool) Name() string {
+	return "quota_status"
+}
+
+func (t *StatusTool) Description() string {
+	return "Report the remaining Google Cloud API read budget shared by all tools, so queries can be planned without hitting quota limits."
+}
+
+func (t *StatusTool) Schema() types.Schema {
+	return types.Schema{
+		Type:                 "object",
+		Properties:           map[string]types.Schema{},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *StatusTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	statusJSON, err := json.MarshalIndent(t.governor.Status(), "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal quota status: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(statusJSON),
+		}},
+	}, nil
+}
@@ -2,11 +2,23 @@ package server
 
 import (
 	"context"
+	"encoding/json"
+	"fmt"
 	"log"
+	"os"
+	"path/filepath"
+	"time"
 
 	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/takashabe/gco-o11y-mcp/internal/cloudrun"
+	"github.com/takashabe/gco-o11y-mcp/internal/errorreporting"
 	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/internal/monitoring"
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
+	"github.com/takashabe/gco-o11y-mcp/internal/trace"
 	"github.com/takashabe/gco-o11y-mcp/internal/transport"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+	"golang.org/x/oauth2/google"
 )
 
 // GCPObservabilityMCPServer はGCP観測性データ用のMCPサーバー
@@ -14,6 +26,12 @@ type GCPObservabilityMCPServer struct {
 	server        *mcp.Server
 	transport     transport.Transport
 	loggingClient *logging.Client
+	traceAPI      trace.API
+	monitoringAPI monitoring.API
+	errorsAPI     errorreporting.API
+	cloudRunAPI   cloudrun.API
+	governor      *quota.Governor
+	exportDir     string
 }
 
 // Config はサーバーの設定
@@ -22,6 +40,24 @@ type Config struct {
 	ServerVersion string
 	TransportType string
 	HTTPAddr      string // Streamable HTTPで使用
+	CacheDir      string // 空の場合はディスクキャッシュを無効化
+	CacheMaxBytes int64  // ディスクキャッシュの最大サイズ
+	// キャッシュキー生成時にタイムスタンプを丸める単位
+	CacheTimeBucket time.Duration
+	// 全ツール・全セッションで共有するAPI読み取りの上限（回/分）
+	ReadsPerMinute int
+	// ユーザー定義のプリセットクエリファイル（YAML/JSON）
+	PresetFile string
+	// 空の場合は環境変数や認証情報から検出
+	ProjectID string
+	// maxOutputTokensを指定しない呼び出しに適用するツール結果の上限
+	MaxOutputTokens int
+	// ツール結果の個人情報・シークレットを伏せ字にする
+	Redact bool
+	// 伏せ字の設定ファイル（YAML/JSON、指定時はRedactも有効）
+	RedactionFile string
+	// export_logsの出力先ディレクトリ（export://で公開）
+	ExportDir string
 }
 
 // NewGCPObservabilityMCPServer は新しいサーバーインスタンスを作成
@@ -33,13 +69,79 @@ func NewGCPObservabilityMCPServer(config Config) (*GCPObservabilityMCPServer, er
 	}
 	server := mcp.NewServer(impl, nil)
 
-	// Cloud Loggingクライアントを初期化
 	ctx := context.Background()
-	loggingClient, err := logging.NewClient(ctx, "")
+	projectID := config.ProjectID
+	if projectID == "" {
+		projectID = DetectProjectID(ctx)
+	}
+
+	// Cloud Loggingクライアントを初期化
+	loggingClient, err := logging.NewClient(ctx, projectID)
+	if err != nil {
+		return nil, err
+	}
+
+	// プロセス全体で共有するクォータ制御
+	governor := quota.NewGovernor(config.ReadsPerMinute)
+	loggingClient.SetQuotaGovernor(governor)
+
+	// Cloud Traceクライアントを初期化（Loggingと同じ読み取り上限を共有）
+	traceAPI, err := trace.NewAPI(ctx, governor)
 	if err != nil {
 		return nil, err
 	}
 
+	// Cloud Monitoringクライアントを初期化（キャッシュと読み取り上限はLoggingと共有）
+	monitoringAPI, err := monitoring.NewAPI(ctx, governor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Error Reportingクライアントを初期化
+	errorsAPI, err := errorreporting.NewAPI(ctx, governor)
+	if err != nil {
+		return nil, err
+	}
+
+	// Cloud Run Admin APIクライアントを初期化
+	cloudRunAPI, err := cloudrun.NewAPI(ctx, governor)
+	if err != nil {
+		return nil, err
+	}
+
+	if config.CacheTimeBucket > 0 {
+		loggingClient.Cache().SetTimeBucket(config.CacheTimeBucket)
+	}
+
+	// 確定済みの過去期間のクエリ結果をディスクに保存
+	if config.CacheDir != "" {
+		diskCache, err := logging.NewDiskCache(config.CacheDir, config.CacheMaxBytes)
+		if err != nil {
+			return nil, err
+		}
+		loggingClient.Cache().SetDiskTier(diskCache)
+		log.Printf("Disk cache enabled: %s", config.CacheDir)
+	}
+
+	// 大きすぎるツール結果はこの上限に収まるよう縮退させる
+	if config.MaxOutputTokens > 0 {
+		logging.SetDefaultMaxOutputTokens(config.MaxOutputTokens)
+	}
+
+	// ツール結果の個人情報・シークレットを伏せ字にする
+	if config.Redact || config.RedactionFile != "" {
+		if err := logging.EnableRedaction(config.RedactionFile); err != nil {
+			return nil, err
+		}
+	}
+
+	// ユーザー定義のプリセットクエリを読み込み（変更時は自動で再読み込み）
+	if config.PresetFile != "" {
+		if err := logging.LoadPresetFile(config.PresetFile); err != nil {
+			return nil, err
+		}
+	}
+
 	// 適切なトランスポートを選択
 	var tp transport.Transport
 	switch config.TransportType {
@@ -55,6 +157,15 @@ func NewGCPObservabilityMCPServer(config Config) (*GCPObservabilityMCPServer, er
 		server:        server,
 		transport:     tp,
 		loggingClient: loggingClient,
+		traceAPI:      traceAPI,
+		monitoringAPI: monitoringAPI,
+		errorsAPI:     errorsAPI,
+		cloudRunAPI:   cloudRunAPI,
+		governor:      governor,
+		exportDir:     config.ExportDir,
+	}
+	if s.exportDir == "" {
+		s.exportDir = DefaultExportDir()
 	}
 
 	// ツールを登録
@@ -63,6 +174,26 @@ func NewGCPObservabilityMCPServer(config Config) (*GCPObservabilityMCPServer, er
 	return s, nil
 }
 
+// DefaultExportDir はexport_logsの既定の出力先
+func DefaultExportDir() string {
+	return filepath.Join(os.TempDir(), "gcp-o11y-mcp-exports")
+}
+
+// DetectProjectID は環境変数、Application Default Credentialsの順にプロジェクトIDを検出
+func DetectProjectID(ctx context.Context) string {
+	for _, env := range []string{"GOOGLE_CLOUD_PROJECT", "GCP_PROJECT", "CLOUDSDK_CORE_PROJECT"} {
+		if projectID := os.Getenv(env); projectID != "" {
+			return projectID
+		}
+	}
+	creds, err := google.FindDefaultCredentials(ctx)
+	if err != nil || creds.ProjectID == "" {
+		log.Printf("Could not detect a project ID; set -project or GOOGLE_CLOUD_PROJECT")
+		return ""
+	}
+	return creds.ProjectID
+}
+
 // registerTools は利用可能なツールを登録
 func (s *GCPObservabilityMCPServer) registerTools() {
 	// Preset Query Tool
@@ -85,6 +216,172 @@ func (s *GCPObservabilityMCPServer) registerTools() {
 		Name:        searchTool.Name(),
 		Description: searchTool.Description(),
 	}, s.createSearchLogsHandler(searchTool))
+
+	// Log Histogram Tool
+	histogramTool := logging.NewLogHistogramTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        histogramTool.Name(),
+		Description: histogramTool.Description(),
+	}, createToolHandler[logging.LogHistogramArgs](histogramTool))
+
+	// Group Errors Tool
+	groupErrorsTool := logging.NewGroupErrorsTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        groupErrorsTool.Name(),
+		Description: groupErrorsTool.Description(),
+	}, createToolHandler[logging.GroupErrorsArgs](groupErrorsTool))
+
+	// Log Patterns Tool
+	patternsTool := logging.NewLogPatternsTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        patternsTool.Name(),
+		Description: patternsTool.Description(),
+	}, createToolHandler[logging.LogPatternsArgs](patternsTool))
+
+	// Request Stats Tool
+	requestStatsTool := logging.NewRequestStatsTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        requestStatsTool.Name(),
+		Description: requestStatsTool.Description(),
+	}, createToolHandler[logging.RequestStatsArgs](requestStatsTool))
+
+	// Compare Logs Tool
+	compareTool := logging.NewCompareLogsTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        compareTool.Name(),
+		Description: compareTool.Description(),
+	}, createToolHandler[logging.CompareLogsArgs](compareTool))
+
+	// Trace Logs Tool
+	traceLogsTool := logging.NewGetTraceLogsTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        traceLogsTool.Name(),
+		Description: traceLogsTool.Description(),
+	}, createToolHandler[logging.GetTraceLogsArgs](traceLogsTool))
+
+	// Get Trace Tool
+	getTraceTool := trace.NewGetTraceTool(s.traceAPI, s.loggingClient, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        getTraceTool.Name(),
+		Description: getTraceTool.Description(),
+	}, createToolHandler[trace.GetTraceArgs](getTraceTool))
+
+	// List Traces Tool
+	listTracesTool := trace.NewListTracesTool(s.traceAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        listTracesTool.Name(),
+		Description: listTracesTool.Description(),
+	}, createToolHandler[trace.ListTracesArgs](listTracesTool))
+
+	// Query Metrics Tool
+	metricsTool := monitoring.NewQueryMetricsTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        metricsTool.Name(),
+		Description: metricsTool.Description(),
+	}, createToolHandler[monitoring.QueryMetricsArgs](metricsTool))
+
+	// Alert Policies Tool
+	alertPoliciesTool := monitoring.NewListAlertPoliciesTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        alertPoliciesTool.Name(),
+		Description: alertPoliciesTool.Description(),
+	}, createToolHandler[monitoring.ListAlertPoliciesArgs](alertPoliciesTool))
+
+	// List Incidents Tool
+	incidentsTool := monitoring.NewListIncidentsTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        incidentsTool.Name(),
+		Description: incidentsTool.Description(),
+	}, createToolHandler[monitoring.ListIncidentsArgs](incidentsTool))
+
+	// Get Incident Tool
+	incidentTool := monitoring.NewGetIncidentTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        incidentTool.Name(),
+		Description: incidentTool.Description(),
+	}, createToolHandler[monitoring.GetIncidentArgs](incidentTool))
+
+	// Notification Channels Tool
+	channelsTool := monitoring.NewListNotificationChannelsTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        channelsTool.Name(),
+		Description: channelsTool.Description(),
+	}, createToolHandler[monitoring.ListNotificationChannelsArgs](channelsTool))
+
+	// Uptime Checks Tool
+	uptimeTool := monitoring.NewListUptimeChecksTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        uptimeTool.Name(),
+		Description: uptimeTool.Description(),
+	}, createToolHandler[monitoring.ListUptimeChecksArgs](uptimeTool))
+
+	// SLO Tool
+	slosTool := monitoring.NewListSLOsTool(s.monitoringAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        slosTool.Name(),
+		Description: slosTool.Description(),
+	}, createToolHandler[monitoring.ListSLOsArgs](slosTool))
+
+	// List Error Groups Tool
+	errorGroupsTool := errorreporting.NewListErrorGroupsTool(s.errorsAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        errorGroupsTool.Name(),
+		Description: errorGroupsTool.Description(),
+	}, createToolHandler[errorreporting.ListErrorGroupsArgs](errorGroupsTool))
+
+	// Get Error Events Tool
+	errorEventsTool := errorreporting.NewGetErrorEventsTool(s.errorsAPI, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        errorEventsTool.Name(),
+		Description: errorEventsTool.Description(),
+	}, createToolHandler[errorreporting.GetErrorEventsArgs](errorEventsTool))
+
+	// Describe Cloud Run Service Tool
+	describeServiceTool := cloudrun.NewDescribeServiceTool(s.cloudRunAPI, s.loggingClient, s.loggingClient.ProjectID(), s.loggingClient.Cache())
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        describeServiceTool.Name(),
+		Description: describeServiceTool.Description(),
+	}, createToolHandler[cloudrun.DescribeServiceArgs](describeServiceTool))
+
+	// Search Audit Logs Tool
+	auditTool := logging.NewSearchAuditLogsTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        auditTool.Name(),
+		Description: auditTool.Description(),
+	}, createToolHandler[logging.SearchAuditLogsArgs](auditTool))
+
+	// Correlate Deploys Tool
+	correlateDeploysTool := logging.NewCorrelateDeploysTool(s.loggingClient)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        correlateDeploysTool.Name(),
+		Description: correlateDeploysTool.Description(),
+	}, createToolHandler[logging.CorrelateDeploysArgs](correlateDeploysTool))
+
+	// Export Logs Tool
+	exportTool := logging.NewExportLogsTool(s.loggingClient, s.exportDir)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        exportTool.Name(),
+		Description: exportTool.Description(),
+	}, s.createExportLogsHandler(exportTool))
+	s.server.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "log_export",
+		Description: "Files written by export_logs",
+		URITemplate: logging.ExportURIScheme + "://{+path}",
+	}, s.readExportResource)
+
+	// List Preset Queries Tool
+	listPresetTool := logging.NewListPresetQueriesTool()
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        listPresetTool.Name(),
+		Description: listPresetTool.Description(),
+	}, createToolHandler[logging.ListPresetQueriesArgs](listPresetTool))
+
+	// Quota Status Tool
+	quotaTool := quota.NewStatusTool(s.governor)
+	mcp.AddTool(s.server, &mcp.Tool{
+		Name:        quotaTool.Name(),
+		Description: quotaTool.Description(),
+	}, createToolHandler[quota.StatusArgs](quotaTool))
 }
 
 // Start はサーバーを開始
@@ -103,11 +400,16 @@ func (s *GCPObservabilityMCPServer) createPresetQueryHandler(tool *logging.Prese
 	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[logging.PresetQueryArgs]) (*mcp.CallToolResultFor[any], error) {
 		// 既存のツールのExecuteメソッドを呼び出し
 		args := map[string]interface{}{
-			"queryName":  params.Arguments.QueryName,
-			"parameters": params.Arguments.Parameters,
+			"queryName":       params.Arguments.QueryName,
+			"parameters":      params.Arguments.Parameters,
+			"fields":          params.Arguments.Fields,
+			"maxPayloadBytes": params.Arguments.MaxPayloadBytes,
+			"compact":         params.Arguments.Compact,
+			"maxOutputTokens": params.Arguments.MaxOutputTokens,
+			"pageToken":       params.Arguments.PageToken,
 		}
 
-		result, err := tool.Execute(args)
+		result, err := tool.Execute(ctx, args)
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
@@ -115,16 +417,7 @@ func (s *GCPObservabilityMCPServer) createPresetQueryHandler(tool *logging.Prese
 			}, nil
 		}
 
-		// types.CallToolResultからmcp.CallToolResultForに変換
-		var content []mcp.Content
-		for _, c := range result.Content {
-			content = append(content, &mcp.TextContent{Text: c.Text})
-		}
-
-		return &mcp.CallToolResultFor[any]{
-			Content: content,
-			IsError: result.IsError,
-		}, nil
+		return toMCPResult(result, params.Arguments.MaxOutputTokens), nil
 	}
 }
 
@@ -133,12 +426,17 @@ func (s *GCPObservabilityMCPServer) createListLogEntriesHandler(tool *logging.Li
 	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[logging.ListLogEntriesArgs]) (*mcp.CallToolResultFor[any], error) {
 		// 既存のツールのExecuteメソッドを呼び出し
 		args := map[string]interface{}{
-			"filter":   params.Arguments.Filter,
-			"pageSize": params.Arguments.PageSize,
-			"orderBy":  params.Arguments.OrderBy,
+			"filter":          params.Arguments.Filter,
+			"pageSize":        params.Arguments.PageSize,
+			"orderBy":         params.Arguments.OrderBy,
+			"fields":          params.Arguments.Fields,
+			"maxPayloadBytes": params.Arguments.MaxPayloadBytes,
+			"compact":         params.Arguments.Compact,
+			"maxOutputTokens": params.Arguments.MaxOutputTokens,
+			"pageToken":       params.Arguments.PageToken,
 		}
 
-		result, err := tool.Execute(args)
+		result, err := tool.Execute(ctx, args)
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
@@ -146,16 +444,7 @@ func (s *GCPObservabilityMCPServer) createListLogEntriesHandler(tool *logging.Li
 			}, nil
 		}
 
-		// types.CallToolResultからmcp.CallToolResultForに変換
-		var content []mcp.Content
-		for _, c := range result.Content {
-			content = append(content, &mcp.TextContent{Text: c.Text})
-		}
-
-		return &mcp.CallToolResultFor[any]{
-			Content: content,
-			IsError: result.IsError,
-		}, nil
+		return toMCPResult(result, params.Arguments.MaxOutputTokens), nil
 	}
 }
 
@@ -164,12 +453,115 @@ func (s *GCPObservabilityMCPServer) createSearchLogsHandler(tool *logging.Search
 	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[logging.SearchLogsArgs]) (*mcp.CallToolResultFor[any], error) {
 		// 既存のツールのExecuteメソッドを呼び出し
 		args := map[string]interface{}{
-			"query":    params.Arguments.Query,
-			"severity": params.Arguments.Severity,
-			"pageSize": params.Arguments.PageSize,
+			"query":           params.Arguments.Query,
+			"startTime":       params.Arguments.StartTime,
+			"endTime":         params.Arguments.EndTime,
+			"severity":        params.Arguments.Severity,
+			"resource":        params.Arguments.Resource,
+			"logName":         params.Arguments.LogName,
+			"pageSize":        params.Arguments.PageSize,
+			"orderBy":         params.Arguments.OrderBy,
+			"fields":          params.Arguments.Fields,
+			"maxPayloadBytes": params.Arguments.MaxPayloadBytes,
+			"compact":         params.Arguments.Compact,
+			"maxOutputTokens": params.Arguments.MaxOutputTokens,
+			"pageToken":       params.Arguments.PageToken,
+		}
+
+		result, err := tool.Execute(ctx, args)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				IsError: true,
+			}, nil
+		}
+
+		return toMCPResult(result, params.Arguments.MaxOutputTokens), nil
+	}
+}
+
+// createExportLogsHandler はExport Logs Tool用のハンドラーを作成
+// クライアントがprogressTokenを指定した場合は進捗を通知する
+func (s *GCPObservabilityMCPServer) createExportLogsHandler(tool *logging.ExportLogsTool) mcp.ToolHandlerFor[logging.ExportLogsArgs, any] {
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[logging.ExportLogsArgs]) (*mcp.CallToolResultFor[any], error) {
+		args := map[string]interface{}{
+			"filter":    params.Arguments.Filter,
+			"startTime": params.Arguments.StartTime,
+			"endTime":   params.Arguments.EndTime,
+			"format":    params.Arguments.Format,
+			"columns":   params.Arguments.Columns,
+			"output":    params.Arguments.Output,
+		}
+
+		if token := params.GetProgressToken(); token != nil {
+			ctx = logging.WithExportProgress(ctx, func(p logging.ExportProgress) {
+				err := ss.NotifyProgress(ctx, &mcp.ProgressNotificationParams{
+					ProgressToken: token,
+					Progress:      float64(p.Entries),
+					Message:       fmt.Sprintf("Exported %d entries through %s", p.Entries, p.Through),
+				})
+				if err != nil {
+					log.Printf("Failed to notify export progress: %v", err)
+				}
+			})
+		}
+
+		result, err := tool.Execute(ctx, args)
+		if err != nil {
+			return &mcp.CallToolResultFor[any]{
+				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
+				IsError: true,
+			}, nil
+		}
+		return toMCPResult(result, 0), nil
+	}
+}
+
+// readExportResource はexport_logsが書き出したファイルを返す
+func (s *GCPObservabilityMCPServer) readExportResource(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	path, err := logging.ExportPath(s.exportDir, params.URI)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	contents := &mcp.ResourceContents{URI: params.URI}
+	switch filepath.Ext(path) {
+	case ".parquet":
+		contents.MIMEType = "application/vnd.apache.parquet"
+		contents.Blob = data
+	case ".csv":
+		contents.MIMEType = "text/csv"
+		contents.Text = string(data)
+	default:
+		contents.MIMEType = "application/x-ndjson"
+		contents.Text = string(data)
+	}
+	return &mcp.ReadResourceResult{Contents: []*mcp.ResourceContents{contents}}, nil
+}
+
+// toolExecutor はツールの実行インターフェース
+type toolExecutor interface {
+	Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error)
+}
+
+// createToolHandler は型付き引数をmapに変換してツールを実行する汎用ハンドラーを作成
+func createToolHandler[T any](tool toolExecutor) mcp.ToolHandlerFor[T, any] {
+	return func(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[T]) (*mcp.CallToolResultFor[any], error) {
+		args := map[string]interface{}{}
+		if argsBytes, err := json.Marshal(params.Arguments); err != nil {
+			return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+		} else if err := json.Unmarshal(argsBytes, &args); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
 		}
 
-		result, err := tool.Execute(args)
+		result, err := tool.Execute(ctx, args)
 		if err != nil {
 			return &mcp.CallToolResultFor[any]{
 				Content: []mcp.Content{&mcp.TextContent{Text: err.Error()}},
@@ -177,15 +569,31 @@ func (s *GCPObservabilityMCPServer) createSearchLogsHandler(tool *logging.Search
 			}, nil
 		}
 
-		// types.CallToolResultからmcp.CallToolResultForに変換
-		var content []mcp.Content
-		for _, c := range result.Content {
-			content = append(content, &mcp.TextContent{Text: c.Text})
+		maxOutputTokens, _ := args["maxOutputTokens"].(float64)
+		return toMCPResult(result, int(maxOutputTokens)), nil
+	}
+}
+
+// toMCPResult はtypes.CallToolResultをmcp.CallToolResultForに変換
+// 機密情報を伏せ字にした上で、上限を超える結果は縮退させる
+func toMCPResult(result *types.CallToolResult, maxOutputTokens int) *mcp.CallToolResultFor[any] {
+	var content []mcp.Content
+	redactions := make(map[string]int)
+	for _, c := range result.Content {
+		text, counts := logging.Redact(c.Text)
+		for name, count := range counts {
+			redactions[name] += count
 		}
+		content = append(content, &mcp.TextContent{Text: logging.FitOutput(text, maxOutputTokens)})
+	}
 
-		return &mcp.CallToolResultFor[any]{
-			Content: content,
-			IsError: result.IsError,
-		}, nil
+	mcpResult := &mcp.CallToolResultFor[any]{
+		Content: content,
+		IsError: result.IsError,
+	}
+	// 伏せ字にした件数は結果のメタデータで返す
+	if len(redactions) > 0 {
+		mcpResult.Meta = mcp.Meta{"redactions": redactions}
 	}
+	return mcpResult
 }
@@ -0,0 +1,96 @@
+package trace
+
+import (
+	"context"
+	"fmt"
+
+	cloudtrace "google.golang.org/api/cloudtrace/v1"
+	"google.golang.org/api/option"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/quota"
+)
+
+// Views of a trace returned by ListTraces
+const (
+	ViewRootSpan = "ROOTSPAN"
+	ViewComplete = "COMPLETE"
+)
+
+// ListQuery selects traces to list; fields map directly to the Cloud Trace
+// v1 projects.traces.list parameters
+type ListQuery struct {
+	Filter    string
+	StartTime string
+	EndTime   string
+	OrderBy   string
+	View      string
+	PageSize  int
+}
+
+// API is the subset of the Cloud Trace API used by the tools. It is an
+// interface so that recorded responses can stand in for the real service.
+type API interface {
+	GetTrace(ctx context.Context, projectID, traceID string) (*cloudtrace.Trace, error)
+	ListTraces(ctx context.Context, projectID string, query ListQuery) ([]*cloudtrace.Trace, error)
+}
+
+type restAPI struct {
+	service  *cloudtrace.Service
+	governor *quota.Governor
+}
+
+// NewAPI creates an API backed by the Cloud Trace REST API. Every request
+// draws from the given read budget.
+func NewAPI(ctx context.Context, governor *quota.Governor, opts ...option.ClientOption) (API, error) {
+	opts = append([]option.ClientOption{
+		option.WithScopes(cloudtrace.TraceReadonlyScope),
+	}, opts...)
+	service, err := cloudtrace.NewService(ctx, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace client: %w", err)
+	}
+	return &restAPI{
+		service:  service,
+		governor: governor,
+	}, nil
+}
+
+func (a *restAPI) GetTrace(ctx context.Context, projectID, traceID string) (*cloudtrace.Trace, error) {
+	if err := a.governor.Wait(ctx); err != nil {
+		return nil, err
+	}
+	return a.service.Projects.Traces.Get(projectID, traceID).Context(ctx).Do()
+}
+
+func (a *restAPI) ListTraces(ctx context.Context, projectID string, query ListQuery) ([]*cloudtrace.Trace, error) {
+	if err := a.governor.Wait(ctx); err != nil {
+		return nil, err
+	}
+
+	call := a.service.Projects.Traces.List(projectID).Context(ctx)
+	if query.Filter != "" {
+		call = call.Filter(query.Filter)
+	}
+	if query.StartTime != "" {
+		call = call.StartTime(query.StartTime)
+	}
+	if query.EndTime != "" {
+		call = call.EndTime(query.EndTime)
+	}
+	if query.OrderBy != "" {
+		call = call.OrderBy(query.OrderBy)
+	}
+	if query.View != "" {
+		call = call.View(query.View)
+	}
+	if query.PageSize > 0 {
+		call = call.PageSize(int64(query.PageSize))
+	}
+
+	// A single page is enough for the tools; callers ask for what they show
+	resp, err := call.Do()
+	if err != nil {
+		return nil, err
+	}
+	return resp.Traces, nil
+}
@@ -0,0 +1,184 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultLogsPerSpan = 20
+	maxTraceLogEntries = 2000
+	// Logs are written slightly before or after the spans they belong to
+	logWindowMargin = time.Minute
+)
+
+// LogSource looks up the log entries of a trace. It is satisfied by
+// *logging.Client.
+type LogSource interface {
+	TraceEntries(ctx context.Context, trace string, window logging.TimeWindow, limit int) ([]logging.LogEntry, bool, error)
+}
+
+type GetTraceTool struct {
+	api         API
+	logs        LogSource
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type GetTraceArgs struct {
+	TraceID     string `json:"traceId"`
+	SkipLogs    bool   `json:"skipLogs,omitempty"`
+	LogsPerSpan int    `json:"logsPerSpan,omitempty"`
+}
+
+type traceResult struct {
+	TraceID       string  `json:"traceId"`
+	ProjectID     string  `json:"projectId"`
+	RootSpan      string  `json:"rootSpan,omitempty"`
+	Start         string  `json:"start,omitempty"`
+	DurationMs    float64 `json:"durationMs"`
+	SpanCount     int     `json:"spanCount"`
+	LogCount      int     `json:"logCount"`
+	UnmatchedLogs int     `json:"unmatchedLogs,omitempty"`
+	LogsTruncated bool    `json:"logsTruncated,omitempty"`
+	Spans         []*Span `json:"spans"`
+}
+
+func NewGetTraceTool(api API, logs LogSource, projectID string, cache *logging.LogCache) *GetTraceTool {
+	return &GetTraceTool{
+		api:         api,
+		logs:        logs,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *GetTraceTool) Name() string {
+	return "get_trace"
+}
+
+func (t *GetTraceTool) Description() string {
+	return "Fetch a Cloud Trace trace as a call tree with per-span start offset, duration and self time, each span annotated with the log entries that share its trace and span ids. Accepts a trace id or a full projects/*/traces/* name."
+}
+
+func (t *GetTraceTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"traceId": {
+				Type: "string",
+			},
+			"skipLogs": {
+				Type: "boolean",
+			},
+			"logsPerSpan": {
+				Type: "integer",
+			},
+		},
+		Required:             []string{"traceId"},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *GetTraceTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params GetTraceArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	projectID, traceID, err := parseTraceName(t.projectID, params.TraceID)
+	if err != nil {
+		return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	if params.LogsPerSpan <= 0 {
+		params.LogsPerSpan = defaultLogsPerSpan
+	}
+
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":        t.Name(),
+		"projectId":   projectID,
+		"traceId":     traceID,
+		"skipLogs":    params.SkipLogs,
+		"logsPerSpan": params.LogsPerSpan,
+	})
+
+	var result traceResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for trace: %s", traceID)
+	} else {
+		var end time.Time
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, end, err = t.getTrace(ctx, projectID, traceID, params)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to get trace: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error getting trace: %v", err)), nil
+		}
+
+		// Spans and logs keep arriving for a short while after a request ends,
+		// and a trace without spans may not have been ingested yet
+		if result.SpanCount > 0 && logging.IsClosedWindow(end.Add(logWindowMargin).Format(time.RFC3339)) {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, time.Minute)
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal trace: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *GetTraceTool) getTrace(ctx context.Context, projectID, traceID string, params GetTraceArgs) (traceResult, time.Time, error) {
+	trace, err := t.api.GetTrace(ctx, projectID, traceID)
+	if err != nil {
+		return traceResult{}, time.Time{}, err
+	}
+
+	spans, start, end := buildSpans(trace)
+	result := traceResult{
+		TraceID:    traceID,
+		ProjectID:  projectID,
+		Start:      start.Format(time.RFC3339Nano),
+		DurationMs: milliseconds(end.Sub(start)),
+		SpanCount:  len(spans),
+		Spans:      spans,
+	}
+	if len(spans) > 0 {
+		result.RootSpan = spans[0].Name
+	}
+	if params.SkipLogs || len(spans) == 0 {
+		return result, end, nil
+	}
+
+	window := logging.TimeWindow{Start: start.Add(-logWindowMargin), End: end.Add(logWindowMargin)}
+	name := fmt.Sprintf("projects/%s/traces/%s", projectID, traceID)
+	entries, truncated, err := t.logs.TraceEntries(ctx, name, window, maxTraceLogEntries)
+	if err != nil {
+		return result, end, fmt.Errorf("failed to fetch trace logs: %w", err)
+	}
+	result.LogCount = len(entries)
+	result.LogsTruncated = truncated
+	result.UnmatchedLogs = attachLogs(spans, entries, params.LogsPerSpan)
+
+	return result, end, nil
+}
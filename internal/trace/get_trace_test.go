@@ -0,0 +1,65 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	cloudtrace "google.golang.org/api/cloudtrace/v1"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+func TestGetTraceCache(t *testing.T) {
+	var trace cloudtrace.Trace
+	loadFixture(t, "trace.json", &trace)
+	pending := &cloudtrace.Trace{ProjectId: "example-project", TraceId: "0af7651916cd43dd8448eb211c80319c"}
+	api := &fixtureAPI{traces: []*cloudtrace.Trace{&trace, pending}}
+
+	disk, err := logging.NewDiskCache(t.TempDir(), 1<<20)
+	if err != nil {
+		t.Fatal(err)
+	}
+	newCache := func() *logging.LogCache {
+		cache := logging.NewLogCache()
+		cache.SetDiskTier(disk)
+		return cache
+	}
+	tool := NewGetTraceTool(api, nil, "example-project", newCache())
+
+	get := func(traceID string) traceResult {
+		t.Helper()
+		result, err := tool.Execute(context.Background(), map[string]interface{}{"traceId": traceID, "skipLogs": true})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if result.IsError {
+			t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+		}
+		var got traceResult
+		if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+			t.Fatal(err)
+		}
+		return got
+	}
+
+	if got := get(trace.TraceId); got.SpanCount == 0 {
+		t.Fatal("finished trace has no spans")
+	}
+	if got := get(pending.TraceId); got.SpanCount != 0 {
+		t.Fatalf("got %d spans, want none", got.SpanCount)
+	}
+
+	// After a restart only the finished trace is still cached; one without
+	// spans may not have been ingested yet
+	tool.cache = newCache()
+	api.gets = 0
+	get(trace.TraceId)
+	if api.gets != 0 {
+		t.Error("finished trace was not kept in the disk cache")
+	}
+	get(pending.TraceId)
+	if api.gets != 1 {
+		t.Error("trace without spans was kept in the disk cache")
+	}
+}
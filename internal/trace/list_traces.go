@@ -0,0 +1,237 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+	"github.com/takashabe/gco-o11y-mcp/pkg/types"
+)
+
+const (
+	defaultTraceLimit = 20
+	maxTraceLimit     = 100
+	defaultTraceOrder = "duration desc"
+)
+
+type ListTracesTool struct {
+	api         API
+	projectID   string
+	cache       *logging.LogCache
+	rateLimiter *logging.RateLimiter
+}
+
+type ListTracesArgs struct {
+	RootSpanName string `json:"rootSpanName,omitempty"`
+	MinLatency   string `json:"minLatency,omitempty"`
+	Filter       string `json:"filter,omitempty"`
+	StartTime    string `json:"startTime,omitempty"`
+	EndTime      string `json:"endTime,omitempty"`
+	OrderBy      string `json:"orderBy,omitempty"`
+	Limit        int    `json:"limit,omitempty"`
+}
+
+type traceSummary struct {
+	TraceID    string            `json:"traceId"`
+	RootSpan   string            `json:"rootSpan"`
+	Start      time.Time         `json:"start"`
+	DurationMs float64           `json:"durationMs"`
+	Labels     map[string]string `json:"labels,omitempty"`
+}
+
+type listTracesResult struct {
+	Filter string         `json:"filter,omitempty"`
+	Start  time.Time      `json:"start"`
+	End    time.Time      `json:"end"`
+	Count  int            `json:"count"`
+	Traces []traceSummary `json:"traces"`
+}
+
+func NewListTracesTool(api API, projectID string, cache *logging.LogCache) *ListTracesTool {
+	return &ListTracesTool{
+		api:         api,
+		projectID:   projectID,
+		cache:       cache,
+		rateLimiter: logging.NewRateLimiter(),
+	}
+}
+
+func (t *ListTracesTool) Name() string {
+	return "list_traces"
+}
+
+func (t *ListTracesTool) Description() string {
+	return "List Cloud Trace traces in a time window (default last hour), slowest first. Filter by root span name prefix, minimum latency (e.g. 500ms) or a raw Cloud Trace filter. Use get_trace for the span breakdown and logs of a trace."
+}
+
+func (t *ListTracesTool) Schema() types.Schema {
+	return types.Schema{
+		Type: "object",
+		Properties: map[string]types.Schema{
+			"rootSpanName": {
+				Type: "string",
+			},
+			"minLatency": {
+				Type: "string",
+			},
+			"filter": {
+				Type: "string",
+			},
+			"startTime": {
+				Type: "string",
+			},
+			"endTime": {
+				Type: "string",
+			},
+			"orderBy": {
+				Type: "string",
+			},
+			"limit": {
+				Type: "integer",
+			},
+		},
+		AdditionalProperties: false,
+	}
+}
+
+func (t *ListTracesTool) Execute(ctx context.Context, args map[string]interface{}) (*types.CallToolResult, error) {
+	var params ListTracesArgs
+	if argsBytes, err := json.Marshal(args); err != nil {
+		return nil, fmt.Errorf("failed to marshal arguments: %w", err)
+	} else if err := json.Unmarshal(argsBytes, &params); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal arguments: %w", err)
+	}
+
+	window, err := logging.ParseTimeWindow(params.StartTime, params.EndTime, time.Hour)
+	if err != nil {
+		return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+	filter, err := traceFilter(params)
+	if err != nil {
+		return logging.ErrorResult(fmt.Sprintf("Error: %v", err)), nil
+	}
+
+	if params.Limit <= 0 {
+		params.Limit = defaultTraceLimit
+	}
+	if params.Limit > maxTraceLimit {
+		params.Limit = maxTraceLimit
+	}
+	if params.OrderBy == "" {
+		params.OrderBy = defaultTraceOrder
+	}
+
+	query := ListQuery{
+		Filter:    filter,
+		StartTime: window.Start.Format(time.RFC3339),
+		EndTime:   window.End.Format(time.RFC3339),
+		OrderBy:   params.OrderBy,
+		View:      ViewRootSpan,
+		PageSize:  params.Limit,
+	}
+	// Round the bounds of open windows so repeated calls within a time bucket
+	// share an entry; closed windows are cached for good and keep exact bounds
+	keyQuery := query
+	if !window.IsClosed() {
+		keyQuery.StartTime = window.Start.Truncate(t.cache.TimeBucket()).Format(time.RFC3339)
+		keyQuery.EndTime = window.End.Truncate(t.cache.TimeBucket()).Format(time.RFC3339)
+	}
+	cacheKey := t.cache.GenerateKey(map[string]interface{}{
+		"tool":      t.Name(),
+		"projectId": t.projectID,
+		"query":     keyQuery,
+	})
+
+	var result listTracesResult
+	if t.cache.GetValue(cacheKey, &result) {
+		log.Printf("Cache hit for traces: %s", filter)
+	} else {
+		err = t.rateLimiter.ExecuteWithBackoff(ctx, func() error {
+			result, err = t.listTraces(ctx, query, window)
+			return err
+		})
+		if err != nil {
+			log.Printf("Failed to list traces: %v", err)
+			return logging.ErrorResult(fmt.Sprintf("Error listing traces: %v", err)), nil
+		}
+
+		if window.IsClosed() {
+			t.cache.SetHistoricalValue(cacheKey, result)
+		} else {
+			t.cache.SetValue(cacheKey, result, time.Minute)
+		}
+	}
+
+	resultJSON, err := json.MarshalIndent(result, "", "  ")
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal traces: %w", err)
+	}
+
+	return &types.CallToolResult{
+		Content: []types.Content{{
+			Type: "text",
+			Text: string(resultJSON),
+		}},
+	}, nil
+}
+
+func (t *ListTracesTool) listTraces(ctx context.Context, query ListQuery, window logging.TimeWindow) (listTracesResult, error) {
+	traces, err := t.api.ListTraces(ctx, t.projectID, query)
+	if err != nil {
+		return listTracesResult{}, err
+	}
+
+	result := listTracesResult{
+		Filter: query.Filter,
+		Start:  window.Start,
+		End:    window.End,
+		Traces: []traceSummary{},
+	}
+	for _, trace := range traces {
+		spans, start, end := buildSpans(trace)
+		summary := traceSummary{
+			TraceID:    trace.TraceId,
+			Start:      start,
+			DurationMs: milliseconds(end.Sub(start)),
+		}
+		if len(spans) > 0 {
+			summary.RootSpan = spans[0].Name
+			summary.Labels = spans[0].Labels
+		}
+		result.Traces = append(result.Traces, summary)
+	}
+	result.Count = len(result.Traces)
+	return result, nil
+}
+
+// traceFilter builds a Cloud Trace list filter. root: matches span name
+// prefixes and latency: is a lower bound.
+func traceFilter(params ListTracesArgs) (string, error) {
+	var terms []string
+	if params.RootSpanName != "" {
+		terms = append(terms, "root:"+quoteTerm(params.RootSpanName))
+	}
+	if params.MinLatency != "" {
+		latency, err := time.ParseDuration(params.MinLatency)
+		if err != nil || latency <= 0 {
+			return "", fmt.Errorf("minLatency %q is not a positive duration", params.MinLatency)
+		}
+		terms = append(terms, fmt.Sprintf("latency:%dms", latency.Milliseconds()))
+	}
+	if params.Filter != "" {
+		terms = append(terms, params.Filter)
+	}
+	return strings.Join(terms, " "), nil
+}
+
+// quoteTerm quotes values containing spaces, which would otherwise split the term
+func quoteTerm(value string) string {
+	if strings.ContainsAny(value, " \t\"") {
+		return `"` + strings.ReplaceAll(value, `"`, `\"`) + `"`
+	}
+	return value
+}
@@ -0,0 +1,128 @@
+package trace
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	cloudtrace "google.golang.org/api/cloudtrace/v1"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+// fixtureAPI serves recorded responses and records the queries it receives
+type fixtureAPI struct {
+	traces  []*cloudtrace.Trace
+	queries []ListQuery
+	gets    int
+}
+
+func (a *fixtureAPI) GetTrace(ctx context.Context, projectID, traceID string) (*cloudtrace.Trace, error) {
+	a.gets++
+	for _, trace := range a.traces {
+		if trace.ProjectId == projectID && trace.TraceId == traceID {
+			return trace, nil
+		}
+	}
+	return nil, fmt.Errorf("trace %s not found", traceID)
+}
+
+func (a *fixtureAPI) ListTraces(ctx context.Context, projectID string, query ListQuery) ([]*cloudtrace.Trace, error) {
+	a.queries = append(a.queries, query)
+	return a.traces, nil
+}
+
+func TestListTraces(t *testing.T) {
+	var response cloudtrace.ListTracesResponse
+	loadFixture(t, "list_traces.json", &response)
+	api := &fixtureAPI{traces: response.Traces}
+	tool := NewListTracesTool(api, "example-project", logging.NewLogCache())
+
+	args := map[string]interface{}{
+		"rootSpanName": "/checkout",
+		"minLatency":   "200ms",
+		"startTime":    "2024-05-01T10:00:00Z",
+		"endTime":      "2024-05-01T11:00:00Z",
+	}
+	result, err := tool.Execute(context.Background(), args)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if result.IsError {
+		t.Fatalf("unexpected error result: %s", result.Content[0].Text)
+	}
+
+	wantQuery := ListQuery{
+		Filter:    "root:/checkout latency:200ms",
+		StartTime: "2024-05-01T10:00:00Z",
+		EndTime:   "2024-05-01T11:00:00Z",
+		OrderBy:   defaultTraceOrder,
+		View:      ViewRootSpan,
+		PageSize:  defaultTraceLimit,
+	}
+	if len(api.queries) != 1 || api.queries[0] != wantQuery {
+		t.Fatalf("queries = %+v, want %+v", api.queries, wantQuery)
+	}
+
+	var got listTracesResult
+	if err := json.Unmarshal([]byte(result.Content[0].Text), &got); err != nil {
+		t.Fatal(err)
+	}
+	if got.Count != 2 || len(got.Traces) != 2 {
+		t.Fatalf("got %d traces, want 2", got.Count)
+	}
+	want := []struct {
+		traceID    string
+		durationMs float64
+		status     string
+	}{
+		{"4bf92f3577b34da6a3ce929d0e0e4736", 500, "200"},
+		{"0af7651916cd43dd8448eb211c80319c", 250, "502"},
+	}
+	for i, w := range want {
+		summary := got.Traces[i]
+		if summary.TraceID != w.traceID || summary.RootSpan != "/checkout" || summary.DurationMs != w.durationMs {
+			t.Errorf("trace %d = %s %q %vms, want %s \"/checkout\" %vms", i, summary.TraceID, summary.RootSpan, summary.DurationMs, w.traceID, w.durationMs)
+		}
+		if summary.Labels["/http/status_code"] != w.status {
+			t.Errorf("trace %d status label = %q, want %q", i, summary.Labels["/http/status_code"], w.status)
+		}
+		if _, ok := summary.Labels["g.co/agent"]; ok {
+			t.Errorf("trace %d kept agent label", i)
+		}
+	}
+
+	// The window is closed, so the same call is served from the cache
+	if _, err := tool.Execute(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.queries) != 1 {
+		t.Errorf("repeated call queried the API again")
+	}
+
+	// A different closed window is not
+	args["startTime"] = "2024-05-01T10:00:30Z"
+	if _, err := tool.Execute(context.Background(), args); err != nil {
+		t.Fatal(err)
+	}
+	if len(api.queries) != 2 {
+		t.Errorf("a different window was served from the cache")
+	}
+}
+
+func TestListTracesInvalidLatency(t *testing.T) {
+	api := &fixtureAPI{}
+	tool := NewListTracesTool(api, "example-project", logging.NewLogCache())
+
+	result, err := tool.Execute(context.Background(), map[string]interface{}{"minLatency": "fast"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !result.IsError {
+		t.Error("invalid minLatency was accepted")
+	}
+	if len(api.queries) != 0 {
+		t.Error("invalid arguments reached the API")
+	}
+}
@@ -0,0 +1,247 @@
+package trace
+
+import (
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	cloudtrace "google.golang.org/api/cloudtrace/v1"
+
+	"github.com/takashabe/gco-o11y-mcp/internal/logging"
+)
+
+const maxLogMessageChars = 500
+
+var traceNamePattern = regexp.MustCompile(`^projects/([^/]+)/traces/([0-9a-fA-F]+)$`)
+
+// Span labels worth showing; the rest are mostly agent metadata
+var spanLabelKeys = []string{
+	"/http/method", "/http/status_code", "/http/url", "/http/route",
+	"/component", "/error/message", "/error/name", "g.co/gae/app/module",
+}
+
+// SpanLog is a log entry emitted while a span was active
+type SpanLog struct {
+	Timestamp string `json:"timestamp"`
+	Severity  string `json:"severity"`
+	Message   string `json:"message"`
+}
+
+// Span is a trace span with timings relative to the trace start and the
+// log entries that share its trace and span ids
+type Span struct {
+	SpanID       string            `json:"spanId"`
+	ParentSpanID string            `json:"parentSpanId,omitempty"`
+	Depth        int               `json:"depth"`
+	Name         string            `json:"name"`
+	Kind         string            `json:"kind,omitempty"`
+	Start        time.Time         `json:"start"`
+	OffsetMs     float64           `json:"offsetMs"`
+	DurationMs   float64           `json:"durationMs"`
+	SelfMs       float64           `json:"selfMs"`
+	Labels       map[string]string `json:"labels,omitempty"`
+	Logs         []SpanLog         `json:"logs,omitempty"`
+	LogCount     int               `json:"logCount,omitempty"`
+	end          time.Time
+	children     []*Span
+}
+
+// parseTraceName splits a projects/*/traces/* name, or returns the
+// default project for a bare trace id
+func parseTraceName(defaultProject, trace string) (string, string, error) {
+	trace = strings.TrimSpace(trace)
+	if matches := traceNamePattern.FindStringSubmatch(trace); matches != nil {
+		return matches[1], strings.ToLower(matches[2]), nil
+	}
+	if _, err := logging.TraceName(defaultProject, trace); err != nil {
+		return "", "", err
+	}
+	return defaultProject, strings.ToLower(trace), nil
+}
+
+// buildSpans converts the spans of a trace into a depth-first list ordered
+// by start time, so that the list reads as a call tree
+func buildSpans(trace *cloudtrace.Trace) ([]*Span, time.Time, time.Time) {
+	var start, end time.Time
+	byID := make(map[uint64]*Span, len(trace.Spans))
+	for _, ts := range trace.Spans {
+		span := &Span{
+			SpanID: formatSpanID(ts.SpanId),
+			Name:   ts.Name,
+			Kind:   ts.Kind,
+			Labels: selectLabels(ts.Labels),
+		}
+		if ts.ParentSpanId != 0 {
+			span.ParentSpanID = formatSpanID(ts.ParentSpanId)
+		}
+		span.Start, _ = time.Parse(time.RFC3339Nano, ts.StartTime)
+		span.end, _ = time.Parse(time.RFC3339Nano, ts.EndTime)
+		span.DurationMs = milliseconds(span.end.Sub(span.Start))
+		byID[ts.SpanId] = span
+
+		if start.IsZero() || span.Start.Before(start) {
+			start = span.Start
+		}
+		if span.end.After(end) {
+			end = span.end
+		}
+	}
+
+	parents := make(map[uint64]uint64, len(trace.Spans))
+	for _, ts := range trace.Spans {
+		if _, ok := byID[ts.ParentSpanId]; ok && ts.ParentSpanId != 0 && ts.ParentSpanId != ts.SpanId {
+			parents[ts.SpanId] = ts.ParentSpanId
+		}
+	}
+
+	var roots []*Span
+	linked := make(map[uint64]bool, len(trace.Spans))
+	for _, ts := range trace.Spans {
+		// Spans repeated in a trace are listed once
+		if linked[ts.SpanId] {
+			continue
+		}
+		linked[ts.SpanId] = true
+
+		span := byID[ts.SpanId]
+		span.OffsetMs = milliseconds(span.Start.Sub(start))
+		if parentID, ok := parents[ts.SpanId]; ok && !inParentCycle(parents, ts.SpanId) {
+			byID[parentID].children = append(byID[parentID].children, span)
+		} else {
+			roots = append(roots, span)
+		}
+	}
+
+	var spans []*Span
+	var walk func(list []*Span, depth int)
+	walk = func(list []*Span, depth int) {
+		sort.SliceStable(list, func(i, j int) bool {
+			return list[i].Start.Before(list[j].Start)
+		})
+		for _, span := range list {
+			span.Depth = depth
+			span.SelfMs = selfTime(span)
+			spans = append(spans, span)
+			walk(span.children, depth+1)
+		}
+	}
+	walk(roots, 0)
+
+	return spans, start, end
+}
+
+// inParentCycle reports whether following parents from a span leads back
+// to it. Such spans are treated as roots, which breaks the cycle.
+func inParentCycle(parents map[uint64]uint64, id uint64) bool {
+	seen := map[uint64]bool{id: true}
+	for current := id; ; {
+		parent, ok := parents[current]
+		if !ok {
+			return false
+		}
+		if parent == id {
+			return true
+		}
+		// A cycle further up, which is broken where it is
+		if seen[parent] {
+			return false
+		}
+		seen[parent] = true
+		current = parent
+	}
+}
+
+// selfTime is the part of a span not covered by any of its children
+func selfTime(span *Span) float64 {
+	if len(span.children) == 0 {
+		return span.DurationMs
+	}
+	children := append([]*Span(nil), span.children...)
+	sort.Slice(children, func(i, j int) bool {
+		return children[i].Start.Before(children[j].Start)
+	})
+
+	var covered time.Duration
+	var cursor time.Time
+	for _, child := range children {
+		from, to := child.Start, child.end
+		if from.Before(span.Start) {
+			from = span.Start
+		}
+		if to.After(span.end) {
+			to = span.end
+		}
+		if from.Before(cursor) {
+			from = cursor
+		}
+		if to.After(from) {
+			covered += to.Sub(from)
+			cursor = to
+		}
+	}
+	return milliseconds(span.end.Sub(span.Start) - covered)
+}
+
+// attachLogs annotates spans with their log entries and returns how many
+// entries did not belong to any span of the trace
+func attachLogs(spans []*Span, entries []logging.LogEntry, maxPerSpan int) int {
+	byID := make(map[string]*Span, len(spans)*2)
+	for _, span := range spans {
+		byID[span.SpanID] = span
+		// Some agents log the span id in decimal, as in X-Cloud-Trace-Context
+		if id, err := strconv.ParseUint(span.SpanID, 16, 64); err == nil {
+			byID[strconv.FormatUint(id, 10)] = span
+		}
+	}
+
+	unmatched := 0
+	for _, entry := range entries {
+		span, ok := byID[strings.ToLower(entry.SpanID)]
+		if !ok {
+			unmatched++
+			continue
+		}
+		span.LogCount++
+		if len(span.Logs) >= maxPerSpan {
+			continue
+		}
+
+		info := logging.ExtractServiceInfoFromLogEntry(entry)
+		message := info.Message
+		if len(message) > maxLogMessageChars {
+			message = message[:maxLogMessageChars] + "..."
+		}
+		span.Logs = append(span.Logs, SpanLog{
+			Timestamp: entry.Timestamp,
+			Severity:  strings.ToUpper(entry.Severity),
+			Message:   message,
+		})
+	}
+	return unmatched
+}
+
+func selectLabels(labels map[string]string) map[string]string {
+	selected := make(map[string]string)
+	for _, key := range spanLabelKeys {
+		if value, ok := labels[key]; ok {
+			selected[key] = value
+		}
+	}
+	if len(selected) == 0 {
+		return nil
+	}
+	return selected
+}
+
+// formatSpanID renders a span id the way Cloud Logging stores it
+func formatSpanID(id uint64) string {
+	return fmt.Sprintf("%016x", id)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return math.Round(float64(d)/float64(time.Millisecond)*100) / 100
+}
@@ -0,0 +1,137 @@
+package trace
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	cloudtrace "google.golang.org/api/cloudtrace/v1"
+)
+
+// loadFixture decodes a recorded Cloud Trace API response from testdata
+func loadFixture(t *testing.T, name string, v interface{}) {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join("testdata", name))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := json.Unmarshal(data, v); err != nil {
+		t.Fatalf("%s: %v", name, err)
+	}
+}
+
+func TestBuildSpans(t *testing.T) {
+	var trace cloudtrace.Trace
+	loadFixture(t, "trace.json", &trace)
+
+	spans, start, end := buildSpans(&trace)
+
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC); !start.Equal(want) {
+		t.Errorf("start = %s, want %s", start, want)
+	}
+	if want := time.Date(2024, 5, 1, 10, 0, 0, 500*int(time.Millisecond), time.UTC); !end.Equal(want) {
+		t.Errorf("end = %s, want %s", end, want)
+	}
+
+	want := []struct {
+		spanID   string
+		parentID string
+		name     string
+		depth    int
+		offsetMs float64
+		duration float64
+		selfMs   float64
+	}{
+		{"0000000000000001", "", "/checkout", 0, 0, 500, 120},
+		{"0000000000000002", "0000000000000001", "cloudsql.Query", 1, 50, 100, 100},
+		{"0000000000000005", "0000000000000001", "cloudsql.Exec", 1, 120, 60, 60},
+		{"0000000000000003", "0000000000000001", "payments.Charge", 1, 200, 250, 20},
+		{"00000000000000ff", "0000000000000003", "/v1/charges", 2, 210, 230, 230},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("got %d spans, want %d", len(spans), len(want))
+	}
+	for i, w := range want {
+		got := spans[i]
+		if got.SpanID != w.spanID || got.ParentSpanID != w.parentID || got.Name != w.name || got.Depth != w.depth {
+			t.Errorf("span %d = %s %q (parent %q, depth %d), want %s %q (parent %q, depth %d)",
+				i, got.SpanID, got.Name, got.ParentSpanID, got.Depth, w.spanID, w.name, w.parentID, w.depth)
+		}
+		if got.OffsetMs != w.offsetMs || got.DurationMs != w.duration || got.SelfMs != w.selfMs {
+			t.Errorf("%s: offset %v, duration %v, self %v; want %v, %v, %v",
+				w.name, got.OffsetMs, got.DurationMs, got.SelfMs, w.offsetMs, w.duration, w.selfMs)
+		}
+	}
+
+	wantLabels := map[string]string{
+		"/http/method":      "POST",
+		"/http/status_code": "200",
+		"/http/url":         "https://shop.example.com/checkout",
+	}
+	if !reflect.DeepEqual(spans[0].Labels, wantLabels) {
+		t.Errorf("root labels = %v, want %v", spans[0].Labels, wantLabels)
+	}
+}
+
+func TestBuildSpansParentCycles(t *testing.T) {
+	var trace cloudtrace.Trace
+	loadFixture(t, "trace_cycle.json", &trace)
+
+	done := make(chan []*Span)
+	go func() {
+		spans, _, _ := buildSpans(&trace)
+		done <- spans
+	}()
+
+	var spans []*Span
+	select {
+	case spans = <-done:
+	case <-time.After(5 * time.Second):
+		t.Fatal("buildSpans did not return for a trace with parent cycles")
+	}
+
+	want := []struct {
+		name  string
+		depth int
+	}{
+		{"self-parent", 0},
+		{"cycle-a", 0},
+		{"cycle-b", 0},
+		{"below-cycle", 1},
+	}
+	if len(spans) != len(want) {
+		t.Fatalf("got %d spans, want %d", len(spans), len(want))
+	}
+	for i, w := range want {
+		if spans[i].Name != w.name || spans[i].Depth != w.depth {
+			t.Errorf("span %d = %q at depth %d, want %q at depth %d", i, spans[i].Name, spans[i].Depth, w.name, w.depth)
+		}
+	}
+}
+
+func TestParseTraceName(t *testing.T) {
+	tests := []struct {
+		input     string
+		wantProj  string
+		wantTrace string
+		wantErr   bool
+	}{
+		{"4BF92F3577B34DA6A3CE929D0E0E4736", "default-project", "4bf92f3577b34da6a3ce929d0e0e4736", false},
+		{"projects/other/traces/4bf92f3577b34da6a3ce929d0e0e4736", "other", "4bf92f3577b34da6a3ce929d0e0e4736", false},
+		{"not-a-trace", "", "", true},
+	}
+
+	for _, tt := range tests {
+		project, trace, err := parseTraceName("default-project", tt.input)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseTraceName(%q) error = %v, want error %v", tt.input, err, tt.wantErr)
+			continue
+		}
+		if project != tt.wantProj || trace != tt.wantTrace {
+			t.Errorf("parseTraceName(%q) = %q, %q, want %q, %q", tt.input, project, trace, tt.wantProj, tt.wantTrace)
+		}
+	}
+}
@@ -28,10 +28,15 @@ type Tool struct {
 
 type Schema struct {
 	Type                 string            `json:"type"`
+	Description          string            `json:"description,omitempty"`
 	Properties           map[string]Schema `json:"properties,omitempty"`
 	Required             []string          `json:"required,omitempty"`
 	Items                *Schema           `json:"items,omitempty"`
 	AdditionalProperties interface{}       `json:"additionalProperties,omitempty"`
+	Default              interface{}       `json:"default,omitempty"`
+	Enum                 []string          `json:"enum,omitempty"`
+	Pattern              string            `json:"pattern,omitempty"`
+	Format               string            `json:"format,omitempty"`
 }
 
 type CallToolParams struct {